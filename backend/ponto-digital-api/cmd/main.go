@@ -4,24 +4,58 @@ import (
 	"log"
 	"ponto-digital-api/config"
 	"github.com/gin-gonic/gin"
+	"ponto-digital-api/internal/afd"
 	"ponto-digital-api/internal/handlers"
-	"go.mongodb.org/mongo-driver/mongo"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/signature"
+	"ponto-digital-api/internal/webauthn"
 	
 )
 
-var db *mongo.Database
-
 func main() {
-    // Conectar ao MongoDB
-    db, err := config.ConnectDB(config.DefaultConfig)
+    // Inicializar armazenamento
+    store, err := newStore(config.DefaultConfig)
     if err != nil {
         log.Fatal("Não foi possível conectar ao banco de dados:", err)
     }
 
+    // Certificado que assina os arquivos fiscais e os comprovantes de ponto
+    signer, err := newSigner(config.DefaultConfig)
+    if err != nil {
+        log.Fatal("Não foi possível carregar o certificado de assinatura:", err)
+    }
+
+    // Aplicação perante os autenticadores WebAuthn da marcação biométrica
+    rp := &webauthn.RelyingParty{
+        ID:      config.DefaultConfig.WebAuthnRPID,
+        Name:    config.DefaultConfig.WebAuthnRPName,
+        Origins: config.DefaultConfig.WebAuthnOrigins,
+    }
+
     // Inicializar handlers
-    authHandler := handlers.NewAuthHandler(db)
-    pointHandler := handlers.NewPointHandler(db)
-    userHandler := handlers.NewUserHandler(db)
+    authHandler := handlers.NewAuthHandler(store)
+    pointHandler := handlers.NewPointHandler(store, signer, config.DefaultConfig.RepINPI, rp)
+    webAuthnHandler := handlers.NewWebAuthnHandler(store, rp)
+    userHandler := handlers.NewUserHandler(store)
+    companyHandler := handlers.NewCompanyHandler(store)
+    scheduleHandler := handlers.NewScheduleHandler(store)
+    hourBankHandler := handlers.NewHourBankHandler(store)
+    holidayHandler := handlers.NewHolidayHandler(store)
+    correctionHandler := handlers.NewCorrectionHandler(store)
+    attachmentHandler := handlers.NewAttachmentHandler(store)
+    absenceHandler := handlers.NewAbsenceHandler(store)
+    closingHandler := handlers.NewClosingHandler(store)
+    acknowledgementHandler := handlers.NewAcknowledgementHandler(store)
+    complianceHandler := handlers.NewComplianceHandler(store)
+    fiscalHandler := handlers.NewFiscalHandler(store, signer, afd.Program{
+        Name:           afd.ProgramName,
+        Version:        afd.ProgramVersion,
+        INPI:           config.DefaultConfig.RepINPI,
+        DeveloperCNPJ:  config.DefaultConfig.DeveloperCNPJ,
+        DeveloperName:  config.DefaultConfig.DeveloperName,
+        DeveloperEmail: config.DefaultConfig.DeveloperEmail,
+    })
 
     r := gin.Default()
 
@@ -46,23 +80,139 @@ func main() {
         // Rotas públicas
         api.POST("/register", authHandler.Register)
         api.POST("/login", authHandler.Login)
+        api.POST("/refresh", authHandler.Refresh)
+        api.POST("/receipts/verify", pointHandler.VerifyReceipt)
 
         // Rotas protegidas
         protected := api.Group("/")
         protected.Use(authHandler.AuthMiddleware())
         {
+            // Sessões de login
+            protected.POST("/logout", authHandler.Logout)
+            protected.POST("/logout-all", authHandler.LogoutAll)
+
             // Rotas de ponto
             protected.POST("/register-point", pointHandler.RegisterPoint)
             protected.GET("/points/today", pointHandler.GetUserPoints)
             protected.GET("/points/monthly", pointHandler.GetMonthlyPoints)
+            protected.GET("/points/monthly/pdf", pointHandler.GetMonthlyReport)
+            protected.GET("/points/monthly/dsr", pointHandler.GetMonthlyWeeks)
+            protected.GET("/points/violations", complianceHandler.GetMyViolations)
+            protected.GET("/points/:id/receipt", pointHandler.GetReceipt)
             protected.POST("/setup-pin", userHandler.SetupPin)
 
+            // Credenciais biométricas (WebAuthn)
+            protected.POST("/webauthn/register/options", webAuthnHandler.RegistrationOptions)
+            protected.POST("/webauthn/register", webAuthnHandler.RegisterCredential)
+            protected.GET("/webauthn/credentials", webAuthnHandler.ListCredentials)
+            protected.DELETE("/webauthn/credentials/:id", webAuthnHandler.DeleteCredential)
+            protected.POST("/webauthn/authenticate/options", webAuthnHandler.AuthenticationOptions)
+
             // Rotas de usuário
             protected.GET("/profile", userHandler.GetProfile)
             protected.PUT("/profile", userHandler.UpdateProfile)
 
             // Rota de estatísticas (opcional)
             protected.GET("/statistics", pointHandler.GetStatistics)
+
+            // Jornada de trabalho do usuário
+            protected.GET("/schedule", scheduleHandler.GetMySchedule)
+            protected.GET("/schedule/expected", scheduleHandler.GetMyExpectedDays)
+
+            // Banco de horas do usuário
+            protected.GET("/hour-bank", hourBankHandler.GetMyHourBank)
+
+            // Feriados do usuário
+            protected.GET("/holidays", holidayHandler.GetMyHolidays)
+
+            // Ajustes de ponto e anexos
+            protected.GET("/corrections", correctionHandler.ListMyCorrections)
+            protected.POST("/corrections", correctionHandler.CreateCorrection)
+            protected.POST("/attachments", attachmentHandler.UploadAttachment)
+            protected.GET("/attachments/:id", attachmentHandler.GetAttachment)
+
+            // Ausências justificadas e abonos
+            protected.GET("/absences", absenceHandler.ListMyAbsences)
+            protected.POST("/absences", absenceHandler.CreateAbsence)
+
+            // Competências fechadas e assinatura do espelho de ponto
+            protected.GET("/closings", acknowledgementHandler.ListMyClosings)
+            protected.GET("/closings/:competence", acknowledgementHandler.GetMyClosing)
+            protected.GET("/closings/:competence/pdf", acknowledgementHandler.GetMyClosingDocument)
+            protected.POST("/closings/:competence/acknowledge", acknowledgementHandler.AcknowledgeClosing)
+
+            // Rotas de gestores: consulta dos pontos da equipe
+            team := protected.Group("/team")
+            team.Use(authHandler.RequireRole(models.RoleManager, models.RoleAdmin))
+            {
+                team.GET("/members", userHandler.ListTeam)
+                team.GET("/members/:id/points/monthly", pointHandler.GetTeamMemberMonthlyPoints)
+                team.GET("/members/:id/points/monthly/pdf", pointHandler.GetTeamMemberMonthlyReport)
+                team.GET("/members/:id/points/monthly/dsr", pointHandler.GetTeamMemberMonthlyWeeks)
+                team.GET("/members/:id/hour-bank", hourBankHandler.GetMemberHourBank)
+                team.POST("/members/:id/hour-bank/adjustments", hourBankHandler.CreateAdjustment)
+                team.GET("/corrections", correctionHandler.ListTeamCorrections)
+                team.PUT("/corrections/:id/approve", correctionHandler.ApproveCorrection)
+                team.PUT("/corrections/:id/reject", correctionHandler.RejectCorrection)
+                team.GET("/absences", absenceHandler.ListTeamAbsences)
+                team.POST("/members/:id/absences", absenceHandler.CreateMemberAbsence)
+                team.PUT("/absences/:id/approve", absenceHandler.ApproveAbsence)
+                team.PUT("/absences/:id/reject", absenceHandler.RejectAbsence)
+                team.GET("/acknowledgements", acknowledgementHandler.ListTeamAcknowledgements)
+                team.GET("/violations", complianceHandler.ListTeamViolations)
+            }
+
+            // Rotas de administração de usuários
+            admin := protected.Group("/admin")
+            admin.Use(authHandler.RequireRole(models.RoleAdmin))
+            {
+                admin.GET("/users", userHandler.ListUsers)
+                admin.POST("/users", userHandler.CreateUser)
+                admin.PUT("/users/:id/role", userHandler.UpdateUserRole)
+                admin.PUT("/users/:id/branch", userHandler.UpdateUserBranch)
+                admin.PUT("/users/:id/cpf", userHandler.UpdateUserCPF)
+
+                // Empresa e estabelecimentos
+                admin.GET("/company", companyHandler.GetCompany)
+                admin.PUT("/company", companyHandler.UpdateCompany)
+                admin.GET("/company/policy", companyHandler.GetPolicy)
+                admin.PUT("/company/policy", companyHandler.UpdatePolicy)
+                admin.GET("/agreements", companyHandler.ListAgreements)
+                admin.POST("/agreements", companyHandler.CreateAgreement)
+                admin.PUT("/agreements/:id", companyHandler.UpdateAgreement)
+                admin.GET("/branches", companyHandler.ListBranches)
+                admin.POST("/branches", companyHandler.CreateBranch)
+                admin.PUT("/branches/:id", companyHandler.UpdateBranch)
+                admin.POST("/branches/:id/holidays/import", holidayHandler.ImportBranchHolidays)
+
+                // Feriados e folgas da empresa
+                admin.GET("/holidays", holidayHandler.ListHolidays)
+                admin.POST("/holidays", holidayHandler.CreateHoliday)
+                admin.DELETE("/holidays/:id", holidayHandler.DeleteHoliday)
+
+                // Arquivos fiscais da Portaria 671
+                admin.GET("/fiscal/afd", fiscalHandler.ExportAFD)
+                admin.GET("/fiscal/aej", fiscalHandler.ExportAEJ)
+                admin.GET("/fiscal/verify", fiscalHandler.VerifyRecords)
+
+                // Fechamento de competência
+                admin.GET("/closings", closingHandler.ListClosings)
+                admin.POST("/closings", closingHandler.CloseCompetence)
+                admin.GET("/closings/:competence", closingHandler.GetClosing)
+                admin.POST("/closings/:competence/reopen", closingHandler.ReopenCompetence)
+
+                // Jornadas de trabalho e grupos
+                admin.GET("/schedules", scheduleHandler.ListSchedules)
+                admin.POST("/schedules", scheduleHandler.CreateSchedule)
+                admin.PUT("/schedules/:id", scheduleHandler.UpdateSchedule)
+                admin.GET("/groups", scheduleHandler.ListGroups)
+                admin.POST("/groups", scheduleHandler.CreateGroup)
+                admin.PUT("/groups/:id", scheduleHandler.UpdateGroup)
+                admin.GET("/shifts", scheduleHandler.ListShifts)
+                admin.POST("/shifts", scheduleHandler.CreateShift)
+                admin.PUT("/shifts/:id", scheduleHandler.UpdateShift)
+                admin.PUT("/users/:id/schedule", scheduleHandler.AssignSchedule)
+            }
         }
     }
 
@@ -72,6 +222,37 @@ func main() {
     }
 }
 
+// newStore cria os repositórios de acordo com o armazenamento configurado
+func newStore(cfg config.Config) (*repository.Store, error) {
+    if cfg.Storage == "memory" {
+        log.Println("Usando armazenamento em memória")
+        return repository.NewMemoryStore(), nil
+    }
+
+    // Conectar ao MongoDB
+    db, err := config.ConnectDB(cfg)
+    if err != nil {
+        return nil, err
+    }
+    store := repository.NewMongoStore(db)
+    store.Files = repository.NewDiskFileStore(cfg.AttachmentsDir)
+    return store, nil
+}
+
+// newSigner carrega o certificado configurado ou, na falta dele, o
+// certificado autoassinado, aceito apenas em desenvolvimento. Com o
+// armazenamento em memória o certificado autoassinado não é guardado
+func newSigner(cfg config.Config) (*signature.Signer, error) {
+    dir := ""
+    if cfg.SigningCertFile == "" && cfg.SigningKeyFile == "" {
+        log.Println("Certificado de assinatura não configurado, usando certificado autoassinado")
+        if cfg.Storage != "memory" {
+            dir = cfg.SelfSignedDir()
+        }
+    }
+    return signature.Open(cfg.SigningCertFile, cfg.SigningKeyFile, dir, "Ponto Digital")
+}
+
 func handleLogin(c *gin.Context) {
 	// Implementaremos depois
 	c.JSON(200, gin.H{
@@ -0,0 +1,204 @@
+// Comando ponto-cli reúne as tarefas administrativas executadas fora da API,
+// como a geração dos arquivos fiscais para a fiscalização do trabalho.
+//
+// Uso:
+//
+//	ponto-cli afd -cnpj 11222333000181 -from 2024-01-01 -to 2024-01-31 [-out dir]
+//	ponto-cli aej -cnpj 11222333000181 -from 2024-01-01 -to 2024-01-31 [-out dir]
+//	ponto-cli verify -cnpj 11222333000181
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/config"
+	"ponto-digital-api/internal/aej"
+	"ponto-digital-api/internal/afd"
+	"ponto-digital-api/internal/punch"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/signature"
+	"ponto-digital-api/internal/timesheet"
+	"ponto-digital-api/internal/utils"
+)
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "afd", "aej":
+		err = runExport(os.Args[1], os.Args[2:])
+	case "verify":
+		err = runVerify(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "uso: ponto-cli afd|aej -cnpj CNPJ -from AAAA-MM-DD -to AAAA-MM-DD [-out DIR]")
+	fmt.Fprintln(os.Stderr, "     ponto-cli verify -cnpj CNPJ")
+	os.Exit(2)
+}
+
+// fiscalFile é um arquivo fiscal gerado, AFD ou AEJ.
+type fiscalFile interface {
+	FileName() string
+	Bytes() []byte
+}
+
+// runExport gera o arquivo fiscal kind do estabelecimento ou da empresa com o
+// CNPJ informado e grava o arquivo e sua assinatura .p7s no diretório de
+// saída.
+func runExport(kind string, args []string) error {
+	flags := flag.NewFlagSet(kind, flag.ExitOnError)
+	cnpj := flags.String("cnpj", "", "CNPJ do estabelecimento ou da empresa")
+	fromValue := flags.String("from", "", "data inicial (AAAA-MM-DD)")
+	toValue := flags.String("to", "", "data final (AAAA-MM-DD)")
+	out := flags.String("out", ".", "diretório de saída")
+	flags.Parse(args)
+
+	store, err := openStore(config.DefaultConfig)
+	if err != nil {
+		return err
+	}
+	sheets := timesheet.NewService(store)
+
+	from, err := time.ParseInLocation(timesheet.DateLayout, *fromValue, sheets.Location)
+	if err != nil {
+		return errors.New("data inicial inválida")
+	}
+	to, err := time.ParseInLocation(timesheet.DateLayout, *toValue, sheets.Location)
+	if err != nil || to.Before(from) {
+		return errors.New("data final inválida")
+	}
+
+	ctx := context.Background()
+	companyID, branchID, err := findEmployer(ctx, store, utils.NormalizeCNPJ(*cnpj))
+	if err != nil {
+		return err
+	}
+
+	cfg := config.DefaultConfig
+	signer, err := signature.Open(cfg.SigningCertFile, cfg.SigningKeyFile, cfg.SelfSignedDir(), "Ponto Digital")
+	if err != nil {
+		return err
+	}
+	program := afd.Program{
+		Name:           afd.ProgramName,
+		Version:        afd.ProgramVersion,
+		INPI:           cfg.RepINPI,
+		DeveloperCNPJ:  cfg.DeveloperCNPJ,
+		DeveloperName:  cfg.DeveloperName,
+		DeveloperEmail: cfg.DeveloperEmail,
+	}
+
+	now := time.Now()
+	var file fiscalFile
+	if kind == "aej" {
+		file, err = aej.NewService(store, sheets, program).Generate(ctx, companyID, branchID, from, to, now)
+	} else {
+		file, err = afd.NewService(store, program, sheets.Location).Generate(ctx, companyID, branchID, from, to, now)
+	}
+	if err != nil {
+		return err
+	}
+
+	content := file.Bytes()
+	signed, err := signer.SignDetached(content, now)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(*out, file.FileName())
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+".p7s", signed, 0o644); err != nil {
+		return err
+	}
+	log.Println(strings.ToUpper(kind), "gravado em", path)
+	return nil
+}
+
+// runVerify confere a numeração sequencial e o encadeamento dos registros de
+// ponto do estabelecimento ou da empresa com o CNPJ informado. Termina com
+// erro quando alguma inconsistência é encontrada.
+func runVerify(args []string) error {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	cnpj := flags.String("cnpj", "", "CNPJ do estabelecimento ou da empresa")
+	flags.Parse(args)
+
+	store, err := openStore(config.DefaultConfig)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	companyID, branchID, err := findEmployer(ctx, store, utils.NormalizeCNPJ(*cnpj))
+	if err != nil {
+		return err
+	}
+
+	records, events, head, err := store.TimeRecords.FindChain(ctx, companyID, branchID)
+	if err != nil {
+		return err
+	}
+	report := punch.VerifyChain(records, events, head.NSR, head.Hash)
+
+	log.Printf("%d registros verificados, último NSR %d", report.Records, report.LastNSR)
+	for _, issue := range report.Issues {
+		log.Printf("NSR %d: %s", issue.NSR, issue.Problem)
+	}
+	if !report.Valid {
+		return fmt.Errorf("%d inconsistências encontradas", len(report.Issues))
+	}
+	log.Println("Sequência íntegra")
+	return nil
+}
+
+// findEmployer localiza o estabelecimento com o CNPJ informado ou, na falta
+// dele, a empresa, cujo AFD é o da sede.
+func findEmployer(ctx context.Context, store *repository.Store, cnpj string) (companyID, branchID primitive.ObjectID, err error) {
+	branch, err := store.Branches.FindByCNPJ(ctx, cnpj)
+	if err == nil {
+		return branch.CompanyID, branch.ID, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return companyID, branchID, err
+	}
+
+	company, err := store.Companies.FindByCNPJ(ctx, cnpj)
+	if errors.Is(err, repository.ErrNotFound) {
+		return companyID, branchID, fmt.Errorf("nenhuma empresa ou estabelecimento com o CNPJ %s", cnpj)
+	}
+	if err != nil {
+		return companyID, branchID, err
+	}
+	return company.ID, primitive.NilObjectID, nil
+}
+
+// openStore conecta ao MongoDB configurado; o armazenamento em memória não
+// faz sentido fora da API.
+func openStore(cfg config.Config) (*repository.Store, error) {
+	db, err := config.ConnectDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return repository.NewMongoStore(db), nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -15,6 +17,29 @@ type Config struct {
 	MongoURI      string
 	DatabaseName  string
 	ClientOptions *options.ClientOptions
+	// Storage define onde os dados são persistidos: "mongo" (padrão) ou
+	// "memory", que dispensa o MongoDB e perde os dados ao encerrar.
+	Storage string
+	// AttachmentsDir é o diretório onde os anexos enviados são gravados
+	// quando o armazenamento é o MongoDB.
+	AttachmentsDir string
+	// Identificação do programa de tratamento de ponto (REP-P) nos arquivos
+	// fiscais: número de registro no INPI e CNPJ do desenvolvedor.
+	RepINPI        string
+	DeveloperCNPJ  string
+	DeveloperName  string
+	DeveloperEmail string
+	// Certificado e chave privada, em PEM, que assinam os arquivos fiscais.
+	// Sem eles é usado um certificado autoassinado, gerado no primeiro uso e
+	// guardado em SelfSignedDir.
+	SigningCertFile string
+	SigningKeyFile  string
+	// Relying party do WebAuthn usado na marcação biométrica: o domínio ao
+	// qual as credenciais ficam vinculadas, o nome exibido pelo navegador e
+	// as origens do frontend autorizadas.
+	WebAuthnRPID    string
+	WebAuthnRPName  string
+	WebAuthnOrigins []string
 }
 
 var DefaultConfig Config
@@ -22,15 +47,32 @@ var DefaultConfig Config
 func init() {
 	err := godotenv.Load()
 	if err != nil {
-		log.Fatal("Erro ao carregar arquivo .env")
+		log.Println("Arquivo .env não encontrado, usando variáveis de ambiente")
 	}
 
 	DefaultConfig = Config{
 		MongoURI:     os.Getenv("MONGO_URI"),          
 		DatabaseName: os.Getenv("DATABASE_NAME"),      
+		Storage:      getEnv("STORAGE", "mongo"),
+		AttachmentsDir: getEnv("ATTACHMENTS_DIR", "uploads"),
+		RepINPI:        os.Getenv("REP_INPI"),
+		DeveloperCNPJ:  os.Getenv("REP_DEVELOPER_CNPJ"),
+		DeveloperName:  os.Getenv("REP_DEVELOPER_NAME"),
+		DeveloperEmail: os.Getenv("REP_DEVELOPER_EMAIL"),
+		SigningCertFile: os.Getenv("SIGNING_CERT_FILE"),
+		SigningKeyFile:  os.Getenv("SIGNING_KEY_FILE"),
+		WebAuthnRPID:    getEnv("WEBAUTHN_RP_ID", "localhost"),
+		WebAuthnRPName:  getEnv("WEBAUTHN_RP_NAME", "Ponto Digital"),
+		WebAuthnOrigins: getList("WEBAUTHN_ORIGINS", "http://localhost:5173"),
 	}
 }
 
+// SelfSignedDir é o diretório do certificado autoassinado, compartilhado
+// pela API e pela linha de comando.
+func (c Config) SelfSignedDir() string {
+	return filepath.Join(c.AttachmentsDir, "certificado")
+}
+
 // ConnectDB é responsável por conectar ao banco de dados MongoDB
 func ConnectDB(cfg Config) (*mongo.Database, error) {
 	// Contexto com timeout de 10 segundos
@@ -54,3 +96,23 @@ func ConnectDB(cfg Config) (*mongo.Database, error) {
 	log.Println("Conectado ao MongoDB Atlas com sucesso!")
 	return client.Database(cfg.DatabaseName), nil
 }
+
+// getEnv retorna o valor da variável de ambiente ou o valor padrão informado
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getList retorna a lista separada por vírgulas da variável de ambiente ou
+// do valor padrão informado
+func getList(key, fallback string) []string {
+	values := []string{}
+	for _, value := range strings.Split(getEnv(key, fallback), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,177 @@
+// Package aej gera o Arquivo Eletrônico de Jornada (AEJ) do programa de
+// tratamento de ponto, no leiaute do Anexo VI da Portaria MTP 671/2021.
+package aej
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ponto-digital-api/internal/afd"
+)
+
+// LayoutVersion é a versão do leiaute do AEJ informada no cabeçalho.
+const LayoutVersion = "001"
+
+// RepID identifica, nos registros do arquivo, o único REP declarado: o
+// próprio REP-P.
+const RepID = 1
+
+// Tipos de marcação (campo tpMarc do registro 05).
+const (
+	MarkEntry       = "E"
+	MarkExit        = "S"
+	MarkDisregarded = "D" // marcação original desconsiderada
+)
+
+// Fontes da marcação (campo fonteMarc do registro 05).
+const (
+	SourceOriginal = "O" // marcação original do REP
+	SourceIncluded = "I" // incluída por ajuste
+)
+
+// Tipos de registro de ausência ou banco de horas (registro 07).
+const (
+	AbsenceUnjustified = 2 // falta não justificada
+	AbsenceHourBank    = 3 // movimento no banco de horas
+)
+
+// Tipos de movimento no banco de horas.
+const (
+	HourBankCredit = 1
+	HourBankDebit  = 2
+)
+
+// Schedule é um horário contratual declarado no registro 04. Os horários
+// estão no formato "HHMM" e Entry2 e Exit2 ficam vazios quando não há
+// intervalo.
+type Schedule struct {
+	Code    string
+	Minutes int // duração da jornada, descontado o intervalo
+	Entry1  string
+	Exit1   string
+	Entry2  string
+	Exit2   string
+}
+
+// Punch é uma marcação tratada do empregado.
+type Punch struct {
+	Timestamp time.Time
+	Kind      string // MarkEntry, MarkExit ou MarkDisregarded
+	Sequence  int    // par de entrada e saída do dia; zero nas desconsideradas
+	Source    string // SourceOriginal ou SourceIncluded
+	Schedule  string // código do horário contratual do dia
+	Reason    string // motivo do ajuste, nas marcações incluídas ou desconsideradas
+}
+
+// Absence é uma ausência ou um movimento no banco de horas do empregado.
+type Absence struct {
+	Kind     int
+	Date     time.Time
+	Minutes  int
+	Movement int // HourBankCredit ou HourBankDebit, nos movimentos do banco
+}
+
+// Employee é um vínculo do empregado com o empregador.
+type Employee struct {
+	CPF      string
+	Name     string
+	Punches  []Punch
+	Absences []Absence
+}
+
+// File reúne os dados de um AEJ entre as datas From e To, inclusive.
+type File struct {
+	Employer    afd.Employer
+	Program     afd.Program
+	Schedules   []Schedule
+	Employees   []Employee
+	From        time.Time
+	To          time.Time
+	GeneratedAt time.Time
+	Location    *time.Location
+}
+
+// Bytes retorna o AEJ codificado em ISO 8859-1, com campos separados por "|"
+// e linhas terminadas em CRLF.
+func (f *File) Bytes() []byte {
+	counts := make([]int, 9)
+	var lines []string
+	add := func(kind int, fields ...string) {
+		counts[kind]++
+		lines = append(lines, fmt.Sprintf("%02d|", kind)+strings.Join(fields, "|"))
+	}
+
+	add(1, "1", f.Employer.CNPJ, "", "", clean(f.Employer.Name),
+		f.date(f.From), f.date(f.To), f.dateTime(f.GeneratedAt), LayoutVersion)
+	add(2, strconv.Itoa(RepID), "3", f.Program.INPI)
+	for i, employee := range f.Employees {
+		add(3, strconv.Itoa(i+1), employee.CPF, clean(employee.Name))
+	}
+	for _, schedule := range f.Schedules {
+		fields := []string{schedule.Code, strconv.Itoa(schedule.Minutes), schedule.Entry1, schedule.Exit1}
+		if schedule.Entry2 != "" {
+			fields = append(fields, schedule.Entry2, schedule.Exit2)
+		}
+		add(4, fields...)
+	}
+	for i, employee := range f.Employees {
+		for _, punch := range employee.Punches {
+			sequence := ""
+			if punch.Sequence != 0 {
+				sequence = strconv.Itoa(punch.Sequence)
+			}
+			add(5, strconv.Itoa(i+1), f.dateTime(punch.Timestamp), strconv.Itoa(RepID), punch.Kind,
+				sequence, punch.Source, punch.Schedule, clean(punch.Reason))
+		}
+	}
+	for i, employee := range f.Employees {
+		for _, absence := range employee.Absences {
+			movement := ""
+			if absence.Movement != 0 {
+				movement = strconv.Itoa(absence.Movement)
+			}
+			add(7, strconv.Itoa(i+1), strconv.Itoa(absence.Kind), f.date(absence.Date),
+				strconv.Itoa(absence.Minutes), movement)
+		}
+	}
+	add(8, f.Program.Name, f.Program.Version, "1", f.Program.DeveloperCNPJ,
+		clean(f.Program.DeveloperName), f.Program.DeveloperEmail)
+
+	trailer := []string{}
+	for kind := 1; kind <= 8; kind++ {
+		trailer = append(trailer, strconv.Itoa(counts[kind]))
+	}
+	lines = append(lines, "99|"+strings.Join(trailer, "|"))
+	lines = append(lines, afd.SignatureNotice)
+
+	var out bytes.Buffer
+	for _, line := range lines {
+		out.Write(afd.Latin1(line))
+		out.WriteString("\r\n")
+	}
+	return out.Bytes()
+}
+
+// FileName retorna o nome do arquivo: "AEJ", o CNPJ do empregador e o
+// período.
+func (f *File) FileName() string {
+	return fmt.Sprintf("AEJ_%s_%s_%s.txt", f.Employer.CNPJ,
+		f.From.Format("20060102"), f.To.Format("20060102"))
+}
+
+func (f *File) date(t time.Time) string {
+	return t.In(f.Location).Format("2006-01-02")
+}
+
+func (f *File) dateTime(t time.Time) string {
+	return t.In(f.Location).Format("2006-01-02T15:04:00-0700")
+}
+
+// clean remove do texto livre os caracteres que quebrariam o leiaute.
+func clean(value string) string {
+	value = strings.NewReplacer("|", " ", "\r", " ", "\n", " ").Replace(value)
+	return strings.TrimSpace(value)
+}
@@ -0,0 +1,231 @@
+package aej
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/afd"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/timesheet"
+)
+
+// Service monta o AEJ a partir das marcações tratadas e da jornada prevista
+// dos empregados.
+type Service struct {
+	store   *repository.Store
+	sheets  *timesheet.Service
+	program afd.Program
+}
+
+// NewService cria um Service que usa sheets para apurar os dias de jornada e
+// identifica o programa com program.
+func NewService(store *repository.Store, sheets *timesheet.Service, program afd.Program) *Service {
+	return &Service{store: store, sheets: sheets, program: program}
+}
+
+// Generate monta o AEJ do estabelecimento branchID da empresa, ou da sede
+// quando branchID é nulo, entre as datas from e to, inclusive. Faltas e
+// movimentos no banco de horas entram apenas nos dias encerrados até now.
+// Retorna *afd.MissingCPFError quando algum empregado não tem CPF.
+func (s *Service) Generate(ctx context.Context, companyID, branchID primitive.ObjectID, from, to, now time.Time) (*File, error) {
+	company, err := s.store.Companies.FindByID(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &File{
+		Employer:    afd.Employer{CNPJ: company.CNPJ, Name: company.Name},
+		Program:     s.program,
+		From:        s.sheets.Date(from),
+		To:          s.sheets.Date(to),
+		GeneratedAt: now,
+		Location:    s.sheets.Location,
+	}
+	if !branchID.IsZero() {
+		branch, err := s.store.Branches.FindByID(ctx, companyID, branchID)
+		if err != nil {
+			return nil, err
+		}
+		file.Employer.CNPJ = branch.CNPJ
+	}
+
+	users, err := s.store.Users.List(ctx, repository.UserFilter{CompanyID: companyID})
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	schedules := &scheduleSet{codes: map[Schedule]string{}}
+	for i := range users {
+		user := &users[i]
+		if user.BranchID != branchID {
+			continue
+		}
+		if user.CPF == "" {
+			missing = append(missing, user.Name)
+			continue
+		}
+
+		employee, err := s.employee(ctx, user, file.From, file.To, now, schedules)
+		if err != nil {
+			return nil, err
+		}
+		file.Employees = append(file.Employees, employee)
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, &afd.MissingCPFError{Names: missing}
+	}
+	file.Schedules = schedules.list
+	return file, nil
+}
+
+// employee monta as marcações e as ausências do empregado no período.
+func (s *Service) employee(ctx context.Context, user *models.User, from, to, now time.Time, schedules *scheduleSet) (Employee, error) {
+	employee := Employee{CPF: user.CPF, Name: user.Name}
+
+	days, err := s.sheets.Period(ctx, user, from, to)
+	if err != nil {
+		return employee, err
+	}
+
+	// As marcações desconsideradas não constam nos dias de jornada
+	records, err := s.store.TimeRecords.FindByUser(ctx, user.CompanyID, user.ID, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return employee, err
+	}
+	originals := make(map[primitive.ObjectID]*models.TimeRecord, len(records))
+	disregarded := make(map[string][]*models.TimeRecord)
+	for i := range records {
+		originals[records[i].ID] = &records[i]
+	}
+	for i := range records {
+		if records[i].Origin != models.OriginDisregarded {
+			continue
+		}
+		if target, ok := originals[records[i].TargetID]; ok {
+			key := target.Timestamp.In(s.sheets.Location).Format(timesheet.DateLayout)
+			disregarded[key] = append(disregarded[key], &records[i])
+		}
+	}
+
+	reasons := map[primitive.ObjectID]string{}
+	reason := func(record *models.TimeRecord) (string, error) {
+		if record.CorrectionID.IsZero() {
+			return "", nil
+		}
+		if value, ok := reasons[record.CorrectionID]; ok {
+			return value, nil
+		}
+		correction, err := s.store.Corrections.FindByID(ctx, user.CompanyID, record.CorrectionID)
+		if err != nil {
+			return "", err
+		}
+		reasons[record.CorrectionID] = correction.Justification
+		return correction.Justification, nil
+	}
+
+	today := s.sheets.Date(now)
+	for i := range days {
+		day := &days[i]
+		code := schedules.code(day.Expected)
+
+		var punches []Punch
+		sequence := 0
+		for j := range day.Records {
+			record := &day.Records[j]
+			punch := Punch{Timestamp: record.Timestamp, Kind: MarkExit, Source: SourceOriginal, Schedule: code}
+			if punchType, _ := models.ParsePunchType(string(record.Type)); punchType == models.PunchEntrada {
+				sequence++
+				punch.Kind = MarkEntry
+			}
+			punch.Sequence = max(sequence, 1)
+			if record.Origin == models.OriginInclusion {
+				punch.Source = SourceIncluded
+				if punch.Reason, err = reason(record); err != nil {
+					return employee, err
+				}
+			}
+			punches = append(punches, punch)
+		}
+		for _, marker := range disregarded[day.Date] {
+			punch := Punch{Timestamp: marker.Timestamp, Kind: MarkDisregarded, Source: SourceOriginal, Schedule: code}
+			if punch.Reason, err = reason(marker); err != nil {
+				return employee, err
+			}
+			punches = append(punches, punch)
+		}
+		sort.SliceStable(punches, func(a, b int) bool { return punches[a].Timestamp.Before(punches[b].Timestamp) })
+		employee.Punches = append(employee.Punches, punches...)
+
+		// O dia corrente ainda pode receber marcações
+		if !day.Time().Before(today) {
+			continue
+		}
+		switch {
+		case day.Expected.Working && len(day.Records) == 0 && day.AbonoMinutes == 0:
+			employee.Absences = append(employee.Absences, Absence{
+				Kind:    AbsenceUnjustified,
+				Date:    day.Time(),
+				Minutes: day.ExpectedMinutes,
+			})
+		case day.AdjustedBalanceMinutes > 0:
+			employee.Absences = append(employee.Absences, Absence{
+				Kind:     AbsenceHourBank,
+				Date:     day.Time(),
+				Minutes:  day.AdjustedBalanceMinutes,
+				Movement: HourBankCredit,
+			})
+		case day.AdjustedBalanceMinutes < 0:
+			employee.Absences = append(employee.Absences, Absence{
+				Kind:     AbsenceHourBank,
+				Date:     day.Time(),
+				Minutes:  -day.AdjustedBalanceMinutes,
+				Movement: HourBankDebit,
+			})
+		}
+	}
+	return employee, nil
+}
+
+// scheduleSet numera os horários contratuais distintos encontrados nos dias
+// de jornada.
+type scheduleSet struct {
+	codes map[Schedule]string
+	list  []Schedule
+}
+
+// code retorna o código do horário contratual previsto, registrando-o na
+// primeira ocorrência. Dias de folga não têm horário contratual.
+func (s *scheduleSet) code(expected timesheet.Expectation) string {
+	if !expected.Working {
+		return ""
+	}
+
+	schedule := Schedule{Minutes: expected.Minutes, Entry1: clock(expected.Entry)}
+	if expected.BreakStart.IsZero() {
+		schedule.Exit1 = clock(expected.Exit)
+	} else {
+		schedule.Exit1 = clock(expected.BreakStart)
+		schedule.Entry2 = clock(expected.BreakEnd)
+		schedule.Exit2 = clock(expected.Exit)
+	}
+
+	if code, ok := s.codes[schedule]; ok {
+		return code
+	}
+	code := strconv.Itoa(len(s.list) + 1)
+	s.codes[schedule] = code
+	schedule.Code = code
+	s.list = append(s.list, schedule)
+	return code
+}
+
+func clock(t time.Time) string {
+	return t.Format("1504")
+}
@@ -0,0 +1,231 @@
+// Package afd gera o Arquivo Fonte de Dados (AFD) do programa de tratamento
+// de ponto (REP-P), no leiaute do Anexo V da Portaria MTP 671/2021.
+package afd
+
+import (
+	"bytes"
+	"sort"
+	"time"
+)
+
+// LayoutVersion é a versão do leiaute do AFD informada no cabeçalho.
+const LayoutVersion = "003"
+
+// SignatureNotice é a última linha do AFD, que indica a assinatura digital
+// em arquivo .p7s separado.
+const SignatureNotice = "ASSINATURA_DIGITAL_EM_ARQUIVO_P7S"
+
+// Identificadores do coletor da marcação (campo 6 do registro tipo 7).
+const (
+	CollectorMobile  = "01"
+	CollectorBrowser = "02"
+	CollectorDesktop = "03"
+	CollectorDevice  = "04"
+	CollectorOther   = "05"
+)
+
+// Nome e versão do programa informados nos arquivos fiscais.
+const (
+	ProgramName    = "Ponto Digital"
+	ProgramVersion = "1.0"
+)
+
+// Program identifica o REP-P e o seu desenvolvedor nos arquivos fiscais.
+type Program struct {
+	Name           string
+	Version        string
+	INPI           string // número de registro do programa no INPI
+	DeveloperCNPJ  string
+	DeveloperName  string // razão social do desenvolvedor
+	DeveloperEmail string
+}
+
+// Employer identifica o empregador e o estabelecimento.
+type Employer struct {
+	CNPJ     string
+	Name     string // razão social
+	Location string // local de prestação de serviços
+}
+
+// EmployerEvent é a inclusão ou alteração da identificação do empregador no
+// REP (registro tipo 2).
+type EmployerEvent struct {
+	NSR            int64
+	Employer       Employer
+	ResponsibleCPF string
+	At             time.Time
+}
+
+// Employee é a inclusão, alteração ou exclusão de um empregado no REP
+// (registro tipo 5).
+type Employee struct {
+	NSR            int64
+	Operation      string // "I", "A" ou "E"
+	CPF            string
+	Name           string
+	ResponsibleCPF string
+	At             time.Time
+}
+
+// Punch é uma marcação original do REP, com o NSR e o hash encadeado
+// gravados no registro.
+type Punch struct {
+	NSR        int64
+	Hash       string
+	CPF        string
+	Timestamp  time.Time
+	RecordedAt time.Time
+	Collector  string
+	Offline    bool
+}
+
+// File reúne os dados de um AFD. Apenas os registros entre From e To,
+// inclusive, são gravados no arquivo.
+//
+// Os registros levam o NSR gravado com o evento ou a marcação, e as marcações
+// também o hash, os mesmos do comprovante e da verificação da sequência. A
+// sequência do estabelecimento numera apenas os eventos de cadastro e as
+// marcações originais; os ajustes de ponto constam somente no AEJ.
+type File struct {
+	Employer    Employer // identificação atual, informada no cabeçalho
+	Program     Program
+	Employers   []EmployerEvent
+	Employees   []Employee
+	Punches     []Punch
+	From        time.Time
+	To          time.Time
+	GeneratedAt time.Time
+	Location    *time.Location
+}
+
+type event struct {
+	nsr      int64
+	at       time.Time
+	kind     byte
+	employer *EmployerEvent
+	employee *Employee
+	punch    *Punch
+}
+
+// Bytes retorna o AFD codificado em ISO 8859-1, com linhas terminadas em CRLF.
+func (f *File) Bytes() []byte {
+	start := time.Date(f.From.Year(), f.From.Month(), f.From.Day(), 0, 0, 0, 0, f.Location)
+	end := time.Date(f.To.Year(), f.To.Month(), f.To.Day(), 0, 0, 0, 0, f.Location).AddDate(0, 0, 1)
+
+	lines := []string{f.header()}
+	counts := map[byte]int{}
+	for _, event := range f.events() {
+		if event.at.Before(start) || !event.at.Before(end) {
+			continue
+		}
+
+		var line string
+		switch event.kind {
+		case '2':
+			line = f.employerRecord(event.employer)
+		case '5':
+			line = f.employeeRecord(event.employee)
+		case '7':
+			line = f.punchRecord(event.punch)
+		}
+		lines = append(lines, line)
+		counts[event.kind]++
+	}
+	lines = append(lines, trailer(counts))
+	lines = append(lines, new(record).alpha(SignatureNotice, 100).String())
+
+	var out bytes.Buffer
+	for _, line := range lines {
+		out.Write(Latin1(line))
+		out.WriteString("\r\n")
+	}
+	return out.Bytes()
+}
+
+// FileName retorna o nome do AFD: o prefixo "AFD", o registro do programa no
+// INPI e o CNPJ do empregador.
+func (f *File) FileName() string {
+	return "AFD" + new(record).num(f.Program.INPI, 17).String() + new(record).num(f.Employer.CNPJ, 14).String() + "REP_P.txt"
+}
+
+// events ordena os registros do arquivo pelo NSR.
+func (f *File) events() []event {
+	var events []event
+	for i := range f.Employers {
+		events = append(events, event{nsr: f.Employers[i].NSR, at: f.Employers[i].At, kind: '2', employer: &f.Employers[i]})
+	}
+	for i := range f.Employees {
+		events = append(events, event{nsr: f.Employees[i].NSR, at: f.Employees[i].At, kind: '5', employee: &f.Employees[i]})
+	}
+	for i := range f.Punches {
+		events = append(events, event{nsr: f.Punches[i].NSR, at: f.Punches[i].RecordedAt, kind: '7', punch: &f.Punches[i]})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].nsr < events[j].nsr
+	})
+	return events
+}
+
+// header monta o registro tipo 1.
+func (f *File) header() string {
+	r := new(record)
+	r.int(0, 9).alpha("1", 1)
+	r.alpha("1", 1).num(f.Employer.CNPJ, 14).alpha("", 14)
+	r.alpha(f.Employer.Name, 150)
+	r.num(f.Program.INPI, 17)
+	r.date(f.From, f.Location).date(f.To, f.Location)
+	r.dateTime(f.GeneratedAt, f.Location)
+	r.alpha(LayoutVersion, 3)
+	r.alpha("1", 1).num(f.Program.DeveloperCNPJ, 14)
+	r.alpha("", 30) // modelo, apenas para REP-C
+	return r.withCRC().String()
+}
+
+// employerRecord monta o registro tipo 2, de inclusão ou alteração da
+// identificação do empregador.
+func (f *File) employerRecord(event *EmployerEvent) string {
+	r := new(record)
+	r.int(int(event.NSR), 9).alpha("2", 1).dateTime(event.At, f.Location)
+	r.num(event.ResponsibleCPF, 14)
+	r.alpha("1", 1).num(event.Employer.CNPJ, 14).alpha("", 14)
+	r.alpha(event.Employer.Name, 150).alpha(event.Employer.Location, 100)
+	return r.withCRC().String()
+}
+
+// employeeRecord monta o registro tipo 5, de inclusão, alteração ou exclusão
+// do empregado.
+func (f *File) employeeRecord(employee *Employee) string {
+	r := new(record)
+	r.int(int(employee.NSR), 9).alpha("5", 1).dateTime(employee.At, f.Location)
+	r.alpha(employee.Operation, 1).num(employee.CPF, 12).alpha(employee.Name, 52)
+	r.alpha("", 4) // demais dados de identificação
+	r.num(employee.ResponsibleCPF, 11)
+	return r.withCRC().String()
+}
+
+// punchRecord monta o registro tipo 7, de marcação do REP-P. O último campo é
+// o hash do registro, encadeado ao do registro anterior do estabelecimento
+// (punch.Hash).
+func (f *File) punchRecord(punch *Punch) string {
+	offline := "0"
+	if punch.Offline {
+		offline = "1"
+	}
+
+	r := new(record)
+	r.int(int(punch.NSR), 9).alpha("7", 1).dateTime(punch.Timestamp, f.Location)
+	r.num(punch.CPF, 12).dateTime(punch.RecordedAt, f.Location)
+	r.num(punch.Collector, 2).alpha(offline, 1)
+	return r.alpha(punch.Hash, 64).String()
+}
+
+// trailer monta o registro tipo 9, com a quantidade de registros de cada tipo.
+func trailer(counts map[byte]int) string {
+	r := new(record)
+	r.alpha("999999999", 9)
+	for _, kind := range []byte{'2', '3', '4', '5', '6', '7'} {
+		r.int(counts[kind], 9)
+	}
+	return r.alpha("9", 1).String()
+}
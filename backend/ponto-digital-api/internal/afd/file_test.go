@@ -0,0 +1,153 @@
+package afd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCRC16(t *testing.T) {
+	tests := []struct {
+		data string
+		want uint16
+	}{
+		{"", 0x0000},
+		{"A", 0x538D},
+		{"123456789", 0x2189}, // valor de conferência do CRC-16/KERMIT
+		{"Ação", 0xD7A8},      // calculado sobre a codificação ISO 8859-1
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			if got := crc16(Latin1(tt.data)); got != tt.want {
+				t.Errorf("crc16(%q) = %04X, want %04X", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordFields(t *testing.T) {
+	loc := time.FixedZone("BRT", -3*60*60)
+	moment := time.Date(2025, 3, 10, 11, 4, 59, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		build func(r *record) *record
+		want  string
+	}{
+		{"numérico completado com zeros", func(r *record) *record { return r.num("123", 6) }, "000123"},
+		{"numérico truncado à esquerda", func(r *record) *record { return r.num("1234567", 4) }, "4567"},
+		{"inteiro", func(r *record) *record { return r.int(42, 9) }, "000000042"},
+		{"alfanumérico completado com espaços", func(r *record) *record { return r.alpha("ab", 4) }, "ab  "},
+		{"alfanumérico truncado por caractere", func(r *record) *record { return r.alpha("Conceição", 7) }, "Conceiç"},
+		{"data e hora no fuso, sem segundos", func(r *record) *record { return r.dateTime(moment, loc) }, "2025-03-10T08:04:00-0300"},
+		{"data", func(r *record) *record { return r.date(moment, loc) }, "2025-03-10"},
+		{"CRC da linha", func(r *record) *record { return r.alpha("123456789", 9).withCRC() }, "1234567892189"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.build(new(record)).String(); got != tt.want {
+				t.Errorf("record = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileBytes(t *testing.T) {
+	loc := time.FixedZone("BRT", -3*60*60)
+	day := func(d, hour, minute int) time.Time {
+		return time.Date(2025, 3, d, hour, minute, 0, 0, loc)
+	}
+
+	file := &File{
+		Employer: Employer{CNPJ: "11222333000181", Name: "Padaria São João", Location: "Rua A - Recife - PE"},
+		Program:  Program{INPI: "BR512025000001", DeveloperCNPJ: "99888777000166"},
+		Employers: []EmployerEvent{
+			{NSR: 1, Employer: Employer{CNPJ: "11222333000181", Name: "Padaria São João"}, At: day(1, 9, 0)},
+			{NSR: 8, Employer: Employer{CNPJ: "11222333000181", Name: "Padaria São João", Location: "Rua A - Recife - PE"}, ResponsibleCPF: "11144477735", At: day(11, 9, 0)},
+		},
+		Employees: []Employee{
+			{NSR: 2, Operation: "I", CPF: "12345678909", Name: "Ana", At: day(1, 10, 0)},
+			{NSR: 9, Operation: "I", CPF: "98765432100", Name: "João", ResponsibleCPF: "11144477735", At: day(12, 10, 0)},
+		},
+		Punches: []Punch{
+			{NSR: 3, Hash: strings.Repeat("a", 64), CPF: "12345678909", Timestamp: day(9, 8, 0), RecordedAt: day(9, 8, 0), Collector: CollectorBrowser},
+			{NSR: 11, Hash: strings.Repeat("c", 64), CPF: "98765432100", Timestamp: day(12, 17, 30), RecordedAt: day(12, 17, 30), Collector: CollectorMobile},
+			{NSR: 7, Hash: strings.Repeat("b", 64), CPF: "12345678909", Timestamp: day(10, 8, 0), RecordedAt: day(10, 8, 0), Collector: CollectorBrowser},
+		},
+		From:        day(10, 0, 0),
+		To:          day(12, 0, 0),
+		GeneratedAt: day(13, 9, 0),
+		Location:    loc,
+	}
+
+	content := file.Bytes()
+	if !bytes.HasSuffix(content, []byte("\r\n")) {
+		t.Fatalf("AFD does not end with CRLF")
+	}
+	if !bytes.Contains(content, []byte("Padaria S\xe3o Jo\xe3o")) {
+		t.Errorf("AFD is not encoded in ISO 8859-1")
+	}
+	// As linhas ficam em ISO 8859-1, um byte por caractere
+	lines := strings.Split(strings.TrimSuffix(string(content), "\r\n"), "\r\n")
+
+	// Registros dentro do período, em ordem de NSR: a marcação do dia 9, a
+	// inclusão do empregador e a da primeira empregada ficam de fora
+	want := []struct {
+		kind   string
+		length int
+		nsr    string
+		crc    bool
+	}{
+		{kind: "1", length: 302, nsr: "000000000", crc: true},
+		{kind: "7", length: 137, nsr: "000000007"},
+		{kind: "2", length: 331, nsr: "000000008", crc: true},
+		{kind: "5", length: 118, nsr: "000000009", crc: true},
+		{kind: "7", length: 137, nsr: "000000011"},
+		{kind: "9", length: 64, nsr: "999999999"},
+	}
+	if len(lines) != len(want)+1 {
+		t.Fatalf("AFD has %d lines, want %d:\n%s", len(lines), len(want)+1, strings.Join(lines, "\n"))
+	}
+	for i, w := range want {
+		line := lines[i]
+		if len(line) != w.length {
+			t.Errorf("line %d (type %s) has %d characters, want %d", i+1, w.kind, len(line), w.length)
+		}
+		if line[:9] != w.nsr {
+			t.Errorf("line %d NSR = %s, want %s", i+1, line[:9], w.nsr)
+		}
+		if w.kind != "9" && line[9:10] != w.kind {
+			t.Errorf("line %d type = %s, want %s", i+1, line[9:10], w.kind)
+		}
+		if w.crc {
+			body := []byte(line[:len(line)-4])
+			if got := fmt.Sprintf("%04X", crc16(body)); got != line[len(line)-4:] {
+				t.Errorf("line %d CRC = %s, want %s", i+1, line[len(line)-4:], got)
+			}
+		}
+	}
+
+	if punch := lines[1]; !strings.HasSuffix(punch, strings.Repeat("b", 64)) {
+		t.Errorf("punch record does not carry the stored hash: %q", punch)
+	}
+	if employer := lines[2]; !strings.HasPrefix(employer, "0000000082"+"2025-03-11T09:00:00-0300"+"00011144477735"+"111222333000181") {
+		t.Errorf("employer record fields = %q", employer)
+	}
+	if employee := lines[3]; !strings.HasPrefix(employee, "0000000095"+"2025-03-12T10:00:00-0300"+"I"+"098765432100"+"Jo\xe3o") ||
+		!strings.HasSuffix(employee[:len(employee)-4], "11144477735") {
+		t.Errorf("employee record fields = %q", employee)
+	}
+	if punch := lines[4]; !strings.Contains(punch, "2025-03-12T17:30:00-03000987654321002025-03-12T17:30:00-0300010") {
+		t.Errorf("punch record fields = %q", punch)
+	}
+	if trailer := lines[5]; trailer != "999999999"+"000000001"+"000000000"+"000000000"+"000000001"+"000000000"+"000000002"+"9" {
+		t.Errorf("trailer = %q", trailer)
+	}
+	if notice := strings.TrimRight(lines[6], " "); notice != SignatureNotice {
+		t.Errorf("last line = %q, want %q", notice, SignatureNotice)
+	}
+}
@@ -0,0 +1,94 @@
+package afd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// record monta uma linha de leiaute fixo, campo a campo.
+type record struct {
+	b strings.Builder
+}
+
+// num acrescenta um campo numérico, alinhado à direita e completado com zeros.
+func (r *record) num(value string, width int) *record {
+	if len(value) > width {
+		value = value[len(value)-width:]
+	}
+	r.b.WriteString(strings.Repeat("0", width-len(value)))
+	r.b.WriteString(value)
+	return r
+}
+
+// int acrescenta um número inteiro como campo numérico.
+func (r *record) int(value, width int) *record {
+	return r.num(strconv.Itoa(value), width)
+}
+
+// alpha acrescenta um campo alfanumérico, alinhado à esquerda e completado
+// com espaços. Textos maiores que o campo são truncados.
+func (r *record) alpha(value string, width int) *record {
+	runes := []rune(value)
+	if len(runes) > width {
+		runes = runes[:width]
+	}
+	r.b.WriteString(string(runes))
+	r.b.WriteString(strings.Repeat(" ", width-len(runes)))
+	return r
+}
+
+// dateTime acrescenta data e hora no formato AAAA-MM-ddThh:mm:00ZZZZZ. As
+// marcações têm precisão de minutos.
+func (r *record) dateTime(t time.Time, loc *time.Location) *record {
+	r.b.WriteString(t.In(loc).Format("2006-01-02T15:04:00-0700"))
+	return r
+}
+
+// date acrescenta uma data no formato AAAA-MM-dd.
+func (r *record) date(t time.Time, loc *time.Location) *record {
+	r.b.WriteString(t.In(loc).Format("2006-01-02"))
+	return r
+}
+
+// withCRC acrescenta o CRC-16 da linha montada até aqui, em quatro dígitos
+// hexadecimais.
+func (r *record) withCRC() *record {
+	r.b.WriteString(fmt.Sprintf("%04X", crc16(Latin1(r.b.String()))))
+	return r
+}
+
+func (r *record) String() string {
+	return r.b.String()
+}
+
+// crc16 calcula o CRC-16/KERMIT (polinômio 0x1021 refletido, valor inicial
+// zero) adotado pela Portaria 671 para os registros do AFD.
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0x8408
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc
+}
+
+// Latin1 codifica o texto em ISO 8859-1, a codificação dos arquivos fiscais.
+// Caracteres fora dela são substituídos por "?".
+func Latin1(value string) []byte {
+	encoded := make([]byte, 0, len(value))
+	for _, r := range value {
+		if r > 0xFF {
+			r = '?'
+		}
+		encoded = append(encoded, byte(r))
+	}
+	return encoded
+}
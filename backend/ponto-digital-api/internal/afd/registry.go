@@ -0,0 +1,116 @@
+package afd
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+)
+
+// Registry grava os eventos de cadastro do REP, que o AFD apresenta como
+// registros tipo 2 (identificação do empregador) e tipo 5 (empregados), com
+// NSRs da mesma sequência das marcações do estabelecimento.
+type Registry struct {
+	store *repository.Store
+}
+
+// NewRegistry cria um Registry sobre store.
+func NewRegistry(store *repository.Store) *Registry {
+	return &Registry{store: store}
+}
+
+// Employer grava a inclusão ou alteração da identificação do empregador no
+// estabelecimento branch, ou na sede quando branch é nulo. O estabelecimento
+// é identificado pelo seu CNPJ e pela razão social da empresa.
+func (r *Registry) Employer(ctx context.Context, company *models.Company, branch *models.Branch, operation models.RepEventOperation, responsibleID primitive.ObjectID, at time.Time) error {
+	event := models.RepEvent{
+		CompanyID:     company.ID,
+		Kind:          models.RepEventEmployer,
+		Operation:     operation,
+		CNPJ:          company.CNPJ,
+		Name:          company.Name,
+		ResponsibleID: responsibleID,
+		CreatedAt:     at,
+	}
+	if branch != nil {
+		event.BranchID = branch.ID
+		event.CNPJ = branch.CNPJ
+		event.Location = BranchLocation(branch)
+	}
+	return r.store.TimeRecords.CreateEvent(ctx, &event)
+}
+
+// CompanyChanged grava a alteração da identificação do empregador na sede e,
+// quando a razão social mudou, em todos os estabelecimentos da empresa.
+func (r *Registry) CompanyChanged(ctx context.Context, before, after *models.Company, responsibleID primitive.ObjectID, at time.Time) error {
+	if before.Name == after.Name && before.CNPJ == after.CNPJ {
+		return nil
+	}
+	if err := r.Employer(ctx, after, nil, models.RepEventChange, responsibleID, at); err != nil {
+		return err
+	}
+	if before.Name == after.Name {
+		return nil
+	}
+
+	branches, err := r.store.Branches.ListByCompany(ctx, after.ID)
+	if err != nil {
+		return err
+	}
+	for i := range branches {
+		if err := r.Employer(ctx, after, &branches[i], models.RepEventChange, responsibleID, at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BranchChanged grava a alteração da identificação do empregador no
+// estabelecimento quando o CNPJ ou o local de prestação de serviços mudou.
+func (r *Registry) BranchChanged(ctx context.Context, company *models.Company, before, after *models.Branch, responsibleID primitive.ObjectID, at time.Time) error {
+	if before.CNPJ == after.CNPJ && BranchLocation(before) == BranchLocation(after) {
+		return nil
+	}
+	return r.Employer(ctx, company, after, models.RepEventChange, responsibleID, at)
+}
+
+// Employee grava os eventos do empregado decorrentes da mudança do cadastro
+// de before para after: a inclusão, quando before é nulo ou não tinha CPF; a
+// alteração do nome ou do CPF; ou, na transferência de estabelecimento, a
+// exclusão no anterior e a inclusão no novo. Usuários sem CPF não constam do
+// REP.
+func (r *Registry) Employee(ctx context.Context, before, after *models.User, responsibleID primitive.ObjectID, at time.Time) error {
+	registered := before != nil && before.CPF != ""
+	if registered && after.CPF != "" && before.BranchID == after.BranchID {
+		if before.CPF == after.CPF && before.Name == after.Name {
+			return nil
+		}
+		return r.employee(ctx, after, models.RepEventChange, responsibleID, at)
+	}
+
+	if registered {
+		if err := r.employee(ctx, before, models.RepEventExclusion, responsibleID, at); err != nil {
+			return err
+		}
+	}
+	if after.CPF == "" {
+		return nil
+	}
+	return r.employee(ctx, after, models.RepEventInclusion, responsibleID, at)
+}
+
+func (r *Registry) employee(ctx context.Context, user *models.User, operation models.RepEventOperation, responsibleID primitive.ObjectID, at time.Time) error {
+	return r.store.TimeRecords.CreateEvent(ctx, &models.RepEvent{
+		CompanyID:     user.CompanyID,
+		BranchID:      user.BranchID,
+		Kind:          models.RepEventEmployee,
+		Operation:     operation,
+		UserID:        user.ID,
+		CPF:           user.CPF,
+		Name:          user.Name,
+		ResponsibleID: responsibleID,
+		CreatedAt:     at,
+	})
+}
@@ -0,0 +1,171 @@
+package afd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+)
+
+// MissingCPFError indica empregados sem CPF, que não podem constar nos
+// arquivos fiscais.
+type MissingCPFError struct {
+	Names []string
+}
+
+func (e *MissingCPFError) Error() string {
+	return fmt.Sprintf("Empregados sem CPF cadastrado: %s", strings.Join(e.Names, ", "))
+}
+
+// Service monta o AFD a partir dos dados persistidos.
+type Service struct {
+	store    *repository.Store
+	program  Program
+	location *time.Location
+}
+
+// NewService cria um Service que identifica o REP-P com program e usa loc
+// para as datas e horas do arquivo.
+func NewService(store *repository.Store, program Program, loc *time.Location) *Service {
+	return &Service{store: store, program: program, location: loc}
+}
+
+// Generate monta o AFD do estabelecimento branchID da empresa, ou da sede
+// quando branchID é nulo, com os registros de from a to, inclusive. Retorna
+// *MissingCPFError quando algum empregado com marcações no período não tem
+// CPF.
+func (s *Service) Generate(ctx context.Context, companyID, branchID primitive.ObjectID, from, to, now time.Time) (*File, error) {
+	company, err := s.store.Companies.FindByID(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &File{
+		Employer:    Employer{CNPJ: company.CNPJ, Name: company.Name},
+		Program:     s.program,
+		From:        from,
+		To:          to,
+		GeneratedAt: now,
+		Location:    s.location,
+	}
+	if !branchID.IsZero() {
+		branch, err := s.store.Branches.FindByID(ctx, companyID, branchID)
+		if err != nil {
+			return nil, err
+		}
+		file.Employer.CNPJ = branch.CNPJ
+		file.Employer.Location = BranchLocation(branch)
+	}
+
+	users, err := s.store.Users.List(ctx, repository.UserFilter{CompanyID: companyID})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]*models.User, len(users))
+	for i := range users {
+		byID[users[i].ID] = &users[i]
+	}
+
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, s.location)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, s.location).AddDate(0, 0, 1)
+	records, err := s.store.TimeRecords.FindByBranch(ctx, companyID, branchID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.store.TimeRecords.FindEvents(ctx, companyID, branchID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		// O responsável sem CPF, como o administrador cadastrado com a
+		// empresa, é informado com zeros
+		var responsibleCPF string
+		if responsible, ok := byID[event.ResponsibleID]; ok {
+			responsibleCPF = responsible.CPF
+		}
+
+		switch event.Kind {
+		case models.RepEventEmployer:
+			file.Employers = append(file.Employers, EmployerEvent{
+				NSR:            event.NSR,
+				Employer:       Employer{CNPJ: event.CNPJ, Name: event.Name, Location: event.Location},
+				ResponsibleCPF: responsibleCPF,
+				At:             event.CreatedAt,
+			})
+		case models.RepEventEmployee:
+			file.Employees = append(file.Employees, Employee{
+				NSR:            event.NSR,
+				Operation:      string(event.Operation),
+				CPF:            event.CPF,
+				Name:           event.Name,
+				ResponsibleCPF: responsibleCPF,
+				At:             event.CreatedAt,
+			})
+		}
+	}
+
+	// Usuários sem CPF e sem marcações no período, como o administrador
+	// cadastrado com a empresa, ficam fora do arquivo sem impedir a geração
+	missing := map[string]bool{}
+	for _, record := range records {
+		// Ajustes de ponto não são marcações do REP; constam apenas no AEJ
+		if record.Origin != "" {
+			continue
+		}
+		user, ok := byID[record.UserID]
+		if !ok || user.CPF == "" {
+			if ok {
+				missing[user.Name] = true
+			}
+			continue
+		}
+		file.Punches = append(file.Punches, Punch{
+			NSR:        record.NSR,
+			Hash:       record.Hash,
+			CPF:        user.CPF,
+			Timestamp:  record.Timestamp,
+			RecordedAt: record.Timestamp,
+			Collector:  collector(record.Device),
+		})
+	}
+
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, &MissingCPFError{Names: names}
+	}
+	return file, nil
+}
+
+// BranchLocation descreve o local de prestação de serviços do
+// estabelecimento a partir do seu endereço.
+func BranchLocation(branch *models.Branch) string {
+	parts := []string{}
+	for _, part := range []string{branch.Address, branch.City, branch.State} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " - ")
+}
+
+// collector identifica o coletor da marcação pelo dispositivo informado pelo
+// cliente. As marcações sem identificação vêm da aplicação web.
+func collector(device string) string {
+	device = strings.ToLower(device)
+	for _, mobile := range []string{"android", "iphone", "ios", "mobile"} {
+		if strings.Contains(device, mobile) {
+			return CollectorMobile
+		}
+	}
+	return CollectorBrowser
+}
@@ -0,0 +1,166 @@
+package afd
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+)
+
+func TestGenerateMissingCPF(t *testing.T) {
+	ctx := context.Background()
+	loc := time.FixedZone("BRT", -3*60*60)
+	from := time.Date(2025, 3, 10, 0, 0, 0, 0, loc)
+	to := time.Date(2025, 3, 14, 0, 0, 0, 0, loc)
+
+	tests := []struct {
+		name        string
+		punchBefore bool // marcação sem CPF anterior ao período
+		punchDuring bool // marcação sem CPF dentro do período
+		want        []string
+	}{
+		{name: "usuário sem CPF e sem marcações"},
+		{name: "marcação sem CPF fora do período", punchBefore: true},
+		{name: "marcação sem CPF no período", punchDuring: true, want: []string{"Bruno"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := repository.NewMemoryStore()
+			company := models.Company{Name: "ACME", CNPJ: "11222333000181"}
+			if err := store.Companies.Create(ctx, &company); err != nil {
+				t.Fatal(err)
+			}
+			withCPF := models.User{Name: "Ana", CPF: "12345678909", CompanyID: company.ID}
+			withoutCPF := models.User{Name: "Bruno", CompanyID: company.ID}
+			for _, user := range []*models.User{&withCPF, &withoutCPF} {
+				if err := store.Users.Create(ctx, user); err != nil {
+					t.Fatal(err)
+				}
+				if err := NewRegistry(store).Employee(ctx, nil, user, primitive.NilObjectID, from); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			punch := func(user *models.User, at time.Time) {
+				record := models.TimeRecord{CompanyID: company.ID, UserID: user.ID, Timestamp: at}
+				if err := store.TimeRecords.CreatePunch(ctx, &record, ""); err != nil {
+					t.Fatal(err)
+				}
+			}
+			punch(&withCPF, from.Add(8*time.Hour))
+			if tt.punchBefore {
+				punch(&withoutCPF, from.Add(-16*time.Hour))
+			}
+			if tt.punchDuring {
+				punch(&withoutCPF, from.Add(9*time.Hour))
+			}
+
+			file, err := NewService(store, Program{}, loc).Generate(ctx, company.ID, primitive.NilObjectID, from, to, to)
+
+			var missing *MissingCPFError
+			if tt.want != nil {
+				if !errors.As(err, &missing) || !reflect.DeepEqual(missing.Names, tt.want) {
+					t.Fatalf("Generate() error = %v, want missing %v", err, tt.want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if len(file.Employees) != 1 || file.Employees[0].CPF != withCPF.CPF {
+				t.Errorf("Employees = %+v, want only %s", file.Employees, withCPF.Name)
+			}
+			if len(file.Punches) != 1 || file.Punches[0].NSR == 0 || file.Punches[0].Hash == "" {
+				t.Errorf("Punches = %+v, want the stored NSR and hash", file.Punches)
+			}
+		})
+	}
+}
+
+func TestGenerateRegistrationEvents(t *testing.T) {
+	ctx := context.Background()
+	loc := time.FixedZone("BRT", -3*60*60)
+	from := time.Date(2025, 3, 10, 0, 0, 0, 0, loc)
+	at := func(hour int) time.Time { return from.Add(time.Duration(hour) * time.Hour) }
+
+	store := repository.NewMemoryStore()
+	registry := NewRegistry(store)
+	company := models.Company{Name: "ACME", CNPJ: "11222333000181"}
+	if err := store.Companies.Create(ctx, &company); err != nil {
+		t.Fatal(err)
+	}
+	branch := models.Branch{CompanyID: company.ID, CNPJ: "11222333000262", City: "Recife", State: "PE"}
+	if err := store.Branches.Create(ctx, &branch); err != nil {
+		t.Fatal(err)
+	}
+	admin := models.User{Name: "Carla", CPF: "11144477735", CompanyID: company.ID, Role: models.RoleAdmin}
+	if err := store.Users.Create(ctx, &admin); err != nil {
+		t.Fatal(err)
+	}
+
+	// Os eventos de cadastro e a marcação ocupam a mesma sequência de NSR
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(registry.Employer(ctx, &company, nil, models.RepEventInclusion, admin.ID, at(7)))
+	must(registry.Employer(ctx, &company, &branch, models.RepEventInclusion, admin.ID, at(7)))
+	user := models.User{Name: "Ana", CPF: "12345678909", CompanyID: company.ID}
+	must(store.Users.Create(ctx, &user))
+	must(registry.Employee(ctx, nil, &user, admin.ID, at(8)))
+	record := models.TimeRecord{CompanyID: company.ID, UserID: user.ID, Timestamp: at(9)}
+	must(store.TimeRecords.CreatePunch(ctx, &record, ""))
+	renamed := user
+	renamed.Name = "Ana Souza"
+	must(registry.Employee(ctx, &user, &renamed, admin.ID, at(10)))
+	moved := renamed
+	moved.BranchID = branch.ID
+	must(registry.Employee(ctx, &renamed, &moved, admin.ID, at(11)))
+	must(store.Users.UpdateBranch(ctx, &moved))
+
+	tests := []struct {
+		name     string
+		branchID primitive.ObjectID
+		cnpj     string
+		want     []string // NSR, tipo e, no tipo 5, a operação de cada registro
+	}{
+		{name: "sede", cnpj: company.CNPJ, want: []string{"0000000012", "0000000025I", "0000000037", "0000000045A", "0000000055E"}},
+		{name: "estabelecimento", branchID: branch.ID, cnpj: branch.CNPJ, want: []string{"0000000012", "0000000025I"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := NewService(store, Program{}, loc).Generate(ctx, company.ID, tt.branchID, from, from, at(12))
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if len(file.Employers) != 1 || file.Employers[0].Employer.CNPJ != tt.cnpj || file.Employers[0].ResponsibleCPF != admin.CPF {
+				t.Errorf("Employers = %+v, want %s included by %s", file.Employers, tt.cnpj, admin.CPF)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(string(file.Bytes()), "\r\n"), "\r\n")
+			records := lines[1 : len(lines)-2]
+			if len(records) != len(tt.want) {
+				t.Fatalf("AFD has %d records, want %d:\n%s", len(records), len(tt.want), strings.Join(records, "\n"))
+			}
+			for i, want := range tt.want {
+				got := records[i][:10]
+				if len(want) > 10 {
+					got += records[i][34:35]
+				}
+				if got != want {
+					t.Errorf("record %d = %q, want %q", i+1, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+// Package closing trata o fechamento das competências (meses) da empresa.
+// Uma competência fechada não aceita novas marcações, ajustes, ausências nem
+// lançamentos no banco de horas até ser reaberta com justificativa, e guarda
+// os totais de cada funcionário para a folha de pagamento.
+package closing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/espelho"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/timesheet"
+)
+
+var (
+	ErrNotEnded      = errors.New("A competência só pode ser fechada depois do fim do mês")
+	ErrAlreadyClosed = errors.New("Competência já fechada")
+	ErrNotClosed     = errors.New("Competência não está fechada")
+	ErrReasonMissing = errors.New("Informe o motivo da reabertura")
+)
+
+// ClosedError indica que a operação alteraria uma competência fechada.
+type ClosedError struct {
+	Year  int
+	Month int
+}
+
+func (e *ClosedError) Error() string {
+	return fmt.Sprintf("A competência %02d/%d está fechada; reabra-a para alterar os registros", e.Month, e.Year)
+}
+
+// Service fecha e reabre as competências e verifica se um período ainda pode
+// ser alterado.
+type Service struct {
+	store    *repository.Store
+	sheets   *timesheet.Service
+	espelhos *espelho.Service
+}
+
+// NewService cria um Service que usa sheets para apurar os totais do mês.
+func NewService(store *repository.Store, sheets *timesheet.Service) *Service {
+	return &Service{store: store, sheets: sheets, espelhos: espelho.NewService(store, sheets)}
+}
+
+// Check retorna *ClosedError quando algum mês entre as datas from e to,
+// inclusive, está fechado na empresa.
+func (s *Service) Check(ctx context.Context, companyID primitive.ObjectID, from, to time.Time) error {
+	first := monthIndex(from.In(s.sheets.Location))
+	last := monthIndex(to.In(s.sheets.Location))
+
+	closings, err := s.store.Closings.List(ctx, companyID)
+	if err != nil {
+		return err
+	}
+	// A lista vem do mês mais recente para o mais antigo; o erro aponta o
+	// primeiro mês fechado do período
+	var closed *ClosedError
+	for _, closing := range closings {
+		index := closing.Year*12 + closing.Month - 1
+		if closing.Status == models.ClosingClosed && index >= first && index <= last {
+			closed = &ClosedError{Year: closing.Year, Month: closing.Month}
+		}
+	}
+	if closed != nil {
+		return closed
+	}
+	return nil
+}
+
+// Close fecha a competência do mês informado, já encerrado em now, guardando
+// os totais e o espelho de ponto de cada funcionário. Uma competência
+// reaberta pode ser fechada novamente, com os totais recalculados.
+func (s *Service) Close(ctx context.Context, companyID primitive.ObjectID, year, month int, by primitive.ObjectID, now time.Time) (*models.PeriodClosing, error) {
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, s.sheets.Location)
+	next := start.AddDate(0, 1, 0)
+	if now.Before(next) {
+		return nil, ErrNotEnded
+	}
+
+	closing, err := s.store.Closings.FindByMonth(ctx, companyID, year, month)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		closing = &models.PeriodClosing{
+			CompanyID:  companyID,
+			Year:       year,
+			Month:      month,
+			Reopenings: []models.ClosingReopening{},
+			CreatedAt:  now,
+		}
+	case err != nil:
+		return nil, err
+	case closing.Status == models.ClosingClosed:
+		return nil, ErrAlreadyClosed
+	}
+
+	users, err := s.store.Users.List(ctx, repository.UserFilter{CompanyID: companyID})
+	if err != nil {
+		return nil, err
+	}
+
+	totals := []models.ClosingTotals{}
+	for i := range users {
+		user := &users[i]
+		// Funcionários cadastrados depois do mês não têm apuração
+		if !user.CreatedAt.Before(next) {
+			continue
+		}
+		total, err := s.snapshot(ctx, user, year, month, now)
+		if err != nil {
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+
+	closing.Status = models.ClosingClosed
+	closing.ClosedBy = by
+	closing.ClosedAt = now
+	closing.Totals = totals
+	closing.UpdatedAt = now
+	if closing.ID.IsZero() {
+		err = s.store.Closings.Create(ctx, closing)
+	} else {
+		err = s.store.Closings.Update(ctx, closing)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return closing, nil
+}
+
+// snapshot apura os totais do funcionário no mês e guarda o espelho de ponto
+// emitido no fechamento. Cada fechamento grava um novo arquivo, preservando
+// os espelhos dos fechamentos desfeitos por reabertura.
+func (s *Service) snapshot(ctx context.Context, user *models.User, year, month int, now time.Time) (models.ClosingTotals, error) {
+	doc, err := s.espelhos.Build(ctx, user, year, month, now)
+	if err != nil {
+		return models.ClosingTotals{}, err
+	}
+
+	content := doc.PDF()
+	sum := sha256.Sum256(content)
+	key := fmt.Sprintf("%s/fechamentos/%04d-%02d/%s-%d.pdf",
+		user.CompanyID.Hex(), year, month, user.ID.Hex(), now.Unix())
+	if err := s.store.Files.Put(ctx, key, content); err != nil {
+		return models.ClosingTotals{}, err
+	}
+
+	totals := doc.Totals
+	overtime := make([]models.ClosingOvertime, 0, len(totals.Overtime))
+	for _, rate := range totals.Overtime {
+		overtime = append(overtime, models.ClosingOvertime{Rate: rate.Rate, Minutes: rate.Minutes})
+	}
+	return models.ClosingTotals{
+		UserID:           user.ID,
+		Name:             user.Name,
+		CPF:              user.CPF,
+		ExpectedMinutes:  totals.ExpectedMinutes,
+		WorkedMinutes:    totals.AdjustedWorkedMinutes,
+		BalanceMinutes:   totals.AdjustedBalanceMinutes,
+		OvertimeMinutes:  totals.OvertimeMinutes,
+		Overtime:         overtime,
+		NightPaidMinutes: totals.NightPaidMinutes,
+		AbonoMinutes:     totals.AbonoMinutes,
+		AbonoDays:        totals.AbonoDays,
+		MissedDays:       totals.MissedDays,
+		LateDays:         totals.AdjustedLateDays,
+		HourBankMinutes:  doc.HourBankMinutes,
+		DocumentKey:      key,
+		DocumentHash:     hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+// Reopen reabre uma competência fechada, registrando quem a reabriu e o
+// motivo. Os totais do último fechamento são mantidos até o próximo.
+func (s *Service) Reopen(ctx context.Context, companyID primitive.ObjectID, year, month int, by primitive.ObjectID, reason string, now time.Time) (*models.PeriodClosing, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrReasonMissing
+	}
+
+	closing, err := s.store.Closings.FindByMonth(ctx, companyID, year, month)
+	if err != nil {
+		return nil, err
+	}
+	if closing.Status != models.ClosingClosed {
+		return nil, ErrNotClosed
+	}
+
+	closing.Status = models.ClosingReopened
+	closing.Reopenings = append(closing.Reopenings, models.ClosingReopening{
+		ReopenedBy: by,
+		ReopenedAt: now,
+		Reason:     reason,
+		ClosedAt:   closing.ClosedAt,
+	})
+	closing.UpdatedAt = now
+	if err := s.store.Closings.Update(ctx, closing); err != nil {
+		return nil, err
+	}
+	return closing, nil
+}
+
+// monthIndex numera os meses em sequência, para comparar competências.
+func monthIndex(t time.Time) int {
+	return t.Year()*12 + int(t.Month()) - 1
+}
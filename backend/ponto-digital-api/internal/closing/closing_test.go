@@ -0,0 +1,145 @@
+package closing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/timesheet"
+)
+
+// newService cria um Service sobre um repositório em memória com uma empresa
+// e um funcionário cadastrado antes de março de 2025.
+func newService(t *testing.T) (*Service, *repository.Store, *models.User) {
+	t.Helper()
+	ctx := context.Background()
+
+	store := repository.NewMemoryStore()
+	company := models.Company{Name: "ACME", CNPJ: "11222333000181"}
+	if err := store.Companies.Create(ctx, &company); err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{Name: "Ana", CompanyID: company.ID, Role: models.RoleEmployee, CreatedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)}
+	if err := store.Users.Create(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+	return NewService(store, timesheet.NewService(store)), store, &user
+}
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+	service, store, user := newService(t)
+	loc := service.sheets.Location
+
+	for _, closing := range []models.PeriodClosing{
+		{CompanyID: user.CompanyID, Year: 2025, Month: 2, Status: models.ClosingClosed},
+		{CompanyID: user.CompanyID, Year: 2025, Month: 3, Status: models.ClosingReopened},
+		{CompanyID: user.CompanyID, Year: 2025, Month: 5, Status: models.ClosingClosed},
+	} {
+		if err := store.Closings.Create(ctx, &closing); err != nil {
+			t.Fatal(err)
+		}
+	}
+	day := func(month, d int) time.Time { return time.Date(2025, time.Month(month), d, 12, 0, 0, 0, loc) }
+
+	tests := []struct {
+		name      string
+		companyID primitive.ObjectID
+		from, to  time.Time
+		want      *ClosedError
+	}{
+		{name: "mês fechado", companyID: user.CompanyID, from: day(2, 10), to: day(2, 10), want: &ClosedError{Year: 2025, Month: 2}},
+		{name: "mês reaberto", companyID: user.CompanyID, from: day(3, 1), to: day(3, 31)},
+		{name: "mês sem fechamento", companyID: user.CompanyID, from: day(4, 1), to: day(4, 30)},
+		{name: "período com dois meses fechados aponta o primeiro", companyID: user.CompanyID, from: day(1, 15), to: day(6, 15), want: &ClosedError{Year: 2025, Month: 2}},
+		{name: "primeiro instante do mês no fuso da empresa", companyID: user.CompanyID, from: time.Date(2025, 5, 1, 0, 0, 0, 0, loc), to: time.Date(2025, 5, 1, 0, 0, 0, 0, loc), want: &ClosedError{Year: 2025, Month: 5}},
+		{name: "outra empresa", companyID: primitive.NewObjectID(), from: day(2, 10), to: day(2, 10)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.Check(ctx, tt.companyID, tt.from, tt.to)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Check() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var closed *ClosedError
+			if !errors.As(err, &closed) || *closed != *tt.want {
+				t.Errorf("Check() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCloseAndReopen(t *testing.T) {
+	ctx := context.Background()
+	service, store, user := newService(t)
+	loc := service.sheets.Location
+	by := primitive.NewObjectID()
+	endOfMarch := time.Date(2025, 4, 1, 0, 0, 0, 0, loc)
+
+	// Funcionário cadastrado depois do mês, sem apuração
+	late := models.User{Name: "Bruno", CompanyID: user.CompanyID, Role: models.RoleEmployee, CreatedAt: endOfMarch}
+	if err := store.Users.Create(ctx, &late); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.Close(ctx, user.CompanyID, 2025, 3, by, endOfMarch.Add(-time.Minute)); !errors.Is(err, ErrNotEnded) {
+		t.Fatalf("Close() before the end of the month error = %v, want %v", err, ErrNotEnded)
+	}
+
+	closing, err := service.Close(ctx, user.CompanyID, 2025, 3, by, endOfMarch)
+	if err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if closing.Status != models.ClosingClosed || len(closing.Totals) != 1 || closing.Totals[0].UserID != user.ID {
+		t.Fatalf("closing = %+v, want closed with the totals of %s only", closing, user.Name)
+	}
+	first := closing.Totals[0]
+	if content, err := store.Files.Get(ctx, first.DocumentKey); err != nil || len(content) == 0 || first.DocumentHash == "" {
+		t.Errorf("stored espelho %q: %d bytes, error %v, hash %q", first.DocumentKey, len(content), err, first.DocumentHash)
+	}
+	if err := service.Check(ctx, user.CompanyID, endOfMarch.Add(-time.Hour), endOfMarch.Add(-time.Hour)); err == nil {
+		t.Errorf("Check() on a closed month error = nil")
+	}
+
+	if _, err := service.Close(ctx, user.CompanyID, 2025, 3, by, endOfMarch); !errors.Is(err, ErrAlreadyClosed) {
+		t.Errorf("Close() again error = %v, want %v", err, ErrAlreadyClosed)
+	}
+	if _, err := service.Reopen(ctx, user.CompanyID, 2025, 3, by, "  ", endOfMarch); !errors.Is(err, ErrReasonMissing) {
+		t.Errorf("Reopen() without reason error = %v, want %v", err, ErrReasonMissing)
+	}
+
+	reopened, err := service.Reopen(ctx, user.CompanyID, 2025, 3, by, "Atestado entregue depois do fechamento", endOfMarch.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Reopen() error = %v", err)
+	}
+	if reopened.Status != models.ClosingReopened || len(reopened.Reopenings) != 1 || !reopened.Reopenings[0].ClosedAt.Equal(endOfMarch) {
+		t.Errorf("reopened = %+v, want one reopening of the closing at %v", reopened, endOfMarch)
+	}
+	if err := service.Check(ctx, user.CompanyID, endOfMarch.Add(-time.Hour), endOfMarch.Add(-time.Hour)); err != nil {
+		t.Errorf("Check() on a reopened month error = %v", err)
+	}
+	if _, err := service.Reopen(ctx, user.CompanyID, 2025, 3, by, "De novo", endOfMarch.Add(time.Hour)); !errors.Is(err, ErrNotClosed) {
+		t.Errorf("Reopen() again error = %v, want %v", err, ErrNotClosed)
+	}
+
+	// O novo fechamento guarda outro espelho e mantém o histórico
+	closedAgain, err := service.Close(ctx, user.CompanyID, 2025, 3, by, endOfMarch.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("Close() after reopening error = %v", err)
+	}
+	if closedAgain.ID != closing.ID || len(closedAgain.Reopenings) != 1 || closedAgain.Totals[0].DocumentKey == first.DocumentKey {
+		t.Errorf("closed again = %+v, want the same closing with a new espelho", closedAgain)
+	}
+	if _, err := store.Files.Get(ctx, first.DocumentKey); err != nil {
+		t.Errorf("first espelho was not preserved: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+// Package espelho monta o espelho de ponto mensal do empregado, o documento
+// com as marcações, a jornada prevista e os totais do mês que ele confere e
+// assina.
+package espelho
+
+import (
+	"context"
+	"time"
+
+	"ponto-digital-api/internal/afd"
+	"ponto-digital-api/internal/hourbank"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/timesheet"
+)
+
+// Document reúne os dados de um espelho de ponto.
+type Document struct {
+	EmployerName string
+	CNPJ         string
+	Location     string // local de prestação de serviços
+	EmployeeName string
+	CPF          string
+	Month        time.Time // primeiro dia do mês, à meia-noite
+	Days         []timesheet.Day
+	Totals       timesheet.Totals
+	// Saldo do banco de horas ao fim do mês, ou até a véspera no mês corrente
+	HourBankMinutes int
+	GeneratedAt     time.Time
+	// Dias posteriores a Until ainda não aconteceram e ficam sem apuração;
+	// nos meses encerrados é o início do mês seguinte
+	Until    time.Time
+	Timezone *time.Location
+}
+
+// Service monta os espelhos de ponto a partir dos dias de jornada e do banco
+// de horas.
+type Service struct {
+	store  *repository.Store
+	sheets *timesheet.Service
+	bank   *hourbank.Service
+}
+
+// NewService cria um Service que usa sheets para apurar os dias de jornada.
+func NewService(store *repository.Store, sheets *timesheet.Service) *Service {
+	return &Service{store: store, sheets: sheets, bank: hourbank.NewService(store, sheets)}
+}
+
+// Build monta o espelho de ponto do usuário no mês informado, apurado até now.
+func (s *Service) Build(ctx context.Context, user *models.User, year, month int, now time.Time) (*Document, error) {
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, s.sheets.Location)
+	next := start.AddDate(0, 1, 0)
+
+	doc := &Document{
+		EmployeeName: user.Name,
+		CPF:          user.CPF,
+		Month:        start,
+		GeneratedAt:  now,
+		Until:        now,
+		Timezone:     s.sheets.Location,
+	}
+	if next.Before(now) {
+		doc.Until = next
+	}
+
+	if !user.CompanyID.IsZero() {
+		company, err := s.store.Companies.FindByID(ctx, user.CompanyID)
+		if err != nil {
+			return nil, err
+		}
+		doc.EmployerName = company.Name
+		doc.CNPJ = company.CNPJ
+	}
+	if !user.BranchID.IsZero() {
+		branch, err := s.store.Branches.FindByID(ctx, user.CompanyID, user.BranchID)
+		if err != nil {
+			return nil, err
+		}
+		doc.CNPJ = branch.CNPJ
+		doc.Location = afd.BranchLocation(branch)
+	}
+
+	days, err := s.sheets.Month(ctx, user, year, month)
+	if err != nil {
+		return nil, err
+	}
+	doc.Days = days
+	doc.Totals = timesheet.Summarize(days, doc.Until)
+
+	// O extrato apura os dias até a véspera da data informada
+	statement, err := s.bank.Statement(ctx, user, doc.Until)
+	if err != nil {
+		return nil, err
+	}
+	doc.HourBankMinutes = statement.BalanceMinutes
+	return doc, nil
+}
@@ -0,0 +1,241 @@
+package espelho
+
+import (
+	"fmt"
+	"strings"
+
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/pdf"
+	"ponto-digital-api/internal/timesheet"
+	"ponto-digital-api/internal/utils"
+)
+
+var monthNames = []string{"Janeiro", "Fevereiro", "Março", "Abril", "Maio", "Junho",
+	"Julho", "Agosto", "Setembro", "Outubro", "Novembro", "Dezembro"}
+
+var weekdayNames = []string{"Dom", "Seg", "Ter", "Qua", "Qui", "Sex", "Sáb"}
+
+var absenceNames = map[models.AbsenceKind]string{
+	models.AbsenceMedical:       "Atestado médico",
+	models.AbsenceVacation:      "Férias",
+	models.AbsenceMaternity:     "Licença-maternidade",
+	models.AbsencePaternity:     "Licença-paternidade",
+	models.AbsenceBereavement:   "Falecimento",
+	models.AbsenceMarriage:      "Casamento",
+	models.AbsenceBloodDonation: "Doação de sangue",
+	models.AbsenceOther:         "Ausência justificada",
+}
+
+// Layout da página A4 em paisagem, em pontos.
+const (
+	margin    = 30
+	rowHeight = 10
+)
+
+// column é uma coluna da tabela de dias.
+type column struct {
+	title string
+	x     float64
+	value func(d *Document, day *timesheet.Day) string
+}
+
+var columns = []column{
+	{"Data", 30, func(d *Document, day *timesheet.Day) string { return day.Time().Format("02/01") }},
+	{"Dia", 62, func(d *Document, day *timesheet.Day) string { return weekdayNames[day.Time().Weekday()] }},
+	{"Marcações", 88, func(d *Document, day *timesheet.Day) string { return d.punches(day) }},
+	{"Previsto", 360, func(d *Document, day *timesheet.Day) string {
+		if !day.Expected.Working {
+			return ""
+		}
+		return day.ExpectedEntry + "-" + day.ExpectedExit + " " + clock(day.ExpectedMinutes)
+	}},
+	{"Trabalhado", 440, func(d *Document, day *timesheet.Day) string { return d.measured(day, day.AdjustedWorkedMinutes) }},
+	{"Extras", 490, func(d *Document, day *timesheet.Day) string { return d.measured(day, day.OvertimeMinutes) }},
+	{"Noturno", 530, func(d *Document, day *timesheet.Day) string { return d.measured(day, day.NightPaidMinutes) }},
+	{"Abono", 570, func(d *Document, day *timesheet.Day) string { return d.measured(day, day.AbonoMinutes) }},
+	{"Saldo", 610, func(d *Document, day *timesheet.Day) string {
+		if !d.elapsed(day) || day.AdjustedBalanceMinutes == 0 {
+			return ""
+		}
+		return signedClock(day.AdjustedBalanceMinutes)
+	}},
+	{"Ocorrência", 655, func(d *Document, day *timesheet.Day) string { return d.occurrence(day) }},
+}
+
+// PDF apresenta o espelho em um documento PDF, com a tabela de dias, os
+// totais do mês e os campos de assinatura do empregador e do empregado.
+func (d *Document) PDF() []byte {
+	doc := pdf.New(fmt.Sprintf("Espelho de Ponto - %s - %s", d.Competence(), d.EmployeeName))
+	page := doc.AddPage(pdf.A4Height, pdf.A4Width)
+	y := d.header(page)
+
+	tableHeader := func() {
+		for _, col := range columns {
+			page.Text(col.x, y, pdf.Bold, 7.5, col.title)
+		}
+		page.Line(margin, y+3, page.Width-margin, y+3, 0.5)
+		y += rowHeight + 2
+	}
+	// need inicia uma nova página quando não há height pontos livres
+	need := func(height float64) bool {
+		if y+height <= page.Height-margin {
+			return false
+		}
+		page = doc.AddPage(pdf.A4Height, pdf.A4Width)
+		y = d.header(page)
+		return true
+	}
+
+	tableHeader()
+	for i := range d.Days {
+		if need(rowHeight) {
+			tableHeader()
+		}
+		day := &d.Days[i]
+		for _, col := range columns {
+			if value := col.value(d, day); value != "" {
+				page.Text(col.x, y, pdf.Regular, 7, value)
+			}
+		}
+		y += rowHeight
+	}
+	page.Line(margin, y-7, page.Width-margin, y-7, 0.5)
+
+	totals := d.totalLines()
+	need(float64(len(totals)*rowHeight) + 95)
+	y += 6
+	page.Text(margin, y, pdf.Bold, 8, "Totais do mês")
+	y += rowHeight + 2
+	for _, line := range totals {
+		page.Text(margin, y, pdf.Regular, 7.5, line)
+		y += rowHeight
+	}
+	page.Text(margin, y, pdf.Regular, 6.5, "* marcação incluída por ajuste aprovado. "+
+		"Trabalhado inclui o tempo abonado; noturno na hora reduzida.")
+
+	y += 20
+	page.Text(margin, y, pdf.Regular, 7.5,
+		"Reconheço a exatidão das marcações e dos totais deste espelho de ponto.")
+	y += 34
+	signatures := []struct {
+		x     float64
+		title string
+		name  string
+	}{{margin + 30, "Empregador", d.EmployerName}, {page.Width/2 + 30, "Empregado", d.EmployeeName}}
+	for _, signature := range signatures {
+		page.Line(signature.x, y, signature.x+300, y, 0.5)
+		page.Text(signature.x, y+10, pdf.Bold, 7.5, signature.title)
+		page.Text(signature.x, y+20, pdf.Regular, 7.5, signature.name)
+	}
+	page.Text(margin, page.Height-margin/2, pdf.Regular, 6,
+		"Gerado em "+d.GeneratedAt.In(d.Timezone).Format("02/01/2006 15:04"))
+	return doc.Bytes()
+}
+
+// Competence retorna o mês do espelho por extenso, como "Outubro/2026".
+func (d *Document) Competence() string {
+	return fmt.Sprintf("%s/%d", monthNames[d.Month.Month()-1], d.Month.Year())
+}
+
+// header escreve a identificação do empregador e do empregado no topo da
+// página e retorna a posição da primeira linha livre.
+func (d *Document) header(page *pdf.Page) float64 {
+	page.Text(margin, 40, pdf.Bold, 14, "Espelho de Ponto")
+	page.Text(page.Width-190, 40, pdf.Bold, 10, "Competência: "+d.Competence())
+	page.Text(margin, 58, pdf.Regular, 8.5, "Empregador: "+d.EmployerName+"    CNPJ: "+utils.FormatCNPJ(d.CNPJ))
+	if d.Location != "" {
+		page.Text(margin, 70, pdf.Regular, 8.5, "Local: "+d.Location)
+	}
+	page.Text(margin, 82, pdf.Regular, 8.5, "Empregado: "+d.EmployeeName+"    CPF: "+utils.FormatCPF(d.CPF))
+	page.Line(margin, 89, page.Width-margin, 89, 0.8)
+	return 104
+}
+
+// elapsed informa se o dia já foi apurado.
+func (d *Document) elapsed(day *timesheet.Day) bool {
+	return !day.Time().After(d.Until)
+}
+
+// measured formata os minutos apurados no dia, omitindo os zerados e os dos
+// dias que ainda não aconteceram.
+func (d *Document) measured(day *timesheet.Day, minutes int) string {
+	if !d.elapsed(day) || minutes == 0 {
+		return ""
+	}
+	return clock(minutes)
+}
+
+// punches lista os horários das marcações do dia, com "*" nas incluídas por
+// ajuste.
+func (d *Document) punches(day *timesheet.Day) string {
+	times := make([]string, 0, len(day.Records))
+	for _, record := range day.Records {
+		value := record.Timestamp.In(d.Timezone).Format("15:04")
+		if record.Origin == models.OriginInclusion {
+			value += "*"
+		}
+		times = append(times, value)
+	}
+	return strings.Join(times, "  ")
+}
+
+// occurrence descreve o feriado, a ausência, a falta ou a folga do dia.
+func (d *Document) occurrence(day *timesheet.Day) string {
+	ended := !day.Time().AddDate(0, 0, 1).After(d.Until)
+	switch {
+	case day.Holiday != "":
+		return truncate(day.Holiday, 34)
+	case day.Absence != "":
+		return absenceNames[day.Absence]
+	case !day.Expected.Working && len(day.Records) == 0:
+		return "Folga"
+	case ended && day.Expected.Working && len(day.Records) == 0 && day.AbonoMinutes == 0:
+		return "Falta"
+	}
+	return ""
+}
+
+// totalLines resume os totais do mês em linhas de texto.
+func (d *Document) totalLines() []string {
+	t := d.Totals
+	overtime := []string{}
+	for _, rate := range t.Overtime {
+		overtime = append(overtime, fmt.Sprintf("%d%%: %s", rate.Rate, clock(rate.Minutes)))
+	}
+	if len(overtime) == 0 {
+		overtime = append(overtime, clock(0))
+	}
+
+	return []string{
+		fmt.Sprintf("Previsto: %s    Trabalhado: %s    Saldo do mês: %s    Banco de horas: %s",
+			clock(t.ExpectedMinutes), clock(t.AdjustedWorkedMinutes),
+			signedClock(t.AdjustedBalanceMinutes), signedClock(d.HourBankMinutes)),
+		fmt.Sprintf("Horas extras: %s    Noturno: %s    Abonado: %s em %d dias    Faltas: %d    Dias com atraso: %d",
+			strings.Join(overtime, ", "), clock(t.NightPaidMinutes), clock(t.AbonoMinutes), t.AbonoDays,
+			t.MissedDays, t.AdjustedLateDays),
+	}
+}
+
+// clock formata minutos como "HH:MM".
+func clock(minutes int) string {
+	if minutes < 0 {
+		minutes = -minutes
+	}
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// signedClock formata minutos como "+HH:MM" ou "-HH:MM".
+func signedClock(minutes int) string {
+	if minutes < 0 {
+		return "-" + clock(minutes)
+	}
+	return "+" + clock(minutes)
+}
+
+func truncate(text string, size int) string {
+	runes := []rune(text)
+	if len(runes) <= size {
+		return text
+	}
+	return string(runes[:size-3]) + "..."
+}
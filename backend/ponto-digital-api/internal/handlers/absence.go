@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/closing"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/timesheet"
+)
+
+// AbsenceHandler trata as ausências justificadas. O tempo previsto nos dias
+// das ausências aprovadas é abonado e conta como trabalhado nos relatórios.
+// Ausências em competências fechadas não podem ser registradas nem aprovadas.
+type AbsenceHandler struct {
+	store    *repository.Store
+	sheets   *timesheet.Service
+	closings *closing.Service
+}
+
+func NewAbsenceHandler(store *repository.Store) *AbsenceHandler {
+	sheets := timesheet.NewService(store)
+	return &AbsenceHandler{store: store, sheets: sheets, closings: closing.NewService(store, sheets)}
+}
+
+// AbsenceRequest descreve uma ausência. endDate é opcional para ausências de
+// um dia; startTime e endTime definem um abono parcial de um único dia.
+type AbsenceRequest struct {
+	Kind         string `json:"kind" binding:"required,oneof=atestado ferias licenca_maternidade licenca_paternidade falecimento casamento doacao_sangue outro"`
+	StartDate    string `json:"startDate" binding:"required"` // AAAA-MM-DD
+	EndDate      string `json:"endDate"`
+	StartTime    string `json:"startTime"` // HH:MM
+	EndTime      string `json:"endTime"`
+	Reason       string `json:"reason" binding:"required"`
+	AttachmentID string `json:"attachmentId"`
+}
+
+type AbsenceReviewRequest struct {
+	Note string `json:"note"`
+}
+
+// CreateAbsence registra uma justificativa de ausência do usuário
+// autenticado, que fica pendente até a análise do gestor.
+func (h *AbsenceHandler) CreateAbsence(c *gin.Context) {
+	var req AbsenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := authenticatedUser(c, h.store)
+	if !ok {
+		return
+	}
+
+	h.createAbsence(c, req, user, user, models.AbsencePending)
+}
+
+// CreateMemberAbsence registra uma ausência já aprovada para um membro da
+// equipe, como férias ou licenças. Gestores não podem registrar ausências
+// próprias.
+func (h *AbsenceHandler) CreateMemberAbsence(c *gin.Context) {
+	var req AbsenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, ok := teamMember(c, h.store)
+	if !ok {
+		return
+	}
+	author, ok := authenticatedUser(c, h.store)
+	if !ok {
+		return
+	}
+	if member.ID == author.ID && author.Role != models.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Não é possível registrar a própria ausência"})
+		return
+	}
+
+	h.createAbsence(c, req, member, author, models.AbsenceApproved)
+}
+
+func (h *AbsenceHandler) createAbsence(c *gin.Context, req AbsenceRequest, member, author *models.User, status models.AbsenceStatus) {
+	now := time.Now()
+	absence := models.Absence{
+		CompanyID: member.CompanyID,
+		UserID:    member.ID,
+		Kind:      models.AbsenceKind(req.Kind),
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Reason:    req.Reason,
+		Status:    status,
+		CreatedBy: author.ID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if status == models.AbsenceApproved {
+		absence.ReviewerID = author.ID
+		absence.ReviewedAt = now
+	}
+
+	var err error
+	if absence.StartDate, absence.EndDate, err = h.parsePeriod(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !checkOpen(c, h.closings, member.CompanyID, absence.StartDate, absence.EndDate) {
+		return
+	}
+
+	var ok bool
+	if absence.AttachmentID, ok = ownedAttachment(c, h.store, req.AttachmentID, member, author); !ok {
+		return
+	}
+
+	// Ausências pendentes ou aprovadas não podem se sobrepor
+	existing, err := h.store.Absences.List(c.Request.Context(), repository.AbsenceFilter{
+		CompanyID: member.CompanyID,
+		UserIDs:   []primitive.ObjectID{member.ID},
+		Statuses:  []models.AbsenceStatus{models.AbsencePending, models.AbsenceApproved},
+		From:      absence.StartDate,
+		To:        absence.EndDate,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar ausências"})
+		return
+	}
+	if len(existing) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Já existe uma ausência registrada no período"})
+		return
+	}
+
+	if err := h.store.Absences.Create(c.Request.Context(), &absence); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao registrar ausência"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, absence)
+}
+
+// parsePeriod valida as datas e os horários da ausência.
+func (h *AbsenceHandler) parsePeriod(req AbsenceRequest) (start, end time.Time, err error) {
+	start, err = time.ParseInLocation(timesheet.DateLayout, req.StartDate, h.sheets.Location)
+	if err != nil {
+		return start, end, errors.New("Data inicial inválida")
+	}
+	end = start
+	if req.EndDate != "" {
+		end, err = time.ParseInLocation(timesheet.DateLayout, req.EndDate, h.sheets.Location)
+		if err != nil || end.Before(start) {
+			return start, end, errors.New("Data final inválida")
+		}
+	}
+	if end.Sub(start) > 366*24*time.Hour {
+		return start, end, errors.New("A ausência deve ter no máximo um ano")
+	}
+
+	if req.StartTime == "" && req.EndTime == "" {
+		return start, end, nil
+	}
+	if !end.Equal(start) {
+		return start, end, errors.New("O abono parcial vale para um único dia")
+	}
+	from, err := timesheet.ParseClock(req.StartTime)
+	if err != nil {
+		return start, end, errors.New("Horário inicial inválido")
+	}
+	to, err := timesheet.ParseClock(req.EndTime)
+	if err != nil || to == from {
+		return start, end, errors.New("Horário final inválido")
+	}
+	return start, end, nil
+}
+
+// ListMyAbsences lista as ausências do usuário autenticado.
+func (h *AbsenceHandler) ListMyAbsences(c *gin.Context) {
+	user, ok := authenticatedUser(c, h.store)
+	if !ok {
+		return
+	}
+
+	statuses, ok := absenceStatusQuery(c)
+	if !ok {
+		return
+	}
+
+	h.respondAbsences(c, repository.AbsenceFilter{
+		CompanyID: user.CompanyID,
+		UserIDs:   []primitive.ObjectID{user.ID},
+		Statuses:  statuses,
+	})
+}
+
+// ListTeamAbsences lista as ausências da equipe do gestor autenticado, ou de
+// toda a empresa para administradores. Aceita o filtro opcional
+// ?status=pendente|aprovada|rejeitada.
+func (h *AbsenceHandler) ListTeamAbsences(c *gin.Context) {
+	statuses, ok := absenceStatusQuery(c)
+	if !ok {
+		return
+	}
+
+	userIDs, ok := teamUserIDs(c, h.store)
+	if !ok {
+		return
+	}
+
+	h.respondAbsences(c, repository.AbsenceFilter{
+		CompanyID: currentCompanyID(c),
+		UserIDs:   userIDs,
+		Statuses:  statuses,
+	})
+}
+
+// ApproveAbsence aprova uma ausência pendente, abonando seus dias.
+func (h *AbsenceHandler) ApproveAbsence(c *gin.Context) {
+	h.review(c, models.AbsenceApproved)
+}
+
+// RejectAbsence rejeita uma ausência pendente.
+func (h *AbsenceHandler) RejectAbsence(c *gin.Context) {
+	h.review(c, models.AbsenceRejected)
+}
+
+func (h *AbsenceHandler) review(c *gin.Context, status models.AbsenceStatus) {
+	// A observação é opcional e o corpo pode ser omitido
+	var req AbsenceReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ausência inválida"})
+		return
+	}
+
+	absence, err := h.store.Absences.FindByID(c.Request.Context(), currentCompanyID(c), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ausência não encontrada"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar ausência"})
+		return
+	}
+
+	member, err := h.store.Users.FindByID(c.Request.Context(), absence.CompanyID, absence.UserID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar usuário"})
+		return
+	}
+	if member == nil || !canAccessUser(c, member) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Acesso negado"})
+		return
+	}
+
+	reviewerID, _ := c.Get("user_id")
+	role, _ := c.Get("role")
+	if member.ID == reviewerID && role != models.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Não é possível analisar a própria ausência"})
+		return
+	}
+	if absence.Status != models.AbsencePending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Ausência já analisada"})
+		return
+	}
+	// A competência pode ter sido fechada depois do pedido
+	if status == models.AbsenceApproved && !checkOpen(c, h.closings, absence.CompanyID, absence.StartDate, absence.EndDate) {
+		return
+	}
+
+	now := time.Now()
+	absence.Status = status
+	absence.ReviewerID = reviewerID.(primitive.ObjectID)
+	absence.ReviewNote = req.Note
+	absence.ReviewedAt = now
+	absence.UpdatedAt = now
+	if err := h.store.Absences.Update(c.Request.Context(), absence); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar ausência"})
+		return
+	}
+
+	c.JSON(http.StatusOK, absence)
+}
+
+func (h *AbsenceHandler) respondAbsences(c *gin.Context, filter repository.AbsenceFilter) {
+	absences, err := h.store.Absences.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar ausências"})
+		return
+	}
+
+	c.JSON(http.StatusOK, absences)
+}
+
+// absenceStatusQuery lê o filtro opcional ?status. Em caso de valor inválido
+// a resposta de erro já é enviada e ok é falso.
+func absenceStatusQuery(c *gin.Context) ([]models.AbsenceStatus, bool) {
+	status := models.AbsenceStatus(c.Query("status"))
+	switch status {
+	case "":
+		return nil, true
+	case models.AbsencePending, models.AbsenceApproved, models.AbsenceRejected:
+		return []models.AbsenceStatus{status}, true
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Status inválido: use pendente, aprovada ou rejeitada"})
+	return nil, false
+}
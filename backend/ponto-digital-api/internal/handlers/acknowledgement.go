@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+)
+
+// AcknowledgementHandler trata a assinatura eletrônica dos espelhos de ponto
+// das competências fechadas: o funcionário confere o espelho guardado no
+// fechamento e declara ciência ou o contesta.
+type AcknowledgementHandler struct {
+	store *repository.Store
+}
+
+func NewAcknowledgementHandler(store *repository.Store) *AcknowledgementHandler {
+	return &AcknowledgementHandler{store: store}
+}
+
+// AcknowledgementRequest é a resposta do funcionário ao espelho. documentHash
+// é o hash do espelho conferido, que precisa ser o da versão atual.
+type AcknowledgementRequest struct {
+	Status       string `json:"status" binding:"required,oneof=ciente contestado"`
+	Comment      string `json:"comment"`
+	DocumentHash string `json:"documentHash" binding:"required"`
+	Device       string `json:"device"`
+}
+
+// AcknowledgementEntry é a situação da assinatura do espelho de um
+// funcionário em uma competência fechada.
+type AcknowledgementEntry struct {
+	UserID         primitive.ObjectID           `json:"user_id"`
+	Name           string                       `json:"name"`
+	Competence     string                       `json:"competence"` // AAAA-MM
+	ClosedAt       time.Time                    `json:"closed_at"`
+	DocumentHash   string                       `json:"document_hash"`
+	Status         models.AcknowledgementStatus `json:"status"`
+	Comment        string                       `json:"comment,omitempty"`
+	AcknowledgedAt *time.Time                   `json:"acknowledged_at,omitempty"`
+	Totals         *models.ClosingTotals        `json:"totals,omitempty"`
+}
+
+// ListMyClosings lista as competências fechadas do usuário autenticado com a
+// situação da assinatura de cada espelho.
+func (h *AcknowledgementHandler) ListMyClosings(c *gin.Context) {
+	user, ok := authenticatedUser(c, h.store)
+	if !ok {
+		return
+	}
+
+	entries, err := h.entries(c.Request.Context(), user.CompanyID, []primitive.ObjectID{user.ID}, 0, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar competências"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// GetMyClosing retorna os totais do usuário autenticado na competência
+// fechada :competence (AAAA-MM) e a situação da assinatura do espelho.
+func (h *AcknowledgementHandler) GetMyClosing(c *gin.Context) {
+	user, ok := authenticatedUser(c, h.store)
+	if !ok {
+		return
+	}
+
+	closing, totals, ok := h.closedTotals(c, user)
+	if !ok {
+		return
+	}
+
+	entries, err := h.entries(c.Request.Context(), user.CompanyID, []primitive.ObjectID{user.ID}, closing.Year, closing.Month)
+	if err != nil || len(entries) == 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar assinatura"})
+		return
+	}
+
+	entry := entries[0]
+	entry.Totals = totals
+	c.JSON(http.StatusOK, entry)
+}
+
+// GetMyClosingDocument retorna o espelho de ponto em PDF guardado no
+// fechamento da competência :competence, o documento a ser assinado.
+func (h *AcknowledgementHandler) GetMyClosingDocument(c *gin.Context) {
+	user, ok := authenticatedUser(c, h.store)
+	if !ok {
+		return
+	}
+
+	closing, totals, ok := h.closedTotals(c, user)
+	if !ok {
+		return
+	}
+
+	content, err := h.store.Files.Get(c.Request.Context(), totals.DocumentKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao carregar espelho"})
+		return
+	}
+
+	name := fmt.Sprintf("espelho-%04d-%02d-%s.pdf", closing.Year, closing.Month, user.ID.Hex())
+	c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+	c.Data(http.StatusOK, "application/pdf", content)
+}
+
+// AcknowledgeClosing registra a ciência ou a contestação do usuário
+// autenticado sobre o espelho da competência fechada :competence, com a data,
+// o IP, o dispositivo e o hash do documento. A contestação exige comentário.
+func (h *AcknowledgementHandler) AcknowledgeClosing(c *gin.Context) {
+	var req AcknowledgementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Comment = strings.TrimSpace(req.Comment)
+	status := models.AcknowledgementStatus(req.Status)
+	if status == models.AcknowledgementDisputed && req.Comment == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Informe o motivo da contestação"})
+		return
+	}
+
+	user, ok := authenticatedUser(c, h.store)
+	if !ok {
+		return
+	}
+
+	closing, totals, ok := h.closedTotals(c, user)
+	if !ok {
+		return
+	}
+	if !strings.EqualFold(req.DocumentHash, totals.DocumentHash) {
+		c.JSON(http.StatusConflict, gin.H{"error": "O espelho conferido não é a versão atual da competência"})
+		return
+	}
+
+	existing, err := h.store.Acknowledgements.List(c.Request.Context(), repository.AcknowledgementFilter{
+		CompanyID: user.CompanyID,
+		UserIDs:   []primitive.ObjectID{user.ID},
+		Year:      closing.Year,
+		Month:     closing.Month,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar assinatura"})
+		return
+	}
+	for _, previous := range existing {
+		if previous.DocumentKey == totals.DocumentKey {
+			c.JSON(http.StatusConflict, gin.H{"error": "Espelho já assinado"})
+			return
+		}
+	}
+
+	acknowledgement := models.Acknowledgement{
+		CompanyID:    user.CompanyID,
+		UserID:       user.ID,
+		Year:         closing.Year,
+		Month:        closing.Month,
+		Status:       status,
+		Comment:      req.Comment,
+		DocumentKey:  totals.DocumentKey,
+		DocumentHash: totals.DocumentHash,
+		IP:           c.ClientIP(),
+		Device:       req.Device,
+		UserAgent:    c.Request.UserAgent(),
+		CreatedAt:    time.Now(),
+	}
+	if err := h.store.Acknowledgements.Create(c.Request.Context(), &acknowledgement); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao registrar assinatura"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, acknowledgement)
+}
+
+// ListTeamAcknowledgements lista a situação das assinaturas dos espelhos da
+// equipe do gestor autenticado, ou de toda a empresa para administradores.
+// Aceita os filtros opcionais ?competence=AAAA-MM e
+// ?status=pendente|ciente|contestado.
+func (h *AcknowledgementHandler) ListTeamAcknowledgements(c *gin.Context) {
+	var year, month int
+	if value := c.Query("competence"); value != "" {
+		t, err := time.Parse("2006-01", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Competência inválida: use o formato AAAA-MM"})
+			return
+		}
+		year, month = t.Year(), int(t.Month())
+	}
+
+	status := models.AcknowledgementStatus(c.Query("status"))
+	switch status {
+	case "", models.AcknowledgementPending, models.AcknowledgementAgreed, models.AcknowledgementDisputed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Status inválido: use pendente, ciente ou contestado"})
+		return
+	}
+
+	userIDs, ok := teamUserIDs(c, h.store)
+	if !ok {
+		return
+	}
+
+	entries, err := h.entries(c.Request.Context(), currentCompanyID(c), userIDs, year, month)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar assinaturas"})
+		return
+	}
+
+	filtered := []AcknowledgementEntry{}
+	for _, entry := range entries {
+		if status == "" || entry.Status == status {
+			filtered = append(filtered, entry)
+		}
+	}
+	c.JSON(http.StatusOK, filtered)
+}
+
+// entries monta a situação das assinaturas dos usuários userIDs, ou de todos
+// quando nil, nas competências fechadas da empresa. year e month, quando
+// diferentes de zero, restringem a competência. Apenas a assinatura do espelho
+// do fechamento vigente conta; reaberturas exigem nova assinatura.
+func (h *AcknowledgementHandler) entries(ctx context.Context, companyID primitive.ObjectID, userIDs []primitive.ObjectID, year, month int) ([]AcknowledgementEntry, error) {
+	closings, err := h.store.Closings.List(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	acknowledgements, err := h.store.Acknowledgements.List(ctx, repository.AcknowledgementFilter{
+		CompanyID: companyID,
+		UserIDs:   userIDs,
+		Year:      year,
+		Month:     month,
+	})
+	if err != nil {
+		return nil, err
+	}
+	byDocument := make(map[string]*models.Acknowledgement, len(acknowledgements))
+	for i := range acknowledgements {
+		byDocument[acknowledgements[i].DocumentKey] = &acknowledgements[i]
+	}
+
+	entries := []AcknowledgementEntry{}
+	for _, closing := range closings {
+		if closing.Status != models.ClosingClosed {
+			continue
+		}
+		if year != 0 && (closing.Year != year || closing.Month != month) {
+			continue
+		}
+		for _, totals := range closing.Totals {
+			if userIDs != nil && !containsID(userIDs, totals.UserID) {
+				continue
+			}
+			entry := AcknowledgementEntry{
+				UserID:       totals.UserID,
+				Name:         totals.Name,
+				Competence:   fmt.Sprintf("%04d-%02d", closing.Year, closing.Month),
+				ClosedAt:     closing.ClosedAt,
+				DocumentHash: totals.DocumentHash,
+				Status:       models.AcknowledgementPending,
+			}
+			if acknowledgement, ok := byDocument[totals.DocumentKey]; ok {
+				entry.Status = acknowledgement.Status
+				entry.Comment = acknowledgement.Comment
+				entry.AcknowledgedAt = &acknowledgement.CreatedAt
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// closedTotals carrega a competência fechada do parâmetro :competence e os
+// totais do usuário nela. Em caso de falha a resposta de erro já é enviada e
+// ok é falso.
+func (h *AcknowledgementHandler) closedTotals(c *gin.Context, user *models.User) (*models.PeriodClosing, *models.ClosingTotals, bool) {
+	year, month, ok := parseCompetence(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	closing, err := h.store.Closings.FindByMonth(c.Request.Context(), user.CompanyID, year, month)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Competência não fechada"})
+		return nil, nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar fechamento"})
+		return nil, nil, false
+	}
+	if closing.Status != models.ClosingClosed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Competência reaberta: aguarde o novo fechamento"})
+		return nil, nil, false
+	}
+
+	for i := range closing.Totals {
+		if closing.Totals[i].UserID == user.ID {
+			return closing, &closing.Totals[i], true
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "Espelho não encontrado na competência"})
+	return nil, nil, false
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+)
+
+// MaxAttachmentSize é o tamanho máximo de um anexo enviado.
+const MaxAttachmentSize = 5 << 20
+
+// attachmentTypes são os formatos aceitos, identificados pelo conteúdo do
+// arquivo e não pela extensão informada pelo cliente.
+var attachmentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+}
+
+type AttachmentHandler struct {
+	store *repository.Store
+}
+
+func NewAttachmentHandler(store *repository.Store) *AttachmentHandler {
+	return &AttachmentHandler{store: store}
+}
+
+// UploadAttachment recebe um arquivo (campo "file" de um formulário
+// multipart) do usuário autenticado, como um atestado que justifica um
+// ajuste de ponto.
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	user, ok := authenticatedUser(c, h.store)
+	if !ok {
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Arquivo não informado"})
+		return
+	}
+	if header.Size > MaxAttachmentSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Arquivo maior que 5 MB"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao ler arquivo"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, MaxAttachmentSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao ler arquivo"})
+		return
+	}
+	if len(data) > MaxAttachmentSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Arquivo maior que 5 MB"})
+		return
+	}
+
+	contentType := http.DetectContentType(data)
+	if !attachmentTypes[contentType] {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Formato não aceito: envie PDF, JPEG ou PNG"})
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	attachment := models.Attachment{
+		ID:          primitive.NewObjectID(),
+		CompanyID:   user.CompanyID,
+		UserID:      user.ID,
+		FileName:    filepath.Base(header.Filename),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		SHA256:      hex.EncodeToString(sum[:]),
+		CreatedAt:   time.Now(),
+	}
+	attachment.Key = attachment.CompanyID.Hex() + "/" + attachment.ID.Hex()
+
+	if err := h.store.Files.Put(c.Request.Context(), attachment.Key, data); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gravar arquivo"})
+		return
+	}
+	if err := h.store.Attachments.Create(c.Request.Context(), &attachment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao registrar anexo"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// GetAttachment devolve o conteúdo de um anexo ao seu autor, ao gestor dele
+// ou a um administrador.
+func (h *AttachmentHandler) GetAttachment(c *gin.Context) {
+	attachment, ok := h.accessibleAttachment(c)
+	if !ok {
+		return
+	}
+
+	data, err := h.store.Files.Get(c.Request.Context(), attachment.Key)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Arquivo não encontrado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao ler arquivo"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", attachment.FileName))
+	c.Data(http.StatusOK, attachment.ContentType, data)
+}
+
+// ownedAttachment valida o anexo informado em uma solicitação, que deve ter
+// sido enviado por um dos owners. Um identificador vazio resulta no ObjectID
+// zero. Em caso de falha a resposta de erro já é enviada e ok é falso.
+func ownedAttachment(c *gin.Context, store *repository.Store, hexID string, owners ...*models.User) (primitive.ObjectID, bool) {
+	if hexID == "" {
+		return primitive.NilObjectID, true
+	}
+	id, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Anexo inválido"})
+		return primitive.NilObjectID, false
+	}
+
+	attachment, err := store.Attachments.FindByID(c.Request.Context(), currentCompanyID(c), id)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar anexo"})
+		return primitive.NilObjectID, false
+	}
+	if attachment != nil {
+		for _, owner := range owners {
+			if attachment.UserID == owner.ID {
+				return attachment.ID, true
+			}
+		}
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Anexo não encontrado"})
+	return primitive.NilObjectID, false
+}
+
+// accessibleAttachment carrega o anexo do parâmetro :id e verifica se o
+// usuário autenticado pode acessá-lo. Em caso de falha a resposta de erro já
+// é enviada e ok é falso.
+func (h *AttachmentHandler) accessibleAttachment(c *gin.Context) (*models.Attachment, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Anexo inválido"})
+		return nil, false
+	}
+
+	attachment, err := h.store.Attachments.FindByID(c.Request.Context(), currentCompanyID(c), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Anexo não encontrado"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar anexo"})
+		return nil, false
+	}
+
+	owner, err := h.store.Users.FindByID(c.Request.Context(), attachment.CompanyID, attachment.UserID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar usuário"})
+		return nil, false
+	}
+	if owner == nil || !canAccessUser(c, owner) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Acesso negado"})
+		return nil, false
+	}
+
+	return attachment, true
+}
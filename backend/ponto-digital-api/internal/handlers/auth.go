@@ -1,31 +1,46 @@
 package handlers
 
 import (
-    "context"
+    "errors"
+    "log"
     "net/http"
+    "strings"
     "time"
 
     "golang.org/x/crypto/bcrypt"
     "github.com/gin-gonic/gin"
-    "go.mongodb.org/mongo-driver/bson"
-    "go.mongodb.org/mongo-driver/mongo"
     "go.mongodb.org/mongo-driver/bson/primitive"
+    "ponto-digital-api/internal/afd"
     "ponto-digital-api/internal/models"
+    "ponto-digital-api/internal/repository"
+    "ponto-digital-api/internal/session"
     "ponto-digital-api/internal/utils"
 )
 
 type AuthHandler struct {
-    db *mongo.Database
+    store    *repository.Store
+    sessions *session.Service
+    rep      *afd.Registry
 }
 
-func NewAuthHandler(db *mongo.Database) *AuthHandler {
-    return &AuthHandler{db: db}
+func NewAuthHandler(store *repository.Store) *AuthHandler {
+    return &AuthHandler{store: store, sessions: session.NewService(store), rep: afd.NewRegistry(store)}
 }
 
 type RegisterRequest struct {
     Name     string `json:"name" binding:"required"`
     Email    string `json:"email" binding:"required,email"`
     Password string `json:"password" binding:"required,min=6"`
+    // Company cadastra uma nova empresa tendo o usuário como administrador.
+    // Os funcionários são cadastrados pelo administrador da empresa, e não
+    // pelo registro público, para não caírem no tenant nulo dos usuários
+    // legados
+    Company  *CompanyRequest `json:"company" binding:"required"`
+}
+
+type CompanyRequest struct {
+    Name string `json:"name" binding:"required"`
+    CNPJ string `json:"cnpj" binding:"required"`
 }
 
 type LoginRequest struct {
@@ -33,6 +48,10 @@ type LoginRequest struct {
     Password string `json:"password" binding:"required"`
 }
 
+type RefreshRequest struct {
+    RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
 func (h *AuthHandler) Register(c *gin.Context) {
     var req RegisterRequest
     if err := c.ShouldBindJSON(&req); err != nil {
@@ -41,12 +60,15 @@ func (h *AuthHandler) Register(c *gin.Context) {
     }
 
     // Verificar se o email já existe
-    var existingUser models.User
-    err := h.db.Collection("users").FindOne(context.Background(), bson.M{"email": req.Email}).Decode(&existingUser)
+    _, err := h.store.Users.FindByEmail(c.Request.Context(), req.Email)
     if err == nil {
         c.JSON(http.StatusConflict, gin.H{"error": "Email já cadastrado"})
         return
     }
+    if !errors.Is(err, repository.ErrNotFound) {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao verificar email"})
+        return
+    }
 
     // Hash da senha
     hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
@@ -55,36 +77,66 @@ func (h *AuthHandler) Register(c *gin.Context) {
         return
     }
 
-    // Criar novo usuário
+    // Cadastrar a empresa, tendo o novo usuário como administrador
+    company, status, err := h.createCompany(c, req.Company)
+    if err != nil {
+        c.JSON(status, gin.H{"error": err.Error()})
+        return
+    }
+
     user := models.User{
         Name:      req.Name,
         Email:     req.Email,
         Password:  string(hashedPassword),
+        Role:      models.RoleAdmin,
+        CompanyID: company.ID,
         CreatedAt: time.Now(),
         UpdatedAt: time.Now(),
     }
 
-    result, err := h.db.Collection("users").InsertOne(context.Background(), user)
-    if err != nil {
+    if err := h.store.Users.Create(c.Request.Context(), &user); err != nil {
+        // Sem o administrador a empresa ficaria inacessível e o CNPJ
+        // bloqueado para um novo cadastro
+        if err := h.store.Companies.Delete(c.Request.Context(), company.ID); err != nil {
+            log.Printf("Erro ao remover a empresa %s após falha no cadastro do usuário: %v", company.ID.Hex(), err)
+        }
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar usuário"})
         return
     }
 
-    // Gerar token
-    token, err := utils.GenerateToken(result.InsertedID.(primitive.ObjectID), user.Email)
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar token"})
-        return
+    // Inclusão do empregador no REP da sede
+    logRepEvent(h.rep.Employer(c.Request.Context(), company, nil, models.RepEventInclusion, user.ID, company.CreatedAt))
+
+    h.startSession(c, http.StatusCreated, &user)
+}
+
+// createCompany valida e cadastra a empresa informada no registro. Em caso de
+// erro, retorna também o status HTTP adequado.
+func (h *AuthHandler) createCompany(c *gin.Context, req *CompanyRequest) (*models.Company, int, error) {
+    if !utils.ValidCNPJ(req.CNPJ) {
+        return nil, http.StatusBadRequest, errors.New("CNPJ inválido")
     }
 
-    c.JSON(http.StatusCreated, gin.H{
-        "token": token,
-        "user": gin.H{
-            "id":    result.InsertedID,
-            "name":  user.Name,
-            "email": user.Email,
-        },
-    })
+    cnpj := utils.NormalizeCNPJ(req.CNPJ)
+    _, err := h.store.Companies.FindByCNPJ(c.Request.Context(), cnpj)
+    if err == nil {
+        return nil, http.StatusConflict, errors.New("CNPJ já cadastrado")
+    }
+    if !errors.Is(err, repository.ErrNotFound) {
+        return nil, http.StatusInternalServerError, errors.New("Erro ao verificar CNPJ")
+    }
+
+    company := models.Company{
+        Name:      req.Name,
+        CNPJ:      cnpj,
+        CreatedAt: time.Now(),
+        UpdatedAt: time.Now(),
+    }
+    if err := h.store.Companies.Create(c.Request.Context(), &company); err != nil {
+        return nil, http.StatusInternalServerError, errors.New("Erro ao cadastrar empresa")
+    }
+
+    return &company, 0, nil
 }
 
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -95,8 +147,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
     }
 
     // Buscar usuário
-    var user models.User
-    err := h.db.Collection("users").FindOne(context.Background(), bson.M{"email": req.Email}).Decode(&user)
+    user, err := h.store.Users.FindByEmail(c.Request.Context(), req.Email)
     if err != nil {
         c.JSON(http.StatusUnauthorized, gin.H{"error": "Credenciais inválidas"})
         return
@@ -109,23 +160,80 @@ func (h *AuthHandler) Login(c *gin.Context) {
         return
     }
 
-    // Gerar token
-    token, err := utils.GenerateToken(user.ID, user.Email)
+    h.startSession(c, http.StatusOK, user)
+}
+
+// startSession abre uma sessão de login para o usuário neste aparelho e
+// responde com o token de acesso e o refresh token
+func (h *AuthHandler) startSession(c *gin.Context, status int, user *models.User) {
+    tokens, err := h.sessions.Start(c.Request.Context(), user, c.ClientIP(), c.Request.UserAgent(), time.Now())
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar token"})
         return
     }
 
+    c.JSON(status, gin.H{
+        "token":        tokens.AccessToken,
+        "refreshToken": tokens.RefreshToken,
+        "expiresIn":    tokens.ExpiresIn,
+        "user":         userResponse(user),
+    })
+}
+
+// Refresh troca o refresh token da sessão por um novo par de tokens. Cada
+// refresh token vale uma única vez
+func (h *AuthHandler) Refresh(c *gin.Context) {
+    var req RefreshRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    tokens, err := h.sessions.Refresh(c.Request.Context(), req.RefreshToken, c.ClientIP(), time.Now())
+    if errors.Is(err, session.ErrInvalid) {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao renovar sessão"})
+        return
+    }
+
     c.JSON(http.StatusOK, gin.H{
-        "token": token,
-        "user": gin.H{
-            "id":    user.ID,
-            "name":  user.Name,
-            "email": user.Email,
-        },
+        "token":        tokens.AccessToken,
+        "refreshToken": tokens.RefreshToken,
+        "expiresIn":    tokens.ExpiresIn,
     })
 }
 
+// Logout encerra a sessão do token usado na requisição
+func (h *AuthHandler) Logout(c *gin.Context) {
+    if err := h.sessions.Revoke(c.Request.Context(), currentSessionID(c), time.Now()); err != nil {
+        log.Printf("Erro ao encerrar sessão: %v", err)
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao encerrar sessão"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Sessão encerrada"})
+}
+
+// LogoutAll encerra as sessões do usuário autenticado em todos os aparelhos,
+// inclusive a atual
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+    user, ok := authenticatedUser(c, h.store)
+    if !ok {
+        return
+    }
+
+    if err := h.sessions.RevokeAll(c.Request.Context(), user, primitive.NilObjectID, time.Now()); err != nil {
+        log.Printf("Erro ao encerrar sessões do usuário %s: %v", user.ID.Hex(), err)
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao encerrar sessões"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Sessões encerradas em todos os aparelhos"})
+}
+
 func (h *AuthHandler) AuthMiddleware() gin.HandlerFunc {
     return func(c *gin.Context) {
         authHeader := c.GetHeader("Authorization")
@@ -135,7 +243,11 @@ func (h *AuthHandler) AuthMiddleware() gin.HandlerFunc {
         }
 
         // Remover "Bearer " do token
-        tokenString := authHeader[7:]
+        tokenString, found := strings.CutPrefix(authHeader, "Bearer ")
+        if !found || tokenString == "" {
+            c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token inválido"})
+            return
+        }
 
         claims, err := utils.ValidateToken(tokenString)
         if err != nil {
@@ -143,10 +255,173 @@ func (h *AuthHandler) AuthMiddleware() gin.HandlerFunc {
             return
         }
 
+        // O token vale apenas enquanto a sessão de login estiver ativa
+        err = h.sessions.Check(c.Request.Context(), claims.SessionID, time.Now())
+        if errors.Is(err, session.ErrInvalid) {
+            c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+            return
+        }
+        if err != nil {
+            c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Erro ao verificar sessão"})
+            return
+        }
+
         // Adicionar informações do usuário ao contexto
+        role := claims.Role
+        if role == "" {
+            role = models.RoleEmployee
+        }
+
         c.Set("user_id", claims.UserID)
         c.Set("email", claims.Email)
+        c.Set("role", role)
+        c.Set("company_id", claims.CompanyID)
+        c.Set("session_id", claims.SessionID)
 
         c.Next()
     }
-}
\ No newline at end of file
+}
+
+// RequireRole permite o acesso apenas a usuários com um dos perfis informados.
+// Deve ser usado depois de AuthMiddleware.
+func (h *AuthHandler) RequireRole(roles ...models.Role) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        role, _ := c.Get("role")
+        for _, allowed := range roles {
+            if role == allowed {
+                c.Next()
+                return
+            }
+        }
+
+        c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Acesso negado"})
+    }
+}
+
+// currentCompanyID retorna a empresa (tenant) do usuário autenticado
+func currentCompanyID(c *gin.Context) primitive.ObjectID {
+    companyID, _ := c.Get("company_id")
+    id, _ := companyID.(primitive.ObjectID)
+    return id
+}
+
+// currentUserID retorna o usuário autenticado
+func currentUserID(c *gin.Context) primitive.ObjectID {
+    userID, _ := c.Get("user_id")
+    id, _ := userID.(primitive.ObjectID)
+    return id
+}
+
+// logRepEvent registra no log a falha ao gravar um evento de cadastro do REP
+// (afd.Registry). O cadastro já foi gravado e não é desfeito; apenas o
+// registro correspondente fica fora do AFD
+func logRepEvent(err error) {
+    if err != nil {
+        log.Printf("Erro ao registrar evento de cadastro do REP: %v", err)
+    }
+}
+
+// currentSessionID retorna a sessão de login do token usado na requisição
+func currentSessionID(c *gin.Context) primitive.ObjectID {
+    sessionID, _ := c.Get("session_id")
+    id, _ := sessionID.(primitive.ObjectID)
+    return id
+}
+
+// canAccessUser informa se o usuário autenticado pode consultar os dados de
+// target: o próprio usuário, o gestor da sua equipe ou um administrador da
+// mesma empresa.
+func canAccessUser(c *gin.Context, target *models.User) bool {
+    userID, _ := c.Get("user_id")
+    role, _ := c.Get("role")
+
+    if target.CompanyID != currentCompanyID(c) {
+        return false
+    }
+
+    switch {
+    case role == models.RoleAdmin:
+        return true
+    case target.ID == userID:
+        return true
+    case role == models.RoleManager:
+        return target.ManagerID == userID
+    }
+    return false
+}
+
+// authenticatedUser carrega o usuário autenticado. Em caso de falha a resposta
+// de erro já é enviada e ok é falso.
+func authenticatedUser(c *gin.Context, store *repository.Store) (*models.User, bool) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+        return nil, false
+    }
+
+    user, err := store.Users.FindByID(c.Request.Context(), currentCompanyID(c), userID.(primitive.ObjectID))
+    if errors.Is(err, repository.ErrNotFound) {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não encontrado"})
+        return nil, false
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar usuário"})
+        return nil, false
+    }
+
+    return user, true
+}
+
+// teamUserIDs retorna os usuários da equipe do gestor autenticado, ou nil
+// para administradores, que acessam toda a empresa. Em caso de falha a
+// resposta de erro já é enviada e ok é falso.
+func teamUserIDs(c *gin.Context, store *repository.Store) ([]primitive.ObjectID, bool) {
+    role, _ := c.Get("role")
+    if role == models.RoleAdmin {
+        return nil, true
+    }
+
+    userID, _ := c.Get("user_id")
+    team, err := store.Users.List(c.Request.Context(), repository.UserFilter{
+        CompanyID: currentCompanyID(c),
+        ManagerID: userID.(primitive.ObjectID),
+    })
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar equipe"})
+        return nil, false
+    }
+
+    ids := make([]primitive.ObjectID, 0, len(team))
+    for _, member := range team {
+        ids = append(ids, member.ID)
+    }
+    return ids, true
+}
+
+// teamMember carrega o usuário do parâmetro :id e verifica se o usuário
+// autenticado pode acessá-lo. Em caso de falha a resposta de erro já é
+// enviada e ok é falso.
+func teamMember(c *gin.Context, store *repository.Store) (*models.User, bool) {
+    memberID, err := primitive.ObjectIDFromHex(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Usuário inválido"})
+        return nil, false
+    }
+
+    member, err := store.Users.FindByID(c.Request.Context(), currentCompanyID(c), memberID)
+    if errors.Is(err, repository.ErrNotFound) {
+        c.JSON(http.StatusNotFound, gin.H{"error": "Usuário não encontrado"})
+        return nil, false
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar usuário"})
+        return nil, false
+    }
+
+    if !canAccessUser(c, member) {
+        c.JSON(http.StatusForbidden, gin.H{"error": "Acesso negado"})
+        return nil, false
+    }
+
+    return member, true
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/closing"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/timesheet"
+)
+
+// ClosingHandler trata o fechamento das competências da empresa.
+type ClosingHandler struct {
+	store    *repository.Store
+	closings *closing.Service
+}
+
+func NewClosingHandler(store *repository.Store) *ClosingHandler {
+	return &ClosingHandler{store: store, closings: closing.NewService(store, timesheet.NewService(store))}
+}
+
+type ClosingRequest struct {
+	Year  int `json:"year" binding:"required"`
+	Month int `json:"month" binding:"required,min=1,max=12"`
+}
+
+type ReopenRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// ListClosings lista os fechamentos de competência da empresa.
+func (h *ClosingHandler) ListClosings(c *gin.Context) {
+	closings, err := h.store.Closings.List(c.Request.Context(), currentCompanyID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar fechamentos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, closings)
+}
+
+// GetClosing retorna o fechamento da competência :competence (AAAA-MM), com
+// os totais de cada funcionário e as reaberturas.
+func (h *ClosingHandler) GetClosing(c *gin.Context) {
+	year, month, ok := parseCompetence(c)
+	if !ok {
+		return
+	}
+
+	closed, err := h.store.Closings.FindByMonth(c.Request.Context(), currentCompanyID(c), year, month)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Competência não fechada"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar fechamento"})
+		return
+	}
+
+	c.JSON(http.StatusOK, closed)
+}
+
+// CloseCompetence fecha uma competência encerrada, bloqueando alterações nos
+// seus registros e guardando os totais para a folha de pagamento.
+func (h *ClosingHandler) CloseCompetence(c *gin.Context) {
+	var req ClosingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authorID, _ := c.Get("user_id")
+	closed, err := h.closings.Close(c.Request.Context(), currentCompanyID(c), req.Year, req.Month,
+		authorID.(primitive.ObjectID), time.Now())
+	switch {
+	case errors.Is(err, closing.ErrNotEnded):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, closing.ErrAlreadyClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao fechar competência"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, closed)
+}
+
+// ReopenCompetence reabre a competência :competence para alterações. O motivo
+// é obrigatório e fica registrado no fechamento.
+func (h *ClosingHandler) ReopenCompetence(c *gin.Context) {
+	var req ReopenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	year, month, ok := parseCompetence(c)
+	if !ok {
+		return
+	}
+
+	authorID, _ := c.Get("user_id")
+	reopened, err := h.closings.Reopen(c.Request.Context(), currentCompanyID(c), year, month,
+		authorID.(primitive.ObjectID), req.Reason, time.Now())
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Competência não fechada"})
+		return
+	case errors.Is(err, closing.ErrReasonMissing):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, closing.ErrNotClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao reabrir competência"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reopened)
+}
+
+// parseCompetence lê o parâmetro :competence no formato AAAA-MM. Em caso de
+// falha a resposta de erro já é enviada e ok é falso.
+func parseCompetence(c *gin.Context) (year, month int, ok bool) {
+	t, err := time.Parse("2006-01", c.Param("competence"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Competência inválida: use o formato AAAA-MM"})
+		return 0, 0, false
+	}
+	return t.Year(), int(t.Month()), true
+}
+
+// checkOpen verifica se os registros da empresa entre from e to, inclusive,
+// ainda podem ser alterados. Em caso de competência fechada ou de falha a
+// resposta de erro já é enviada e ok é falso.
+func checkOpen(c *gin.Context, closings *closing.Service, companyID primitive.ObjectID, from, to time.Time) bool {
+	err := closings.Check(c.Request.Context(), companyID, from, to)
+	var closed *closing.ClosedError
+	switch {
+	case errors.As(err, &closed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return false
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao verificar fechamento da competência"})
+		return false
+	}
+	return true
+}
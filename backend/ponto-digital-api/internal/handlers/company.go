@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/afd"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/utils"
+)
+
+type CompanyHandler struct {
+	store *repository.Store
+	rep   *afd.Registry
+}
+
+func NewCompanyHandler(store *repository.Store) *CompanyHandler {
+	return &CompanyHandler{store: store, rep: afd.NewRegistry(store)}
+}
+
+type BranchRequest struct {
+	Name    string `json:"name" binding:"required"`
+	CNPJ    string `json:"cnpj" binding:"required"`
+	Address string `json:"address"`
+	City    string `json:"city"`
+	State   string `json:"state" binding:"omitempty,len=2"`
+
+	AgreementID string `json:"agreementId"` // convenção coletiva aplicável
+}
+
+// GetCompany retorna a empresa do administrador autenticado
+func (h *CompanyHandler) GetCompany(c *gin.Context) {
+	company, ok := h.currentCompany(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, company)
+}
+
+// UpdateCompany altera a razão social e o CNPJ da empresa
+func (h *CompanyHandler) UpdateCompany(c *gin.Context) {
+	var req CompanyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	company, ok := h.currentCompany(c)
+	if !ok {
+		return
+	}
+
+	if !utils.ValidCNPJ(req.CNPJ) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CNPJ inválido"})
+		return
+	}
+
+	cnpj := utils.NormalizeCNPJ(req.CNPJ)
+	if other, err := h.store.Companies.FindByCNPJ(c.Request.Context(), cnpj); err == nil && other.ID != company.ID {
+		c.JSON(http.StatusConflict, gin.H{"error": "CNPJ já cadastrado"})
+		return
+	}
+
+	before := *company
+	company.Name = req.Name
+	company.CNPJ = cnpj
+	company.UpdatedAt = time.Now()
+	if err := h.store.Companies.Update(c.Request.Context(), company); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar empresa"})
+		return
+	}
+	logRepEvent(h.rep.CompanyChanged(c.Request.Context(), &before, company, currentUserID(c), company.UpdatedAt))
+
+	c.JSON(http.StatusOK, company)
+}
+
+// ListBranches retorna os estabelecimentos da empresa
+func (h *CompanyHandler) ListBranches(c *gin.Context) {
+	branches, err := h.store.Branches.ListByCompany(c.Request.Context(), currentCompanyID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar estabelecimentos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, branches)
+}
+
+// CreateBranch cadastra um estabelecimento na empresa
+func (h *CompanyHandler) CreateBranch(c *gin.Context) {
+	var req BranchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	company, ok := h.currentCompany(c)
+	if !ok {
+		return
+	}
+
+	branch := models.Branch{
+		CompanyID: company.ID,
+		CreatedAt: time.Now(),
+	}
+	if !h.applyBranchRequest(c, &branch, req) {
+		return
+	}
+
+	if err := h.store.Branches.Create(c.Request.Context(), &branch); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao cadastrar estabelecimento"})
+		return
+	}
+	logRepEvent(h.rep.Employer(c.Request.Context(), company, &branch, models.RepEventInclusion, currentUserID(c), branch.CreatedAt))
+
+	c.JSON(http.StatusCreated, branch)
+}
+
+// UpdateBranch altera os dados de um estabelecimento da empresa
+func (h *CompanyHandler) UpdateBranch(c *gin.Context) {
+	var req BranchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	branchID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Estabelecimento inválido"})
+		return
+	}
+
+	branch, err := h.store.Branches.FindByID(c.Request.Context(), currentCompanyID(c), branchID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Estabelecimento não encontrado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar estabelecimento"})
+		return
+	}
+
+	company, ok := h.currentCompany(c)
+	if !ok {
+		return
+	}
+
+	before := *branch
+	if !h.applyBranchRequest(c, branch, req) {
+		return
+	}
+
+	if err := h.store.Branches.Update(c.Request.Context(), branch); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar estabelecimento"})
+		return
+	}
+	logRepEvent(h.rep.BranchChanged(c.Request.Context(), company, &before, branch, currentUserID(c), branch.UpdatedAt))
+
+	c.JSON(http.StatusOK, branch)
+}
+
+// applyBranchRequest valida o CNPJ informado e copia os dados da requisição
+// para o estabelecimento. Em caso de falha a resposta de erro já é enviada.
+func (h *CompanyHandler) applyBranchRequest(c *gin.Context, branch *models.Branch, req BranchRequest) bool {
+	if !utils.ValidCNPJ(req.CNPJ) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CNPJ inválido"})
+		return false
+	}
+
+	cnpj := utils.NormalizeCNPJ(req.CNPJ)
+	if other, err := h.store.Branches.FindByCNPJ(c.Request.Context(), cnpj); err == nil && other.ID != branch.ID {
+		c.JSON(http.StatusConflict, gin.H{"error": "CNPJ já cadastrado"})
+		return false
+	}
+
+	var agreementID primitive.ObjectID
+	if req.AgreementID != "" {
+		var err error
+		agreementID, err = primitive.ObjectIDFromHex(req.AgreementID)
+		if err == nil {
+			_, err = h.store.Agreements.FindByID(c.Request.Context(), branch.CompanyID, agreementID)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Convenção inválida"})
+			return false
+		}
+	}
+
+	branch.Name = req.Name
+	branch.CNPJ = cnpj
+	branch.Address = req.Address
+	branch.City = req.City
+	branch.State = req.State
+	branch.AgreementID = agreementID
+	branch.UpdatedAt = time.Now()
+	return true
+}
+
+// currentCompany carrega a empresa do usuário autenticado. Em caso de falha a
+// resposta de erro já é enviada e ok é falso.
+func (h *CompanyHandler) currentCompany(c *gin.Context) (*models.Company, bool) {
+	companyID := currentCompanyID(c)
+	if companyID.IsZero() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Usuário não vinculado a uma empresa"})
+		return nil, false
+	}
+
+	company, err := h.store.Companies.FindByID(c.Request.Context(), companyID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Empresa não encontrada"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar empresa"})
+		return nil, false
+	}
+
+	return company, true
+}
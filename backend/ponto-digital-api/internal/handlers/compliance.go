@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/timesheet"
+)
+
+// ComplianceHandler aponta os descumprimentos dos limites legais de jornada e
+// descanso: intervalo intrajornada, descanso entre jornadas e jornada máxima.
+type ComplianceHandler struct {
+	store  *repository.Store
+	sheets *timesheet.Service
+}
+
+func NewComplianceHandler(store *repository.Store) *ComplianceHandler {
+	return &ComplianceHandler{store: store, sheets: timesheet.NewService(store)}
+}
+
+// MemberViolations são os descumprimentos de um membro da equipe no mês.
+type MemberViolations struct {
+	UserID     primitive.ObjectID    `json:"user_id"`
+	Name       string                `json:"name"`
+	Violations []timesheet.Violation `json:"violations"`
+}
+
+// GetMyViolations lista os descumprimentos do usuário autenticado nos dias
+// encerrados do mês ?year=AAAA&month=MM.
+func (h *ComplianceHandler) GetMyViolations(c *gin.Context) {
+	year, month, ok := parseMonth(c)
+	if !ok {
+		return
+	}
+
+	user, ok := authenticatedUser(c, h.store)
+	if !ok {
+		return
+	}
+
+	violations, err := h.violations(c, user, year, month)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao apurar jornada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, violations)
+}
+
+// ListTeamViolations lista, por funcionário, os descumprimentos da equipe do
+// gestor autenticado, ou de toda a empresa para administradores, no mês
+// ?year=AAAA&month=MM. Funcionários sem descumprimentos não são listados.
+func (h *ComplianceHandler) ListTeamViolations(c *gin.Context) {
+	year, month, ok := parseMonth(c)
+	if !ok {
+		return
+	}
+
+	userIDs, ok := teamUserIDs(c, h.store)
+	if !ok {
+		return
+	}
+
+	users, err := h.store.Users.List(c.Request.Context(), repository.UserFilter{CompanyID: currentCompanyID(c)})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar equipe"})
+		return
+	}
+
+	response := []MemberViolations{}
+	for i := range users {
+		member := &users[i]
+		if userIDs != nil && !containsID(userIDs, member.ID) {
+			continue
+		}
+
+		violations, err := h.violations(c, member, year, month)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao apurar jornada"})
+			return
+		}
+		if len(violations) > 0 {
+			response = append(response, MemberViolations{UserID: member.ID, Name: member.Name, Violations: violations})
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// violations apura os descumprimentos do usuário nos dias encerrados do mês.
+func (h *ComplianceHandler) violations(c *gin.Context, user *models.User, year, month int) ([]timesheet.Violation, error) {
+	days, err := h.sheets.Month(c.Request.Context(), user, year, month)
+	if err != nil {
+		return nil, err
+	}
+	return timesheet.Violations(days, time.Now()), nil
+}
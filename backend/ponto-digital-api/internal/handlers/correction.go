@@ -0,0 +1,379 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/closing"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/timesheet"
+)
+
+// CorrectionHandler trata as solicitações de ajuste de ponto. Os ajustes
+// aprovados geram novos registros; as marcações originais nunca são alteradas
+// ou removidas, conforme a Portaria 671. Marcações de competências fechadas
+// não podem ser ajustadas.
+type CorrectionHandler struct {
+	store    *repository.Store
+	closings *closing.Service
+}
+
+func NewCorrectionHandler(store *repository.Store) *CorrectionHandler {
+	return &CorrectionHandler{store: store, closings: closing.NewService(store, timesheet.NewService(store))}
+}
+
+// CorrectionCreateRequest é o pedido de ajuste do funcionário. Inclusões
+// informam type e timestamp; desconsiderações informam recordId.
+type CorrectionCreateRequest struct {
+	Kind          string `json:"kind" binding:"required,oneof=inclusao desconsideracao"`
+	Type          string `json:"type"`
+	Timestamp     string `json:"timestamp"` // RFC 3339
+	RecordID      string `json:"recordId"`
+	Justification string `json:"justification" binding:"required"`
+	AttachmentID  string `json:"attachmentId"`
+}
+
+type CorrectionReviewRequest struct {
+	Note string `json:"note"`
+}
+
+// CreateCorrection registra uma solicitação de ajuste do usuário autenticado,
+// que fica pendente até a análise do gestor.
+func (h *CorrectionHandler) CreateCorrection(c *gin.Context) {
+	var req CorrectionCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := authenticatedUser(c, h.store)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	correction := models.CorrectionRequest{
+		CompanyID:     user.CompanyID,
+		UserID:        user.ID,
+		Kind:          models.CorrectionKind(req.Kind),
+		Justification: req.Justification,
+		Status:        models.CorrectionPending,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	switch correction.Kind {
+	case models.CorrectionInclude:
+		punchType, ok := models.ParsePunchType(req.Type)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tipo de marcação inválido: use \"entrada\" ou \"saída\""})
+			return
+		}
+		timestamp, err := time.Parse(time.RFC3339, req.Timestamp)
+		if err != nil || !timestamp.Before(now) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Horário inválido: informe um horário passado no formato RFC 3339"})
+			return
+		}
+		correction.Type = punchType
+		correction.Timestamp = timestamp
+
+	case models.CorrectionDisregard:
+		recordID, err := primitive.ObjectIDFromHex(req.RecordID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Marcação inválida"})
+			return
+		}
+		record, ok := h.effectiveRecord(c, user, recordID)
+		if !ok {
+			return
+		}
+		correction.RecordID = record.ID
+		correction.Type = record.Type
+		correction.Timestamp = record.Timestamp
+	}
+
+	if !checkOpen(c, h.closings, user.CompanyID, correction.Timestamp, correction.Timestamp) {
+		return
+	}
+
+	if correction.AttachmentID, ok = ownedAttachment(c, h.store, req.AttachmentID, user); !ok {
+		return
+	}
+
+	if err := h.store.Corrections.Create(c.Request.Context(), &correction); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao registrar solicitação"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, correction)
+}
+
+// ListMyCorrections lista as solicitações de ajuste do usuário autenticado.
+func (h *CorrectionHandler) ListMyCorrections(c *gin.Context) {
+	user, ok := authenticatedUser(c, h.store)
+	if !ok {
+		return
+	}
+
+	status, ok := correctionStatusQuery(c)
+	if !ok {
+		return
+	}
+
+	h.respondCorrections(c, repository.CorrectionFilter{
+		CompanyID: user.CompanyID,
+		UserIDs:   []primitive.ObjectID{user.ID},
+		Status:    status,
+	})
+}
+
+// ListTeamCorrections lista as solicitações de ajuste da equipe do gestor
+// autenticado, ou de toda a empresa para administradores. Aceita o filtro
+// opcional ?status=pendente|aprovada|rejeitada.
+func (h *CorrectionHandler) ListTeamCorrections(c *gin.Context) {
+	status, ok := correctionStatusQuery(c)
+	if !ok {
+		return
+	}
+
+	userIDs, ok := teamUserIDs(c, h.store)
+	if !ok {
+		return
+	}
+
+	h.respondCorrections(c, repository.CorrectionFilter{
+		CompanyID: currentCompanyID(c),
+		UserIDs:   userIDs,
+		Status:    status,
+	})
+}
+
+// ApproveCorrection aprova uma solicitação pendente e aplica o ajuste: uma
+// inclusão gera uma nova marcação e uma desconsideração gera um registro que
+// anula a marcação original.
+func (h *CorrectionHandler) ApproveCorrection(c *gin.Context) {
+	h.review(c, models.CorrectionApproved)
+}
+
+// RejectCorrection rejeita uma solicitação pendente sem alterar as marcações.
+func (h *CorrectionHandler) RejectCorrection(c *gin.Context) {
+	h.review(c, models.CorrectionRejected)
+}
+
+func (h *CorrectionHandler) review(c *gin.Context, status models.CorrectionStatus) {
+	// A observação é opcional e o corpo pode ser omitido
+	var req CorrectionReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	correction, member, ok := h.teamCorrection(c)
+	if !ok {
+		return
+	}
+
+	reviewerID, _ := c.Get("user_id")
+	role, _ := c.Get("role")
+	if member.ID == reviewerID && role != models.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Não é possível analisar a própria solicitação"})
+		return
+	}
+	if correction.Status != models.CorrectionPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Solicitação já analisada"})
+		return
+	}
+
+	// A competência pode ter sido fechada depois do pedido
+	if status == models.CorrectionApproved &&
+		!checkOpen(c, h.closings, correction.CompanyID, correction.Timestamp, correction.Timestamp) {
+		return
+	}
+
+	// A análise é gravada apenas se a solicitação ainda estiver pendente,
+	// antes de aplicar o ajuste, para que duas aprovações simultâneas não
+	// gerem dois registros
+	ctx := c.Request.Context()
+	now := time.Now()
+	correction.Status = status
+	correction.ReviewerID = reviewerID.(primitive.ObjectID)
+	correction.ReviewNote = req.Note
+	correction.ReviewedAt = now
+	correction.UpdatedAt = now
+	err := h.store.Corrections.Review(ctx, correction)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Solicitação já analisada"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar solicitação"})
+		return
+	}
+
+	if status == models.CorrectionApproved {
+		applied, ok := h.apply(c, member, correction)
+		if !ok {
+			// Sem o ajuste gravado, a solicitação volta para nova análise
+			if err := h.store.Corrections.Reopen(ctx, correction); err != nil {
+				log.Printf("Erro ao reabrir a solicitação %s: %v", correction.ID.Hex(), err)
+			}
+			return
+		}
+		correction.AppliedID = applied.ID
+		if err := h.store.Corrections.SetApplied(ctx, correction); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar solicitação"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, correction)
+}
+
+// apply grava o registro que efetiva o ajuste aprovado. Em caso de falha a
+// resposta de erro já é enviada e ok é falso.
+func (h *CorrectionHandler) apply(c *gin.Context, member *models.User, correction *models.CorrectionRequest) (*models.TimeRecord, bool) {
+	record := models.TimeRecord{
+		UserID:       member.ID,
+		CompanyID:    member.CompanyID,
+		BranchID:     member.BranchID,
+		Type:         correction.Type,
+		Timestamp:    correction.Timestamp,
+		AuthMethod:   "correcao",
+		CorrectionID: correction.ID,
+	}
+
+	switch correction.Kind {
+	case models.CorrectionInclude:
+		record.Origin = models.OriginInclusion
+
+	case models.CorrectionDisregard:
+		// A marcação pode ter sido desconsiderada por outra solicitação
+		// depois do pedido
+		target, ok := h.effectiveRecord(c, member, correction.RecordID)
+		if !ok {
+			return nil, false
+		}
+		record.Origin = models.OriginDisregarded
+		record.TargetID = target.ID
+		record.BranchID = target.BranchID
+	}
+
+	if err := h.store.TimeRecords.Create(c.Request.Context(), &record); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao registrar ajuste"})
+		return nil, false
+	}
+	return &record, true
+}
+
+// effectiveRecord carrega uma marcação original do usuário que ainda não foi
+// desconsiderada. Em caso de falha a resposta de erro já é enviada e ok é
+// falso.
+func (h *CorrectionHandler) effectiveRecord(c *gin.Context, user *models.User, id primitive.ObjectID) (*models.TimeRecord, bool) {
+	record, err := h.store.TimeRecords.FindByID(c.Request.Context(), user.CompanyID, id)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && record.UserID != user.ID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Marcação não encontrada"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar marcação"})
+		return nil, false
+	}
+	if record.Origin == models.OriginDisregarded {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Registros de desconsideração não podem ser ajustados"})
+		return nil, false
+	}
+
+	disregarded, err := isDisregarded(c.Request.Context(), h.store, record)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar marcação"})
+		return nil, false
+	}
+	if disregarded {
+		c.JSON(http.StatusConflict, gin.H{"error": "Marcação já desconsiderada"})
+		return nil, false
+	}
+
+	return record, true
+}
+
+// isDisregarded informa se já existe um registro de desconsideração para
+// record. Esses registros repetem o horário da marcação que anulam.
+func isDisregarded(ctx context.Context, store *repository.Store, record *models.TimeRecord) (bool, error) {
+	sameTime, err := store.TimeRecords.FindByUser(ctx, record.CompanyID, record.UserID,
+		record.Timestamp, record.Timestamp.Add(time.Millisecond))
+	if err != nil {
+		return false, err
+	}
+	for _, other := range sameTime {
+		if other.Origin == models.OriginDisregarded && other.TargetID == record.ID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// teamCorrection carrega a solicitação do parâmetro :id e o funcionário que a
+// fez, verificando se o usuário autenticado pode analisá-la. Em caso de falha
+// a resposta de erro já é enviada e ok é falso.
+func (h *CorrectionHandler) teamCorrection(c *gin.Context) (*models.CorrectionRequest, *models.User, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Solicitação inválida"})
+		return nil, nil, false
+	}
+
+	correction, err := h.store.Corrections.FindByID(c.Request.Context(), currentCompanyID(c), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Solicitação não encontrada"})
+		return nil, nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar solicitação"})
+		return nil, nil, false
+	}
+
+	member, err := h.store.Users.FindByID(c.Request.Context(), correction.CompanyID, correction.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Usuário não encontrado"})
+		return nil, nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar usuário"})
+		return nil, nil, false
+	}
+	if !canAccessUser(c, member) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Acesso negado"})
+		return nil, nil, false
+	}
+
+	return correction, member, true
+}
+
+func (h *CorrectionHandler) respondCorrections(c *gin.Context, filter repository.CorrectionFilter) {
+	corrections, err := h.store.Corrections.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar solicitações"})
+		return
+	}
+
+	c.JSON(http.StatusOK, corrections)
+}
+
+// correctionStatusQuery lê o filtro opcional ?status. Em caso de valor
+// inválido a resposta de erro já é enviada e ok é falso.
+func correctionStatusQuery(c *gin.Context) (models.CorrectionStatus, bool) {
+	status := models.CorrectionStatus(c.Query("status"))
+	switch status {
+	case "", models.CorrectionPending, models.CorrectionApproved, models.CorrectionRejected:
+		return status, true
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Status inválido: use pendente, aprovada ou rejeitada"})
+	return "", false
+}
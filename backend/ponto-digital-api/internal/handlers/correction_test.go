@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/afd"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/punch"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/timesheet"
+)
+
+// newCorrectionRouter monta as rotas de análise de ajustes sobre um
+// repositório em memória, autenticadas como o administrador da empresa do
+// funcionário member.
+func newCorrectionRouter(t *testing.T) (*gin.Engine, *repository.Store, *models.User) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	store := repository.NewMemoryStore()
+	company := models.Company{Name: "ACME", CNPJ: "11222333000181"}
+	if err := store.Companies.Create(ctx, &company); err != nil {
+		t.Fatal(err)
+	}
+	admin := models.User{Name: "Carla", CompanyID: company.ID, Role: models.RoleAdmin}
+	member := models.User{Name: "Ana", CPF: "12345678909", CompanyID: company.ID, Role: models.RoleEmployee}
+	for _, user := range []*models.User{&admin, &member} {
+		if err := store.Users.Create(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	handler := NewCorrectionHandler(store)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", admin.ID)
+		c.Set("company_id", company.ID)
+		c.Set("role", admin.Role)
+	})
+	router.PUT("/corrections/:id/approve", handler.ApproveCorrection)
+	router.PUT("/corrections/:id/reject", handler.RejectCorrection)
+	return router, store, &member
+}
+
+func TestReviewCorrection(t *testing.T) {
+	ctx := context.Background()
+	timestamp := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	tests := []struct {
+		name        string
+		action      string
+		kind        models.CorrectionKind
+		recordID    primitive.ObjectID
+		wantCode    int
+		wantStatus  models.CorrectionStatus
+		wantApplied bool
+	}{
+		{name: "inclusão aprovada", action: "approve", kind: models.CorrectionInclude, wantCode: http.StatusOK, wantStatus: models.CorrectionApproved, wantApplied: true},
+		{name: "inclusão rejeitada", action: "reject", kind: models.CorrectionInclude, wantCode: http.StatusOK, wantStatus: models.CorrectionRejected},
+		{name: "desconsideração de marcação inexistente volta a ficar pendente", action: "approve", kind: models.CorrectionDisregard, recordID: primitive.NewObjectID(), wantCode: http.StatusNotFound, wantStatus: models.CorrectionPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, store, member := newCorrectionRouter(t)
+			correction := models.CorrectionRequest{
+				CompanyID: member.CompanyID,
+				UserID:    member.ID,
+				Kind:      tt.kind,
+				Type:      models.PunchEntrada,
+				Timestamp: timestamp,
+				RecordID:  tt.recordID,
+				Status:    models.CorrectionPending,
+			}
+			if err := store.Corrections.Create(ctx, &correction); err != nil {
+				t.Fatal(err)
+			}
+
+			w := serve(router, http.MethodPut, "/corrections/"+correction.ID.Hex()+"/"+tt.action, "")
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+
+			stored, err := store.Corrections.FindByID(ctx, member.CompanyID, correction.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Status != tt.wantStatus || stored.AppliedID.IsZero() == tt.wantApplied {
+				t.Errorf("Status = %q, AppliedID = %s, want %q (applied %v)", stored.Status, stored.AppliedID.Hex(), tt.wantStatus, tt.wantApplied)
+			}
+			if tt.wantStatus == models.CorrectionPending && !stored.ReviewerID.IsZero() {
+				t.Errorf("ReviewerID = %s, want it cleared", stored.ReviewerID.Hex())
+			}
+		})
+	}
+}
+
+func TestApproveCorrectionConcurrently(t *testing.T) {
+	router, store, member := newCorrectionRouter(t)
+	ctx := context.Background()
+	timestamp := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	correction := models.CorrectionRequest{
+		CompanyID: member.CompanyID,
+		UserID:    member.ID,
+		Kind:      models.CorrectionInclude,
+		Type:      models.PunchEntrada,
+		Timestamp: timestamp,
+		Status:    models.CorrectionPending,
+	}
+	if err := store.Corrections.Create(ctx, &correction); err != nil {
+		t.Fatal(err)
+	}
+
+	const requests = 10
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	codes := map[int]int{}
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := serve(router, http.MethodPut, "/corrections/"+correction.ID.Hex()+"/approve", "")
+			mu.Lock()
+			codes[w.Code]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if codes[http.StatusOK] != 1 || codes[http.StatusConflict] != requests-1 {
+		t.Errorf("status codes = %v, want one %d and %d %d", codes, http.StatusOK, requests-1, http.StatusConflict)
+	}
+
+	records, err := store.TimeRecords.FindByUser(ctx, member.CompanyID, member.ID, timestamp, timestamp.Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].CorrectionID != correction.ID {
+		t.Errorf("records = %+v, want a single inclusion", records)
+	}
+}
+
+func TestApprovedCorrectionKeepsAFDSequence(t *testing.T) {
+	router, store, member := newCorrectionRouter(t)
+	ctx := context.Background()
+	loc := timesheet.NewService(store).Location
+	yesterday := time.Now().In(loc).AddDate(0, 0, -1)
+	start := time.Date(yesterday.Year(), yesterday.Month(), yesterday.Day(), 0, 0, 0, 0, loc)
+
+	register := func(hour int) {
+		record := models.TimeRecord{CompanyID: member.CompanyID, UserID: member.ID, Timestamp: start.Add(time.Duration(hour) * time.Hour)}
+		if err := store.TimeRecords.CreatePunch(ctx, &record, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A saída esquecida das 12h é incluída por ajuste entre as marcações
+	register(8)
+	correction := models.CorrectionRequest{
+		CompanyID: member.CompanyID,
+		UserID:    member.ID,
+		Kind:      models.CorrectionInclude,
+		Type:      models.PunchSaida,
+		Timestamp: start.Add(12 * time.Hour),
+		Status:    models.CorrectionPending,
+	}
+	if err := store.Corrections.Create(ctx, &correction); err != nil {
+		t.Fatal(err)
+	}
+	if w := serve(router, http.MethodPut, "/corrections/"+correction.ID.Hex()+"/approve", ""); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	register(13)
+	register(17)
+
+	file, err := afd.NewService(store, afd.Program{}, loc).Generate(ctx, member.CompanyID, primitive.NilObjectID, start, start, time.Now())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(file.Punches) != 3 {
+		t.Fatalf("Punches = %+v, want the 3 original punches", file.Punches)
+	}
+	for i, record := range file.Punches {
+		if record.NSR != int64(i+1) {
+			t.Errorf("punch %d NSR = %d, want %d", i+1, record.NSR, i+1)
+		}
+	}
+
+	records, events, head, err := store.TimeRecords.FindChain(ctx, member.CompanyID, primitive.NilObjectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report := punch.VerifyChain(records, events, head.NSR, head.Hash); !report.Valid || report.LastNSR != 3 {
+		t.Errorf("VerifyChain() = %+v, want a valid sequence up to NSR 3", report)
+	}
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/aej"
+	"ponto-digital-api/internal/afd"
+	"ponto-digital-api/internal/punch"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/signature"
+	"ponto-digital-api/internal/timesheet"
+)
+
+// FiscalHandler gera os arquivos exigidos pela Portaria 671 para a
+// fiscalização do trabalho.
+type FiscalHandler struct {
+	store  *repository.Store
+	sheets *timesheet.Service
+	afd    *afd.Service
+	aej    *aej.Service
+	signer *signature.Signer
+}
+
+func NewFiscalHandler(store *repository.Store, signer *signature.Signer, program afd.Program) *FiscalHandler {
+	sheets := timesheet.NewService(store)
+	return &FiscalHandler{
+		store:  store,
+		sheets: sheets,
+		afd:    afd.NewService(store, program, sheets.Location),
+		aej:    aej.NewService(store, sheets, program),
+		signer: signer,
+	}
+}
+
+// ExportAFD gera o AFD de um estabelecimento (?branchId, ou a sede quando
+// omitido) entre as datas ?from e ?to. A resposta é um ZIP com o arquivo e
+// sua assinatura destacada (.p7s).
+func (h *FiscalHandler) ExportAFD(c *gin.Context) {
+	from, to, branchID, ok := h.parseExport(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	file, err := h.afd.Generate(c.Request.Context(), currentCompanyID(c), branchID, from, to, now)
+	if !h.checkGenerate(c, err, "Erro ao gerar AFD") {
+		return
+	}
+
+	h.respondSigned(c, file.FileName(), file.Bytes(), now)
+}
+
+// ExportAEJ gera o AEJ de um estabelecimento (?branchId, ou a sede quando
+// omitido) entre as datas ?from e ?to, com os horários contratuais, as
+// marcações tratadas, as faltas e os movimentos no banco de horas. A resposta
+// é um ZIP com o arquivo e sua assinatura destacada (.p7s).
+func (h *FiscalHandler) ExportAEJ(c *gin.Context) {
+	from, to, branchID, ok := h.parseExport(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	file, err := h.aej.Generate(c.Request.Context(), currentCompanyID(c), branchID, from, to, now)
+	if !h.checkGenerate(c, err, "Erro ao gerar AEJ") {
+		return
+	}
+
+	h.respondSigned(c, file.FileName(), file.Bytes(), now)
+}
+
+// VerifyRecords confere a numeração sequencial (NSR) e o encadeamento dos
+// hashes dos registros de ponto de um estabelecimento (?branchId, ou a sede
+// quando omitido), apontando lacunas, duplicidades e alterações.
+func (h *FiscalHandler) VerifyRecords(c *gin.Context) {
+	branchID, ok := parseBranch(c)
+	if !ok {
+		return
+	}
+
+	companyID := currentCompanyID(c)
+	if !branchID.IsZero() {
+		_, err := h.store.Branches.FindByID(c.Request.Context(), companyID, branchID)
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Estabelecimento não encontrado"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar estabelecimento"})
+			return
+		}
+	}
+
+	records, events, head, err := h.store.TimeRecords.FindChain(c.Request.Context(), companyID, branchID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar registros"})
+		return
+	}
+	c.JSON(http.StatusOK, punch.VerifyChain(records, events, head.NSR, head.Hash))
+}
+
+// parseExport lê o período e o estabelecimento (?branchId) da exportação.
+func (h *FiscalHandler) parseExport(c *gin.Context) (from, to time.Time, branchID primitive.ObjectID, ok bool) {
+	if from, to, ok = h.parsePeriod(c); !ok {
+		return from, to, branchID, false
+	}
+	branchID, ok = parseBranch(c)
+	return from, to, branchID, ok
+}
+
+// parseBranch lê o estabelecimento ?branchId, nulo quando omitido. Em caso de
+// falha a resposta de erro já é enviada e ok é falso.
+func parseBranch(c *gin.Context) (primitive.ObjectID, bool) {
+	value := c.Query("branchId")
+	if value == "" {
+		return primitive.NilObjectID, true
+	}
+	branchID, err := primitive.ObjectIDFromHex(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Estabelecimento inválido"})
+		return branchID, false
+	}
+	return branchID, true
+}
+
+// checkGenerate envia a resposta de erro da geração de um arquivo fiscal e
+// retorna falso quando err não é nulo.
+func (h *FiscalHandler) checkGenerate(c *gin.Context, err error, message string) bool {
+	var missingCPF *afd.MissingCPFError
+	switch {
+	case err == nil:
+		return true
+	case errors.As(err, &missingCPF):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "users": missingCPF.Names})
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Estabelecimento não encontrado"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+	return false
+}
+
+// respondSigned assina content e envia um ZIP com o arquivo e a assinatura.
+func (h *FiscalHandler) respondSigned(c *gin.Context, name string, content []byte, now time.Time) {
+	signed, err := h.signer.SignDetached(content, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao assinar arquivo"})
+		return
+	}
+
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	for _, entry := range []struct {
+		name    string
+		content []byte
+	}{{name, content}, {name + ".p7s", signed}} {
+		w, err := writer.CreateHeader(&zip.FileHeader{Name: entry.name, Method: zip.Deflate, Modified: now})
+		if err == nil {
+			_, err = w.Write(entry.content)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao compactar arquivo"})
+			return
+		}
+	}
+	if err := writer.Close(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao compactar arquivo"})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+name+`.zip"`)
+	c.Data(http.StatusOK, "application/zip", archive.Bytes())
+}
+
+// parsePeriod lê as datas ?from e ?to, inclusive, limitadas a um ano. Em caso
+// de falha a resposta de erro já é enviada e ok é falso.
+func (h *FiscalHandler) parsePeriod(c *gin.Context) (from, to time.Time, ok bool) {
+	from, err := time.ParseInLocation(timesheet.DateLayout, c.Query("from"), h.sheets.Location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Data inicial inválida"})
+		return from, to, false
+	}
+	to, err = time.ParseInLocation(timesheet.DateLayout, c.Query("to"), h.sheets.Location)
+	if err != nil || to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Data final inválida"})
+		return from, to, false
+	}
+	if to.Sub(from) > 366*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "O período deve ter no máximo um ano"})
+		return from, to, false
+	}
+	return from, to, true
+}
@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/timesheet"
+)
+
+// maxImportSize limita o tamanho dos calendários importados.
+const maxImportSize = 1 << 20
+
+type HolidayHandler struct {
+	store  *repository.Store
+	sheets *timesheet.Service
+}
+
+func NewHolidayHandler(store *repository.Store) *HolidayHandler {
+	return &HolidayHandler{store: store, sheets: timesheet.NewService(store)}
+}
+
+type HolidayRequest struct {
+	Date     string              `json:"date" binding:"required"` // AAAA-MM-DD
+	Name     string              `json:"name" binding:"required"`
+	Scope    models.HolidayScope `json:"scope"`    // padrão: empresa
+	BranchID string              `json:"branchId"` // vazio vale para toda a empresa
+	Optional bool                `json:"optional"`
+}
+
+// GetMyHolidays retorna os feriados do usuário autenticado no ano informado
+// (padrão: ano atual)
+func (h *HolidayHandler) GetMyHolidays(c *gin.Context) {
+	user, ok := authenticatedUser(c, h.store)
+	if !ok {
+		return
+	}
+
+	start, end, ok := h.yearRange(c)
+	if !ok {
+		return
+	}
+
+	policy, err := h.sheets.PolicyFor(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar regras da empresa"})
+		return
+	}
+
+	calendar, err := h.sheets.Holidays(c.Request.Context(), user, policy, start, end)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar feriados"})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar.List())
+}
+
+// ListHolidays retorna os feriados e folgas cadastrados pela empresa no ano
+// informado (padrão: ano atual)
+func (h *HolidayHandler) ListHolidays(c *gin.Context) {
+	start, end, ok := h.yearRange(c)
+	if !ok {
+		return
+	}
+
+	holidays, err := h.store.Holidays.ListByCompany(c.Request.Context(), currentCompanyID(c), start, end.AddDate(0, 0, 1))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar feriados"})
+		return
+	}
+
+	c.JSON(http.StatusOK, holidays)
+}
+
+// CreateHoliday cadastra um feriado ou uma folga concedida pela empresa
+func (h *HolidayHandler) CreateHoliday(c *gin.Context) {
+	var req HolidayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	companyID := currentCompanyID(c)
+	var branchID primitive.ObjectID
+	if req.BranchID != "" {
+		var err error
+		branchID, err = primitive.ObjectIDFromHex(req.BranchID)
+		if err == nil {
+			_, err = h.store.Branches.FindByID(c.Request.Context(), companyID, branchID)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Estabelecimento inválido"})
+			return
+		}
+	}
+
+	holiday, err := h.newHoliday(companyID, branchID, req.Scope, req.Date, req.Name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	holiday.Optional = req.Optional
+
+	if err := h.store.Holidays.Create(c.Request.Context(), &holiday); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao cadastrar feriado"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, holiday)
+}
+
+// DeleteHoliday remove um feriado cadastrado pela empresa
+func (h *HolidayHandler) DeleteHoliday(c *gin.Context) {
+	holidayID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Feriado inválido"})
+		return
+	}
+
+	err = h.store.Holidays.Delete(c.Request.Context(), currentCompanyID(c), holidayID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Feriado não encontrado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao remover feriado"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ImportBranchHolidays importa o calendário estadual ou municipal de um
+// estabelecimento. O corpo é um CSV com uma linha "AAAA-MM-DD;Nome" por
+// feriado e o parâmetro scope indica se o calendário é estadual ou municipal.
+// Datas já cadastradas para o estabelecimento são ignoradas.
+func (h *HolidayHandler) ImportBranchHolidays(c *gin.Context) {
+	scope := models.HolidayScope(c.Query("scope"))
+	if scope != models.HolidayState && scope != models.HolidayMunicipal {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Informe scope=estadual ou scope=municipal"})
+		return
+	}
+
+	branchID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Estabelecimento inválido"})
+		return
+	}
+
+	companyID := currentCompanyID(c)
+	branch, err := h.store.Branches.FindByID(c.Request.Context(), companyID, branchID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Estabelecimento não encontrado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar estabelecimento"})
+		return
+	}
+
+	holidays, err := h.parseCalendar(io.LimitReader(c.Request.Body, maxImportSize), companyID, branch.ID, scope)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(holidays) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nenhum feriado informado"})
+		return
+	}
+
+	// Datas já cadastradas no estabelecimento não são importadas novamente
+	start, end := holidays[0].Date, holidays[0].Date
+	for _, holiday := range holidays {
+		if holiday.Date.Before(start) {
+			start = holiday.Date
+		}
+		if holiday.Date.After(end) {
+			end = holiday.Date
+		}
+	}
+	existing, err := h.store.Holidays.ListByCompany(c.Request.Context(), companyID, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar feriados"})
+		return
+	}
+	known := make(map[string]bool)
+	for _, holiday := range existing {
+		if holiday.BranchID == branch.ID {
+			known[holiday.Date.In(h.sheets.Location).Format(timesheet.DateLayout)] = true
+		}
+	}
+
+	imported := []models.Holiday{}
+	skipped := 0
+	for _, holiday := range holidays {
+		key := holiday.Date.Format(timesheet.DateLayout)
+		if known[key] {
+			skipped++
+			continue
+		}
+		if err := h.store.Holidays.Create(c.Request.Context(), &holiday); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao cadastrar feriado"})
+			return
+		}
+		known[key] = true
+		imported = append(imported, holiday)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"imported": imported,
+		"skipped":  skipped,
+	})
+}
+
+// parseCalendar lê as linhas "AAAA-MM-DD;Nome" do calendário importado. Linhas
+// em branco são ignoradas e vírgula também é aceita como separador.
+func (h *HolidayHandler) parseCalendar(body io.Reader, companyID, branchID primitive.ObjectID, scope models.HolidayScope) ([]models.Holiday, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, errors.New("Erro ao ler calendário")
+	}
+
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.Comma = ';'
+	if !strings.Contains(string(data), ";") {
+		reader.Comma = ','
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var holidays []models.Holiday
+	for line := 1; ; line++ {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil || len(fields) < 2 {
+			return nil, fmt.Errorf("Linha %d inválida: use AAAA-MM-DD;Nome", line)
+		}
+
+		holiday, err := h.newHoliday(companyID, branchID, scope, strings.TrimSpace(fields[0]), strings.TrimSpace(fields[1]))
+		if err != nil {
+			return nil, fmt.Errorf("Linha %d: %s", line, err.Error())
+		}
+		holidays = append(holidays, holiday)
+	}
+	return holidays, nil
+}
+
+// newHoliday valida a data, o nome e a abrangência de um feriado da empresa
+func (h *HolidayHandler) newHoliday(companyID, branchID primitive.ObjectID, scope models.HolidayScope, date, name string) (models.Holiday, error) {
+	if scope == "" {
+		scope = models.HolidayCompany
+	}
+	switch scope {
+	case models.HolidayState, models.HolidayMunicipal, models.HolidayCompany:
+	default:
+		return models.Holiday{}, errors.New("Abrangência do feriado inválida")
+	}
+
+	parsed, err := time.ParseInLocation(timesheet.DateLayout, date, h.sheets.Location)
+	if err != nil {
+		return models.Holiday{}, errors.New("Data do feriado inválida")
+	}
+	if name == "" {
+		return models.Holiday{}, errors.New("Informe o nome do feriado")
+	}
+
+	return models.Holiday{
+		CompanyID: companyID,
+		BranchID:  branchID,
+		Scope:     scope,
+		Date:      parsed,
+		Name:      name,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// yearRange lê o parâmetro year e retorna o primeiro e o último dia do ano.
+// Em caso de falha a resposta de erro já é enviada e ok é falso.
+func (h *HolidayHandler) yearRange(c *gin.Context) (time.Time, time.Time, bool) {
+	year := time.Now().In(h.sheets.Location).Year()
+	if value := c.Query("year"); value != "" {
+		var err error
+		if year, err = strconv.Atoi(value); err != nil || year < 1900 || year > 2200 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Ano inválido"})
+			return time.Time{}, time.Time{}, false
+		}
+	}
+
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, h.sheets.Location)
+	return start, start.AddDate(1, 0, -1), true
+}
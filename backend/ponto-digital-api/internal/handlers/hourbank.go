@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/closing"
+	"ponto-digital-api/internal/hourbank"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/timesheet"
+)
+
+type HourBankHandler struct {
+	store    *repository.Store
+	sheets   *timesheet.Service
+	bank     *hourbank.Service
+	closings *closing.Service
+}
+
+func NewHourBankHandler(store *repository.Store) *HourBankHandler {
+	sheets := timesheet.NewService(store)
+	return &HourBankHandler{
+		store:    store,
+		sheets:   sheets,
+		bank:     hourbank.NewService(store, sheets),
+		closings: closing.NewService(store, sheets),
+	}
+}
+
+type HourBankAdjustmentRequest struct {
+	Date    string `json:"date" binding:"required"` // AAAA-MM-DD
+	Minutes int    `json:"minutes" binding:"required"`
+	Reason  string `json:"reason" binding:"required"`
+}
+
+// GetMyHourBank retorna o extrato do banco de horas do usuário autenticado
+func (h *HourBankHandler) GetMyHourBank(c *gin.Context) {
+	user, ok := authenticatedUser(c, h.store)
+	if !ok {
+		return
+	}
+
+	h.respondStatement(c, user)
+}
+
+// GetMemberHourBank retorna o extrato do banco de horas de um membro da equipe
+func (h *HourBankHandler) GetMemberHourBank(c *gin.Context) {
+	member, ok := teamMember(c, h.store)
+	if !ok {
+		return
+	}
+
+	h.respondStatement(c, member)
+}
+
+// CreateAdjustment lança um crédito ou débito manual no banco de horas de um
+// membro da equipe. Gestores não podem lançar no próprio banco.
+func (h *HourBankHandler) CreateAdjustment(c *gin.Context) {
+	var req HourBankAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, ok := teamMember(c, h.store)
+	if !ok {
+		return
+	}
+
+	authorID, _ := c.Get("user_id")
+	role, _ := c.Get("role")
+	if member.ID == authorID && role != models.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Não é possível lançar ajustes no próprio banco de horas"})
+		return
+	}
+
+	date, err := time.ParseInLocation(timesheet.DateLayout, req.Date, h.sheets.Location)
+	if err != nil || date.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Data inválida"})
+		return
+	}
+	if !checkOpen(c, h.closings, member.CompanyID, date, date) {
+		return
+	}
+
+	adjustment := models.HourBankAdjustment{
+		CompanyID: member.CompanyID,
+		UserID:    member.ID,
+		Date:      date,
+		Minutes:   req.Minutes,
+		Reason:    req.Reason,
+		CreatedBy: authorID.(primitive.ObjectID),
+		CreatedAt: time.Now(),
+	}
+	if err := h.store.HourBank.Create(c.Request.Context(), &adjustment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao registrar ajuste"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, adjustment)
+}
+
+func (h *HourBankHandler) respondStatement(c *gin.Context, user *models.User) {
+	statement, err := h.bank.Statement(c.Request.Context(), user, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao apurar banco de horas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
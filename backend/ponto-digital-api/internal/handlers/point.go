@@ -2,45 +2,65 @@ package handlers
 
 import (
 	//"bytes" // Adicionado
-	"context"
-	"encoding/base64"
 	"encoding/json" // Adicionado
 	"errors"
+	"fmt"
 	//"io" // Adicionado
-	//"log"
+	"log"
 	"net/http"
-	"sort"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	"ponto-digital-api/internal/espelho"
 	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/pin"
+	"ponto-digital-api/internal/punch"
+	"ponto-digital-api/internal/receipt"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/signature"
+	"ponto-digital-api/internal/timesheet"
+	"ponto-digital-api/internal/webauthn"
 )
 
 type PointHandler struct {
-	db *mongo.Database
+	store    *repository.Store
+	sheets   *timesheet.Service
+	receipts *receipt.Service
+	espelhos *espelho.Service
+	rp       *webauthn.RelyingParty
 }
 
-func NewPointHandler(db *mongo.Database) *PointHandler {
-	return &PointHandler{db: db}
+// NewPointHandler cria o handler de marcações; os comprovantes são assinados
+// com signer e identificam o programa pelo registro inpi, e as marcações
+// biométricas são asserções WebAuthn verificadas com rp.
+func NewPointHandler(store *repository.Store, signer *signature.Signer, inpi string, rp *webauthn.RelyingParty) *PointHandler {
+	sheets := timesheet.NewService(store)
+	return &PointHandler{
+		store:    store,
+		sheets:   sheets,
+		receipts: receipt.NewService(store, signer, inpi, sheets.Location),
+		espelhos: espelho.NewService(store, sheets),
+		rp:       rp,
+	}
 }
 
+// O campo type das requisições de marcação é opcional: quando omitido, o
+// servidor determina o próximo tipo esperado a partir da última marcação
 type PinRequest struct {
-    Type      string `json:"type" binding:"required"`
+    Type      string `json:"type"`
     Pin       string `json:"pin" binding:"required"`
     Location  string `json:"location"`
     Device    string `json:"device"`
     AuthMethod string `json:"authMethod" binding:"required,eq=pin"`
 }
 
-// BiometricRequest para requisições com biometria
+// BiometricRequest para requisições com biometria: assertion é a resposta de
+// navigator.credentials.get() ao desafio de /webauthn/authenticate/options
 type BiometricRequest struct {
-    Type          string `json:"type" binding:"required"`
-    BiometricToken string `json:"biometricToken" binding:"required"`
+    Type          string `json:"type"`
+    Assertion     *webauthn.AssertionResponse `json:"assertion" binding:"required"`
     Location      string `json:"location"`
     Device        string `json:"device"`
     AuthMethod    string `json:"authMethod" binding:"required,eq=biometric"`
@@ -60,35 +80,51 @@ func (req RegisterPointRequest) Validate() error {
     return nil
 }*/
 
-func (h *PointHandler) verifyPin(userID primitive.ObjectID, pin string) error {
-    var user models.User
-    err := h.db.Collection("users").FindOne(
-        context.Background(),
-        bson.M{"_id": userID},
-    ).Decode(&user)
-
-    if err != nil {
-        return err
-    }
-
-    if user.Pin != pin {
-        return errors.New("PIN inválido")
+// verifyPin confere o PIN do usuário. As tentativas erradas são contadas no
+// banco de forma atômica e o bloqueio é lido do documento atualizado; no
+// acerto, apenas os campos do PIN são gravados. Em caso de falha a resposta
+// de erro já é enviada e ok é falso
+func verifyPin(c *gin.Context, store *repository.Store, user *models.User, value string) bool {
+    ctx := c.Request.Context()
+    now := time.Now()
+    save, err := pin.Verify(user, value, now)
+    if errors.Is(err, pin.ErrNotConfigured) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return false
+    }
+    if errors.Is(err, pin.ErrMismatch) {
+        updated, updateErr := store.Users.RecordPinFailure(ctx, user.CompanyID, user.ID, pin.MaxAttempts, now, now.Add(pin.LockDuration))
+        if updateErr != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao verificar PIN"})
+            return false
+        }
+        err = pin.Failure(updated, now)
     }
-
-    return nil
-}
-
-func (h *PointHandler) verifyBiometricToken(token string) error {
-    if token == "" {
-        return errors.New("Token biométrico não fornecido")
+    if err == nil && save {
+        user.UpdatedAt = now
+        if err := store.Users.UpdatePin(ctx, user); err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao verificar PIN"})
+            return false
+        }
     }
 
-    _, err := base64.StdEncoding.DecodeString(token)
-    if err != nil {
-        return errors.New("Token biométrico inválido")
+    var invalidErr *pin.InvalidError
+    var lockedErr *pin.LockedError
+    switch {
+    case errors.As(err, &lockedErr):
+        c.JSON(http.StatusTooManyRequests, gin.H{
+            "error":       err.Error(),
+            "lockedUntil": lockedErr.Until,
+        })
+        return false
+    case errors.As(err, &invalidErr):
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return false
+    case err != nil:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao verificar PIN"})
+        return false
     }
-
-    return nil
+    return true
 }
 
 func (h *PointHandler) RegisterPoint(c *gin.Context) {
@@ -121,76 +157,164 @@ func (h *PointHandler) RegisterPoint(c *gin.Context) {
 }
 
 func (h *PointHandler) handlePinRequest(c *gin.Context, req PinRequest) {
-    userID, exists := c.Get("user_id")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+    user, ok := h.currentUser(c)
+    if !ok {
         return
     }
 
     // Verificar PIN
-    if err := h.verifyPin(userID.(primitive.ObjectID), req.Pin); err != nil {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+    if !verifyPin(c, h.store, user, req.Pin) {
         return
     }
 
-    // Criar registro
-    timeRecord := models.TimeRecord{
-        UserID:     userID.(primitive.ObjectID),
-        Type:       req.Type,
-        Timestamp:  time.Now(),
+    h.saveRecord(c, user, req.Type, models.TimeRecord{
         Location:   req.Location,
         Device:     req.Device,
         AuthMethod: "pin",
+    })
+}
+
+func (h *PointHandler) handleBiometricRequest(c *gin.Context, req BiometricRequest) {
+    user, ok := h.currentUser(c)
+    if !ok {
+        return
     }
 
-    result, err := h.db.Collection("time_records").InsertOne(context.Background(), timeRecord)
-    if err != nil {
+    // Verificar a asserção WebAuthn assinada pelo autenticador
+    if req.Assertion == nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Asserção biométrica não fornecida"})
+        return
+    }
+    if !verifyAssertion(c, h.store, h.rp, user, req.Assertion) {
+        return
+    }
+
+    h.saveRecord(c, user, req.Type, models.TimeRecord{
+        Location:   req.Location,
+        Device:     req.Device,
+        AuthMethod: "biometric",
+    })
+}
+
+// currentUser carrega o usuário autenticado. Em caso de falha a resposta de
+// erro já é enviada e ok é falso.
+func (h *PointHandler) currentUser(c *gin.Context) (*models.User, bool) {
+    return authenticatedUser(c, h.store)
+}
+
+// saveRecord grava a marcação do usuário, já autenticado, validando o tipo
+// solicitado contra a sequência de marcações, e envia a resposta
+func (h *PointHandler) saveRecord(c *gin.Context, user *models.User, requestedType string, timeRecord models.TimeRecord) {
+    timeRecord.UserID = user.ID
+    timeRecord.CompanyID = user.CompanyID
+    timeRecord.BranchID = user.BranchID
+    timeRecord.Timestamp = time.Now()
+
+    // A sequência é conferida pelo repositório na mesma transação que grava
+    // a marcação, para que duas requisições simultâneas não registrem duas
+    // entradas seguidas
+    err := h.store.TimeRecords.CreatePunch(c.Request.Context(), &timeRecord, requestedType)
+    var sequenceErr *punch.SequenceError
+    switch {
+    case errors.As(err, &sequenceErr):
+        c.JSON(http.StatusConflict, gin.H{
+            "error":    err.Error(),
+            "expected": sequenceErr.Expected,
+        })
+        return
+    case errors.Is(err, punch.ErrInvalidType):
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    case err != nil:
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao registrar ponto"})
         return
     }
 
+    // A marcação já está gravada; sem o comprovante, ele pode ser obtido
+    // depois em /points/:id/receipt
+    issued, err := h.receipts.Issue(c.Request.Context(), &timeRecord)
+    if err != nil {
+        log.Printf("Erro ao emitir comprovante da marcação %s: %v", timeRecord.ID.Hex(), err)
+    }
+
     c.JSON(http.StatusCreated, gin.H{
-        "id":        result.InsertedID,
+        "id":        timeRecord.ID,
         "message":   "Ponto registrado com sucesso",
+        "nsr":       timeRecord.NSR,
+        "receipt":   issued,
         "timestamp": timeRecord.Timestamp,
         "type":      timeRecord.Type,
     })
 }
 
-func (h *PointHandler) handleBiometricRequest(c *gin.Context, req BiometricRequest) {
-    userID, exists := c.Get("user_id")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+// GetReceipt emite o comprovante assinado de uma marcação do usuário ou de um
+// membro da sua equipe, em JSON ou, com ?format=pdf, em PDF
+func (h *PointHandler) GetReceipt(c *gin.Context) {
+    id, err := primitive.ObjectIDFromHex(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
         return
     }
 
-    // Verificar token biométrico
-    if err := h.verifyBiometricToken(req.BiometricToken); err != nil {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+    record, err := h.store.TimeRecords.FindByID(c.Request.Context(), currentCompanyID(c), id)
+    if errors.Is(err, repository.ErrNotFound) {
+        c.JSON(http.StatusNotFound, gin.H{"error": "Marcação não encontrada"})
         return
     }
-
-    // Criar registro
-    timeRecord := models.TimeRecord{
-        UserID:     userID.(primitive.ObjectID),
-        Type:       req.Type,
-        Timestamp:  time.Now(),
-        Location:   req.Location,
-        Device:     req.Device,
-        AuthMethod: "biometric",
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar marcação"})
+        return
+    }
+    owner, err := h.store.Users.FindByID(c.Request.Context(), record.CompanyID, record.UserID)
+    if err != nil || !canAccessUser(c, owner) {
+        c.JSON(http.StatusNotFound, gin.H{"error": "Marcação não encontrada"})
+        return
+    }
+    // Ajustes de ponto não são marcações do trabalhador
+    if record.Origin != "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Ajustes de ponto não têm comprovante"})
+        return
     }
 
-    result, err := h.db.Collection("time_records").InsertOne(context.Background(), timeRecord)
+    issued, err := h.receipts.Issue(c.Request.Context(), record)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao registrar ponto"})
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao emitir comprovante"})
         return
     }
 
-    c.JSON(http.StatusCreated, gin.H{
-        "id":        result.InsertedID,
-        "message":   "Ponto registrado com sucesso",
-        "timestamp": timeRecord.Timestamp,
-        "type":      timeRecord.Type,
+    if c.Query("format") == "pdf" {
+        c.Header("Content-Disposition", `attachment; filename="comprovante-`+record.ID.Hex()+`.pdf"`)
+        c.Data(http.StatusOK, "application/pdf", issued.PDF())
+        return
+    }
+    c.JSON(http.StatusOK, issued)
+}
+
+// VerifyReceipt confere a assinatura de um comprovante e se ele corresponde a
+// uma marcação gravada. A rota é pública, para que o trabalhador ou a
+// fiscalização confiram o comprovante sem acesso ao sistema
+func (h *PointHandler) VerifyReceipt(c *gin.Context) {
+    var req receipt.Receipt
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    record, err := h.receipts.Verify(c.Request.Context(), &req)
+    switch {
+    case errors.Is(err, receipt.ErrInvalidSignature), errors.Is(err, receipt.ErrRecordNotFound), errors.Is(err, receipt.ErrMismatch):
+        c.JSON(http.StatusUnprocessableEntity, gin.H{"valid": false, "error": err.Error()})
+        return
+    case err != nil:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao conferir comprovante"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "valid":     true,
+        "message":   "Comprovante válido",
+        "nsr":       record.NSR,
+        "timestamp": record.Timestamp,
     })
 }
 
@@ -201,195 +325,246 @@ func (h *PointHandler) GetUserPoints(c *gin.Context) {
 		return
 	}
 
-	// Obter registros do dia atual
-	startOfDay := time.Now().Truncate(24 * time.Hour)
-	endOfDay := startOfDay.Add(24 * time.Hour)
-
-	filter := bson.M{
-		"user_id": userID.(primitive.ObjectID),
-		"timestamp": bson.M{
-			"$gte": startOfDay,
-			"$lt":  endOfDay,
-		},
-	}
+	// Obter registros do dia atual, no fuso da empresa
+	now := time.Now().In(h.sheets.Location)
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, h.sheets.Location)
+	endOfDay := startOfDay.AddDate(0, 0, 1)
 
-	cursor, err := h.db.Collection("time_records").Find(context.Background(), filter)
+	records, err := h.store.TimeRecords.FindByUser(c.Request.Context(), currentCompanyID(c), userID.(primitive.ObjectID), startOfDay, endOfDay)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar registros"})
 		return
 	}
-	defer cursor.Close(context.Background())
 
-	var records []models.TimeRecord
-	if err := cursor.All(context.Background(), &records); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao decodificar registros"})
-		return
-	}
-
-	c.JSON(http.StatusOK, records)
+	// As desconsiderações e as marcações desconsideradas ficam de fora
+	c.JSON(http.StatusOK, punch.Effective(records))
 }
 
 func (h *PointHandler) GetMonthlyPoints(c *gin.Context) {
-    userID, exists := c.Get("user_id")
-    if (!exists) {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+    user, ok := h.currentUser(c)
+    if !ok {
         return
     }
 
-    // Pegar ano e mês dos parâmetros da query
-    year, err := strconv.Atoi(c.Query("year"))
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Ano inválido"})
-        return
-    }
+    h.respondMonthlyPoints(c, user)
+}
 
-    month, err := strconv.Atoi(c.Query("month"))
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Mês inválido"})
+// GetTeamMemberMonthlyPoints retorna o relatório mensal de um membro da equipe
+// do gestor autenticado (ou de qualquer usuário, para administradores)
+func (h *PointHandler) GetTeamMemberMonthlyPoints(c *gin.Context) {
+    member, ok := teamMember(c, h.store)
+    if !ok {
         return
     }
 
-    // Calcular início e fim do mês
-    loc, _ := time.LoadLocation("America/Sao_Paulo")
-    startOfMonth := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, loc)
-    endOfMonth := startOfMonth.AddDate(0, 1, 0)
+    h.respondMonthlyPoints(c, member)
+}
 
-    // Buscar registros do mês
-    filter := bson.M{
-        "user_id": userID.(primitive.ObjectID),
-        "timestamp": bson.M{
-            "$gte": startOfMonth,
-            "$lt":  endOfMonth,
-        },
+func (h *PointHandler) respondMonthlyPoints(c *gin.Context, user *models.User) {
+    year, month, ok := parseMonth(c)
+    if !ok {
+        return
     }
 
-    // Ordenar por data
-    opts := options.Find().SetSort(bson.M{"timestamp": 1})
-
-    cursor, err := h.db.Collection("time_records").Find(context.Background(), filter, opts)
+    days, err := h.sheets.Month(c.Request.Context(), user, year, month)
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar registros"})
         return
     }
-    defer cursor.Close(context.Background())
 
-    var records []models.TimeRecord
-    if err := cursor.All(context.Background(), &records); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao decodificar registros"})
-        return
+    // Retornar os dias com marcações, com jornada prevista, feriados ou
+    // ausências, ordenados por data. O dia corrente ainda pode receber
+    // marcações e seus descumprimentos só são apontados depois de encerrado
+    today := h.sheets.Date(time.Now())
+    response := []timesheet.Day{}
+    for _, day := range days {
+        if !day.Time().Before(today) {
+            day.Violations = []timesheet.Violation{}
+        }
+        if len(day.Records) > 0 || day.Expected.Working || day.Holiday != "" || day.Absence != "" {
+            response = append(response, day)
+        }
     }
 
-    // Agrupar registros por dia
-    recordsByDay := make(map[string][]models.TimeRecord)
-    for _, record := range records {
-        day := record.Timestamp.Format("2006-01-02")
-        recordsByDay[day] = append(recordsByDay[day], record)
+    c.JSON(http.StatusOK, response)
+}
+
+// GetMonthlyWeeks retorna o DSR das semanas do usuário autenticado cujo
+// domingo cai no mês informado
+func (h *PointHandler) GetMonthlyWeeks(c *gin.Context) {
+    user, ok := h.currentUser(c)
+    if !ok {
+        return
     }
 
-    // Converter para slice para retorno
-    var response []struct {
-        Date    string              `json:"date"`
-        Records []models.TimeRecord `json:"records"`
+    h.respondMonthlyWeeks(c, user)
+}
+
+// GetTeamMemberMonthlyWeeks retorna o DSR das semanas do mês de um membro da
+// equipe do gestor autenticado
+func (h *PointHandler) GetTeamMemberMonthlyWeeks(c *gin.Context) {
+    member, ok := teamMember(c, h.store)
+    if !ok {
+        return
     }
 
-    for date, dayRecords := range recordsByDay {
-        response = append(response, struct {
-            Date    string              `json:"date"`
-            Records []models.TimeRecord `json:"records"`
-        }{
-            Date:    date,
-            Records: dayRecords,
-        })
+    h.respondMonthlyWeeks(c, member)
+}
+
+func (h *PointHandler) respondMonthlyWeeks(c *gin.Context, user *models.User) {
+    year, month, ok := parseMonth(c)
+    if !ok {
+        return
     }
 
-    // Ordenar por data
-    sort.Slice(response, func(i, j int) bool {
-        return response[i].Date < response[j].Date
-    })
+    weeks, err := h.sheets.MonthWeeks(c.Request.Context(), user, year, month, time.Now())
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao apurar descanso semanal"})
+        return
+    }
 
-    c.JSON(http.StatusOK, response)
+    c.JSON(http.StatusOK, weeks)
 }
 
-func (h *PointHandler) GetStatistics(c *gin.Context) {
-    userID, exists := c.Get("user_id")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+// GetMonthlyReport gera o espelho de ponto do usuário autenticado no mês
+// informado, em PDF
+func (h *PointHandler) GetMonthlyReport(c *gin.Context) {
+    user, ok := h.currentUser(c)
+    if !ok {
         return
     }
 
-    // Obter o primeiro dia do mês atual
-    now := time.Now()
-    startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+    h.respondMonthlyReport(c, user)
+}
 
-    // Buscar registros do mês atual
-    filter := bson.M{
-        "user_id": userID.(primitive.ObjectID),
-        "timestamp": bson.M{
-            "$gte": startOfMonth,
-        },
+// GetTeamMemberMonthlyReport gera o espelho de ponto de um membro da equipe
+// do gestor autenticado (ou de qualquer usuário, para administradores)
+func (h *PointHandler) GetTeamMemberMonthlyReport(c *gin.Context) {
+    member, ok := teamMember(c, h.store)
+    if !ok {
+        return
     }
 
-    cursor, err := h.db.Collection("time_records").Find(context.Background(), filter)
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar registros"})
+    h.respondMonthlyReport(c, member)
+}
+
+func (h *PointHandler) respondMonthlyReport(c *gin.Context, user *models.User) {
+    year, month, ok := parseMonth(c)
+    if !ok {
         return
     }
-    defer cursor.Close(context.Background())
 
-    var records []models.TimeRecord
-    if err := cursor.All(context.Background(), &records); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao decodificar registros"})
+    doc, err := h.espelhos.Build(c.Request.Context(), user, year, month, time.Now())
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar espelho de ponto"})
         return
     }
 
-    // Calcular estatísticas
-    var totalHours float64
-    var daysWorked int
-    var lateDays int
-    daysMap := make(map[string][]models.TimeRecord)
+    name := fmt.Sprintf("espelho-%04d-%02d-%s.pdf", year, month, user.ID.Hex())
+    c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+    c.Data(http.StatusOK, "application/pdf", doc.PDF())
+}
 
-    // Agrupar registros por dia
-    for _, record := range records {
-        day := record.Timestamp.Format("2006-01-02")
-        daysMap[day] = append(daysMap[day], record)
+// parseMonth lê o ano e o mês dos parâmetros ?year e ?month. Em caso de falha
+// a resposta de erro já é enviada e ok é falso
+func parseMonth(c *gin.Context) (year, month int, ok bool) {
+    year, err := strconv.Atoi(c.Query("year"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Ano inválido"})
+        return year, month, false
     }
 
-    // Analisar cada dia
-    for _, dayRecords := range daysMap {
-        if len(dayRecords) > 0 {
-            daysWorked++
+    month, err = strconv.Atoi(c.Query("month"))
+    if err != nil || month < 1 || month > 12 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Mês inválido"})
+        return year, month, false
+    }
+    return year, month, true
+}
 
-            // Verificar primeira entrada do dia
-            firstEntry := dayRecords[0]
-            if firstEntry.Type == "entrada" && firstEntry.Timestamp.Hour() >= 9 { // considerando 9h como hora limite
-                lateDays++
-            }
+func (h *PointHandler) GetStatistics(c *gin.Context) {
+    user, ok := h.currentUser(c)
+    if !ok {
+        return
+    }
 
-            // Calcular horas trabalhadas
-            var entryTime *time.Time
-            for _, record := range dayRecords {
-                if record.Type == "entrada" {
-                    entryTime = &record.Timestamp
-                } else if record.Type == "saída" && entryTime != nil {
-                    duration := record.Timestamp.Sub(*entryTime)
-                    totalHours += duration.Hours()
-                    entryTime = nil
-                }
-            }
+    // Por padrão, estatísticas do mês atual
+    now := time.Now().In(h.sheets.Location)
+    year, month := now.Year(), int(now.Month())
+    if c.Query("year") != "" || c.Query("month") != "" {
+        var err error
+        if year, err = strconv.Atoi(c.Query("year")); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Ano inválido"})
+            return
+        }
+        if month, err = strconv.Atoi(c.Query("month")); err != nil || month < 1 || month > 12 {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Mês inválido"})
+            return
         }
     }
 
+    days, err := h.sheets.Month(c.Request.Context(), user, year, month)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar registros"})
+        return
+    }
+
+    // Calcular estatísticas contra a jornada prevista, até o dia de hoje. Atrasos
+    // e saldo consideram a tolerância de marcação; os valores brutos também são
+    // retornados
+    totals := timesheet.Summarize(days, now)
+    totalHours := float64(totals.WorkedMinutes) / 60
+
     // Calcular médias
     averageHoursPerDay := 0.0
-    if daysWorked > 0 {
-        averageHoursPerDay = totalHours / float64(daysWorked)
+    if totals.DaysWorked > 0 {
+        averageHoursPerDay = totalHours / float64(totals.DaysWorked)
+    }
+
+    // Previsto e trabalhado de cada dia já transcorrido
+    daily := []gin.H{}
+    for _, day := range days {
+        if day.Time().After(now) {
+            break
+        }
+        daily = append(daily, gin.H{
+            "date":                     day.Date,
+            "expected_minutes":         day.ExpectedMinutes,
+            "worked_minutes":           day.WorkedMinutes,
+            "balance_minutes":          day.BalanceMinutes,
+            "adjusted_worked_minutes":  day.AdjustedWorkedMinutes,
+            "adjusted_balance_minutes": day.AdjustedBalanceMinutes,
+            "overtime_minutes":         day.OvertimeMinutes,
+            "overtime":                 day.Overtime,
+            "night_minutes":            day.NightMinutes,
+            "night_paid_minutes":       day.NightPaidMinutes,
+            "rest_day":                 day.RestDay,
+            "holiday":                  day.Holiday,
+            "absence":                  day.Absence,
+            "abono_minutes":            day.AbonoMinutes,
+        })
     }
 
     c.JSON(http.StatusOK, gin.H{
         "total_hours":          totalHours,
-        "days_worked":          daysWorked,
-        "late_days":           lateDays,
+        "days_worked":          totals.DaysWorked,
+        "late_days":           totals.AdjustedLateDays,
         "average_hours_per_day": averageHoursPerDay,
-        "current_month":        now.Format("January 2006"),
+        "expected_hours":       float64(totals.ExpectedMinutes) / 60,
+        "balance_hours":        float64(totals.AdjustedBalanceMinutes) / 60,
+        "adjusted_hours":       float64(totals.AdjustedWorkedMinutes) / 60,
+        "raw_late_days":        totals.LateDays,
+        "raw_balance_hours":    float64(totals.BalanceMinutes) / 60,
+        "overtime_hours":       float64(totals.OvertimeMinutes) / 60,
+        "overtime":             totals.Overtime,
+        "overtime_excess_minutes": totals.OvertimeExcessMinutes,
+        "overtime_excess_days": totals.OvertimeExcessDays,
+        "night_hours":          float64(totals.NightMinutes) / 60,
+        "night_paid_hours":     float64(totals.NightPaidMinutes) / 60,
+        "abono_hours":          float64(totals.AbonoMinutes) / 60,
+        "abono_days":           totals.AbonoDays,
+        "missed_days":          totals.MissedDays,
+        "current_month":        time.Date(year, time.Month(month), 1, 0, 0, 0, 0, h.sheets.Location).Format("January 2006"),
+        "days":                 daily,
     })
-}
\ No newline at end of file
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/pin"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/signature"
+	"ponto-digital-api/internal/timesheet"
+)
+
+// newPointRouter monta as rotas de marcação sobre um repositório em memória,
+// autenticadas como um funcionário com o PIN 2580.
+func newPointRouter(t *testing.T) (*gin.Engine, *repository.Store, *models.User) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	store := repository.NewMemoryStore()
+	company := models.Company{Name: "ACME", CNPJ: "11222333000181"}
+	if err := store.Companies.Create(ctx, &company); err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{Name: "Ana", CompanyID: company.ID, Role: models.RoleEmployee}
+	if err := pin.Set(&user, "2580", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Users.Create(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := signature.NewSelfSigned("Ponto Digital")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := NewPointHandler(store, signer, "", nil)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", user.ID)
+		c.Set("company_id", company.ID)
+		c.Set("role", user.Role)
+	})
+	router.POST("/register-point", handler.RegisterPoint)
+	router.GET("/points/today", handler.GetUserPoints)
+	return router, store, &user
+}
+
+func serve(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return w
+}
+
+func TestRegisterPointSequence(t *testing.T) {
+	router, _, _ := newPointRouter(t)
+
+	steps := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantType models.PunchType
+		wantNSR  int64
+	}{
+		{name: "entrada definida pelo servidor", body: `{"pin":"2580","authMethod":"pin"}`, wantCode: http.StatusCreated, wantType: models.PunchEntrada, wantNSR: 1},
+		{name: "entrada repetida", body: `{"pin":"2580","authMethod":"pin","type":"entrada"}`, wantCode: http.StatusConflict},
+		{name: "tipo desconhecido", body: `{"pin":"2580","authMethod":"pin","type":"pausa"}`, wantCode: http.StatusBadRequest},
+		{name: "saída", body: `{"pin":"2580","authMethod":"pin","type":"saída"}`, wantCode: http.StatusCreated, wantType: models.PunchSaida, wantNSR: 2},
+		{name: "PIN incorreto", body: `{"pin":"1357","authMethod":"pin"}`, wantCode: http.StatusUnauthorized},
+		{name: "nova entrada", body: `{"pin":"2580","authMethod":"pin"}`, wantCode: http.StatusCreated, wantType: models.PunchEntrada, wantNSR: 3},
+	}
+
+	// Os passos dependem da sequência gravada pelos anteriores
+	for _, step := range steps {
+		w := serve(router, http.MethodPost, "/register-point", step.body)
+		if w.Code != step.wantCode {
+			t.Fatalf("%s: status = %d, want %d: %s", step.name, w.Code, step.wantCode, w.Body.String())
+		}
+		if step.wantCode != http.StatusCreated {
+			continue
+		}
+
+		var response struct {
+			Type models.PunchType `json:"type"`
+			NSR  int64            `json:"nsr"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		if response.Type != step.wantType || response.NSR != step.wantNSR {
+			t.Errorf("%s: type = %q, nsr = %d, want %q, %d", step.name, response.Type, response.NSR, step.wantType, step.wantNSR)
+		}
+	}
+}
+
+func TestRegisterPointConcurrentEntries(t *testing.T) {
+	router, store, user := newPointRouter(t)
+	const requests = 10
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	codes := map[int]int{}
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := serve(router, http.MethodPost, "/register-point", `{"pin":"2580","authMethod":"pin","type":"entrada"}`)
+			mu.Lock()
+			codes[w.Code]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	// Apenas uma das entradas simultâneas é aceita
+	if codes[http.StatusCreated] != 1 || codes[http.StatusConflict] != requests-1 {
+		t.Errorf("status codes = %v, want one %d and %d %d", codes, http.StatusCreated, requests-1, http.StatusConflict)
+	}
+
+	now := time.Now()
+	records, err := store.TimeRecords.FindByUser(context.Background(), user.CompanyID, user.ID, now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Type != models.PunchEntrada {
+		t.Errorf("records = %+v, want a single entrada", records)
+	}
+}
+
+func TestRegisterPointConcurrentPinFailures(t *testing.T) {
+	router, store, user := newPointRouter(t)
+	const requests = 20
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	codes := map[int]int{}
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := serve(router, http.MethodPost, "/register-point", `{"pin":"1357","authMethod":"pin"}`)
+			mu.Lock()
+			codes[w.Code]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	// Cada tentativa errada é contada uma única vez: as primeiras
+	// MaxAttempts-1 são recusadas e a seguinte bloqueia o PIN
+	want := map[int]int{
+		http.StatusUnauthorized:    pin.MaxAttempts - 1,
+		http.StatusTooManyRequests: requests - pin.MaxAttempts + 1,
+	}
+	if len(codes) != len(want) || codes[http.StatusUnauthorized] != want[http.StatusUnauthorized] || codes[http.StatusTooManyRequests] != want[http.StatusTooManyRequests] {
+		t.Errorf("status codes = %v, want %v", codes, want)
+	}
+
+	ctx := context.Background()
+	stored, err := store.Users.FindByID(ctx, user.CompanyID, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.PinFailedAttempts != pin.MaxAttempts || stored.PinLockedUntil == nil {
+		t.Fatalf("PinFailedAttempts = %d, PinLockedUntil = %v, want %d and a lock", stored.PinFailedAttempts, stored.PinLockedUntil, pin.MaxAttempts)
+	}
+
+	if w := serve(router, http.MethodPost, "/register-point", `{"pin":"2580","authMethod":"pin"}`); w.Code != http.StatusTooManyRequests {
+		t.Errorf("correct PIN while locked: status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+
+	expired := time.Now().Add(-time.Minute)
+	stored.PinLockedUntil = &expired
+	if err := store.Users.UpdatePin(ctx, stored); err != nil {
+		t.Fatal(err)
+	}
+	if w := serve(router, http.MethodPost, "/register-point", `{"pin":"2580","authMethod":"pin"}`); w.Code != http.StatusCreated {
+		t.Fatalf("correct PIN after the lock: status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	stored, err = store.Users.FindByID(ctx, user.CompanyID, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.PinFailedAttempts != 0 || stored.PinLockedUntil != nil {
+		t.Errorf("PinFailedAttempts = %d, PinLockedUntil = %v, want the counter reset", stored.PinFailedAttempts, stored.PinLockedUntil)
+	}
+}
+
+func TestGetUserPointsEffective(t *testing.T) {
+	router, store, user := newPointRouter(t)
+	ctx := context.Background()
+
+	// Horários fixos no início do dia local, sempre dentro da consulta
+	loc := timesheet.NewService(store).Location
+	now := time.Now().In(loc)
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	create := func(record models.TimeRecord) models.TimeRecord {
+		record.CompanyID = user.CompanyID
+		record.UserID = user.ID
+		if err := store.TimeRecords.Create(ctx, &record); err != nil {
+			t.Fatal(err)
+		}
+		return record
+	}
+	kept := create(models.TimeRecord{Type: models.PunchEntrada, Timestamp: startOfDay.Add(time.Minute)})
+	disregarded := create(models.TimeRecord{Type: models.PunchSaida, Timestamp: startOfDay.Add(2 * time.Minute)})
+	create(models.TimeRecord{Origin: models.OriginDisregarded, TargetID: disregarded.ID, Timestamp: startOfDay.Add(3 * time.Minute)})
+	create(models.TimeRecord{Type: models.PunchEntrada, Timestamp: startOfDay.Add(-time.Minute)})
+
+	w := serve(router, http.MethodGet, "/points/today", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var records []struct {
+		ID primitive.ObjectID `json:"id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &records); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].ID != kept.ID {
+		t.Errorf("records = %+v, want only %s", records, kept.ID.Hex())
+	}
+}
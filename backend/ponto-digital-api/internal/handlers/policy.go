@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/timesheet"
+)
+
+// PolicyRequest altera as regras trabalhistas. Grupos de regras omitidos são
+// mantidos.
+type PolicyRequest struct {
+	Tolerance *ToleranceRequest `json:"tolerance"`
+	Overtime  *OvertimeRequest  `json:"overtime"`
+	Night     *NightRequest     `json:"night"`
+	HourBank  *HourBankRequest  `json:"hourBank"`
+	Holidays  *HolidaysRequest  `json:"holidays"`
+}
+
+type ToleranceRequest struct {
+	PerPunchMinutes int `json:"perPunchMinutes" binding:"min=0,max=60"`
+	PerDayMinutes   int `json:"perDayMinutes" binding:"min=0,max=120"`
+}
+
+type OvertimeRequest struct {
+	Bands             []OvertimeBandRequest `json:"bands" binding:"required,min=1,dive"`
+	RestDayRate       int                   `json:"restDayRate" binding:"required"`
+	DailyLimitMinutes int                   `json:"dailyLimitMinutes" binding:"min=0"`
+}
+
+type OvertimeBandRequest struct {
+	UpToMinutes int `json:"upToMinutes" binding:"min=0"` // ignorado na última faixa
+	Rate        int `json:"rate" binding:"required"`
+}
+
+type NightRequest struct {
+	Start          string `json:"start" binding:"required"` // HH:MM
+	End            string `json:"end" binding:"required"`
+	Rate           int    `json:"rate" binding:"min=0"`
+	ReducedHour    bool   `json:"reducedHour"`
+	ExtendAfterEnd bool   `json:"extendAfterEnd"`
+}
+
+type HourBankRequest struct {
+	ExpirationMonths int `json:"expirationMonths" binding:"oneof=6 12"`
+}
+
+type HolidaysRequest struct {
+	WorkOnOptional bool `json:"workOnOptional"`
+}
+
+type AgreementRequest struct {
+	Name string `json:"name" binding:"required"`
+	PolicyRequest
+}
+
+// GetPolicy retorna as regras trabalhistas em vigor na empresa
+func (h *CompanyHandler) GetPolicy(c *gin.Context) {
+	company, ok := h.currentCompany(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, company.EffectivePolicy())
+}
+
+// UpdatePolicy altera as regras trabalhistas da empresa
+func (h *CompanyHandler) UpdatePolicy(c *gin.Context) {
+	var req PolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	company, ok := h.currentCompany(c)
+	if !ok {
+		return
+	}
+
+	policy := company.EffectivePolicy()
+	if err := applyPolicyRequest(&policy, req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	company.Policy = &policy
+	company.UpdatedAt = time.Now()
+	if err := h.store.Companies.Update(c.Request.Context(), company); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar empresa"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// ListAgreements retorna as convenções coletivas da empresa
+func (h *CompanyHandler) ListAgreements(c *gin.Context) {
+	agreements, err := h.store.Agreements.ListByCompany(c.Request.Context(), currentCompanyID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar convenções"})
+		return
+	}
+
+	c.JSON(http.StatusOK, agreements)
+}
+
+// CreateAgreement cadastra uma convenção coletiva. As regras omitidas partem
+// das regras da empresa.
+func (h *CompanyHandler) CreateAgreement(c *gin.Context) {
+	var req AgreementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	company, ok := h.currentCompany(c)
+	if !ok {
+		return
+	}
+
+	agreement := models.CollectiveAgreement{
+		CompanyID: company.ID,
+		Name:      req.Name,
+		Policy:    company.EffectivePolicy(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := applyPolicyRequest(&agreement.Policy, req.PolicyRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.store.Agreements.Create(c.Request.Context(), &agreement); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao cadastrar convenção"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, agreement)
+}
+
+// UpdateAgreement altera o nome e as regras de uma convenção coletiva
+func (h *CompanyHandler) UpdateAgreement(c *gin.Context) {
+	var req AgreementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	agreementID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Convenção inválida"})
+		return
+	}
+
+	agreement, err := h.store.Agreements.FindByID(c.Request.Context(), currentCompanyID(c), agreementID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Convenção não encontrada"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar convenção"})
+		return
+	}
+
+	policy := agreement.EffectivePolicy()
+	if err := applyPolicyRequest(&policy, req.PolicyRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	agreement.Name = req.Name
+	agreement.Policy = policy
+	agreement.UpdatedAt = time.Now()
+	if err := h.store.Agreements.Update(c.Request.Context(), agreement); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar convenção"})
+		return
+	}
+
+	c.JSON(http.StatusOK, agreement)
+}
+
+// applyPolicyRequest valida a requisição e copia as regras informadas para a
+// política
+func applyPolicyRequest(policy *models.LaborPolicy, req PolicyRequest) error {
+	updated := *policy
+
+	if req.Tolerance != nil {
+		if req.Tolerance.PerDayMinutes < req.Tolerance.PerPunchMinutes {
+			return errors.New("A tolerância diária não pode ser menor que a tolerância por marcação")
+		}
+		updated.Tolerance = models.TolerancePolicy{
+			PerPunchMinutes: req.Tolerance.PerPunchMinutes,
+			PerDayMinutes:   req.Tolerance.PerDayMinutes,
+		}
+	}
+
+	if req.Overtime != nil {
+		bands := make([]models.OvertimeBand, 0, len(req.Overtime.Bands))
+		for _, band := range req.Overtime.Bands {
+			bands = append(bands, models.OvertimeBand{UpToMinutes: band.UpToMinutes, Rate: band.Rate})
+		}
+		updated.Overtime = models.OvertimePolicy{
+			Bands:             bands,
+			RestDayRate:       req.Overtime.RestDayRate,
+			DailyLimitMinutes: req.Overtime.DailyLimitMinutes,
+		}
+		if err := timesheet.ValidateOvertime(updated.Overtime); err != nil {
+			return err
+		}
+	}
+
+	if req.Night != nil {
+		updated.Night = models.NightPolicy{
+			Start:          req.Night.Start,
+			End:            req.Night.End,
+			Rate:           req.Night.Rate,
+			ReducedHour:    req.Night.ReducedHour,
+			ExtendAfterEnd: req.Night.ExtendAfterEnd,
+		}
+		if err := timesheet.ValidateNight(updated.Night); err != nil {
+			return err
+		}
+	}
+
+	if req.HourBank != nil {
+		updated.HourBank = models.HourBankPolicy{ExpirationMonths: req.HourBank.ExpirationMonths}
+	}
+
+	if req.Holidays != nil {
+		updated.Holidays = models.HolidayPolicy{WorkOnOptional: req.Holidays.WorkOnOptional}
+	}
+
+	*policy = updated
+	return nil
+}
@@ -0,0 +1,566 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/timesheet"
+)
+
+type ScheduleHandler struct {
+	store  *repository.Store
+	sheets *timesheet.Service
+}
+
+func NewScheduleHandler(store *repository.Store) *ScheduleHandler {
+	return &ScheduleHandler{store: store, sheets: timesheet.NewService(store)}
+}
+
+type ScheduleRequest struct {
+	Name string               `json:"name" binding:"required"`
+	Days []ScheduleDayRequest `json:"days" binding:"dive"`
+}
+
+type ScheduleDayRequest struct {
+	Weekday    time.Weekday `json:"weekday" binding:"min=0,max=6"` // 0 = domingo
+	Entry      string       `json:"entry" binding:"required"`
+	Exit       string       `json:"exit" binding:"required"`
+	BreakStart string       `json:"breakStart"`
+	BreakEnd   string       `json:"breakEnd"`
+}
+
+type GroupRequest struct {
+	Name       string `json:"name" binding:"required"`
+	ScheduleID string `json:"scheduleId"`
+}
+
+// ShiftRequest descreve uma escala de revezamento. Nas escalas 12x36, 6x1 e
+// 5x2 os horários de entry a breakEnd valem para todos os dias de trabalho; na
+// escala custom cada dia do ciclo é informado em cycle.
+type ShiftRequest struct {
+	Name       string            `json:"name" binding:"required"`
+	Kind       models.ShiftKind  `json:"kind" binding:"required"`
+	AnchorDate string            `json:"anchorDate" binding:"required"` // AAAA-MM-DD
+	Entry      string            `json:"entry"`
+	Exit       string            `json:"exit"`
+	BreakStart string            `json:"breakStart"`
+	BreakEnd   string            `json:"breakEnd"`
+	Cycle      []ShiftDayRequest `json:"cycle"`
+}
+
+type ShiftDayRequest struct {
+	Working    bool   `json:"working"`
+	Entry      string `json:"entry"`
+	Exit       string `json:"exit"`
+	BreakStart string `json:"breakStart"`
+	BreakEnd   string `json:"breakEnd"`
+}
+
+type AssignScheduleRequest struct {
+	ScheduleID  string `json:"scheduleId"`
+	GroupID     string `json:"groupId"`
+	ShiftID     string `json:"shiftId"`
+	ShiftAnchor string `json:"shiftAnchor"` // AAAA-MM-DD
+}
+
+// GetMySchedule retorna a jornada efetiva do usuário autenticado
+func (h *ScheduleHandler) GetMySchedule(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	user, err := h.store.Users.FindByID(c.Request.Context(), currentCompanyID(c), userID.(primitive.ObjectID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar usuário"})
+		return
+	}
+
+	schedule, err := h.sheets.ScheduleFor(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar jornada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// GetMyExpectedDays retorna, para cada data entre from e to (AAAA-MM-DD), se o
+// usuário autenticado deve trabalhar e em quais horários
+func (h *ScheduleHandler) GetMyExpectedDays(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	from, err := time.ParseInLocation("2006-01-02", c.Query("from"), h.sheets.Location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Data inicial inválida"})
+		return
+	}
+	to, err := time.ParseInLocation("2006-01-02", c.Query("to"), h.sheets.Location)
+	if err != nil || to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Data final inválida"})
+		return
+	}
+	if to.Sub(from) > 366*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "O período deve ter no máximo um ano"})
+		return
+	}
+
+	user, err := h.store.Users.FindByID(c.Request.Context(), currentCompanyID(c), userID.(primitive.ObjectID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar usuário"})
+		return
+	}
+
+	plan, err := h.sheets.PlanBetween(c.Request.Context(), user, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar jornada"})
+		return
+	}
+
+	days := []gin.H{}
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		expected := plan.Expect(date)
+		day := gin.H{
+			"date":    date.Format("2006-01-02"),
+			"working": expected.Working,
+		}
+		if expected.Holiday != "" {
+			day["holiday"] = expected.Holiday
+		}
+		if expected.Absence != "" {
+			day["absence"] = expected.Absence
+		}
+		if expected.Working {
+			day["entry"] = expected.Entry
+			day["exit"] = expected.Exit
+			day["expected_minutes"] = expected.Minutes
+		}
+		days = append(days, day)
+	}
+
+	c.JSON(http.StatusOK, days)
+}
+
+// ListSchedules retorna as jornadas cadastradas na empresa
+func (h *ScheduleHandler) ListSchedules(c *gin.Context) {
+	schedules, err := h.store.Schedules.ListByCompany(c.Request.Context(), currentCompanyID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar jornadas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+// CreateSchedule cadastra uma jornada semanal
+func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule := models.WorkSchedule{
+		CompanyID: currentCompanyID(c),
+		CreatedAt: time.Now(),
+	}
+	if err := applyScheduleRequest(&schedule, req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.store.Schedules.Create(c.Request.Context(), &schedule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao cadastrar jornada"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// UpdateSchedule altera uma jornada da empresa
+func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scheduleID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Jornada inválida"})
+		return
+	}
+
+	schedule, err := h.store.Schedules.FindByID(c.Request.Context(), currentCompanyID(c), scheduleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Jornada não encontrada"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar jornada"})
+		return
+	}
+
+	if err := applyScheduleRequest(schedule, req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.store.Schedules.Update(c.Request.Context(), schedule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar jornada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// applyScheduleRequest copia os dias da requisição para a jornada, validando
+// os horários e calculando os minutos previstos de cada dia
+func applyScheduleRequest(schedule *models.WorkSchedule, req ScheduleRequest) error {
+	days := make([]models.ScheduleDay, 0, len(req.Days))
+	for _, day := range req.Days {
+		days = append(days, models.ScheduleDay{
+			Weekday: day.Weekday,
+			WorkHours: models.WorkHours{
+				Entry:      day.Entry,
+				Exit:       day.Exit,
+				BreakStart: day.BreakStart,
+				BreakEnd:   day.BreakEnd,
+			},
+		})
+	}
+
+	updated := *schedule
+	updated.Name = req.Name
+	updated.Days = days
+	if err := timesheet.NormalizeSchedule(&updated); err != nil {
+		return err
+	}
+
+	updated.UpdatedAt = time.Now()
+	*schedule = updated
+	return nil
+}
+
+// ListShifts retorna as escalas de revezamento cadastradas na empresa
+func (h *ScheduleHandler) ListShifts(c *gin.Context) {
+	shifts, err := h.store.Shifts.ListByCompany(c.Request.Context(), currentCompanyID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar escalas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, shifts)
+}
+
+// CreateShift cadastra uma escala de revezamento
+func (h *ScheduleHandler) CreateShift(c *gin.Context) {
+	var req ShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	shift := models.ShiftPattern{
+		CompanyID: currentCompanyID(c),
+		CreatedAt: time.Now(),
+	}
+	if err := h.applyShiftRequest(&shift, req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.store.Shifts.Create(c.Request.Context(), &shift); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao cadastrar escala"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, shift)
+}
+
+// UpdateShift altera uma escala de revezamento da empresa
+func (h *ScheduleHandler) UpdateShift(c *gin.Context) {
+	var req ShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	shiftID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Escala inválida"})
+		return
+	}
+
+	shift, err := h.store.Shifts.FindByID(c.Request.Context(), currentCompanyID(c), shiftID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Escala não encontrada"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar escala"})
+		return
+	}
+
+	if err := h.applyShiftRequest(shift, req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.store.Shifts.Update(c.Request.Context(), shift); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar escala"})
+		return
+	}
+
+	c.JSON(http.StatusOK, shift)
+}
+
+// applyShiftRequest copia a requisição para a escala, montando e validando o
+// ciclo
+func (h *ScheduleHandler) applyShiftRequest(shift *models.ShiftPattern, req ShiftRequest) error {
+	anchor, err := time.ParseInLocation("2006-01-02", req.AnchorDate, h.sheets.Location)
+	if err != nil {
+		return errors.New("Data de início da escala inválida")
+	}
+
+	cycle := make([]models.ShiftDay, 0, len(req.Cycle))
+	for _, day := range req.Cycle {
+		cycle = append(cycle, models.ShiftDay{
+			Working: day.Working,
+			WorkHours: models.WorkHours{
+				Entry:      day.Entry,
+				Exit:       day.Exit,
+				BreakStart: day.BreakStart,
+				BreakEnd:   day.BreakEnd,
+			},
+		})
+	}
+
+	updated := *shift
+	updated.Name = req.Name
+	updated.Kind = req.Kind
+	updated.AnchorDate = anchor
+	updated.Cycle = cycle
+	hours := models.WorkHours{
+		Entry:      req.Entry,
+		Exit:       req.Exit,
+		BreakStart: req.BreakStart,
+		BreakEnd:   req.BreakEnd,
+	}
+	if err := timesheet.NormalizeShift(&updated, hours); err != nil {
+		return err
+	}
+
+	updated.UpdatedAt = time.Now()
+	*shift = updated
+	return nil
+}
+
+// ListGroups retorna os grupos de jornada da empresa
+func (h *ScheduleHandler) ListGroups(c *gin.Context) {
+	groups, err := h.store.Groups.ListByCompany(c.Request.Context(), currentCompanyID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar grupos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// CreateGroup cadastra um grupo de jornada
+func (h *ScheduleHandler) CreateGroup(c *gin.Context) {
+	var req GroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	companyID := currentCompanyID(c)
+	scheduleID, ok := h.parseSchedule(c, companyID, req.ScheduleID)
+	if !ok {
+		return
+	}
+
+	group := models.WorkGroup{
+		CompanyID:  companyID,
+		Name:       req.Name,
+		ScheduleID: scheduleID,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	if err := h.store.Groups.Create(c.Request.Context(), &group); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao cadastrar grupo"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, group)
+}
+
+// UpdateGroup altera o nome e a jornada de um grupo
+func (h *ScheduleHandler) UpdateGroup(c *gin.Context) {
+	var req GroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Grupo inválido"})
+		return
+	}
+
+	companyID := currentCompanyID(c)
+	group, err := h.store.Groups.FindByID(c.Request.Context(), companyID, groupID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Grupo não encontrado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar grupo"})
+		return
+	}
+
+	scheduleID, ok := h.parseSchedule(c, companyID, req.ScheduleID)
+	if !ok {
+		return
+	}
+
+	group.Name = req.Name
+	group.ScheduleID = scheduleID
+	group.UpdatedAt = time.Now()
+	if err := h.store.Groups.Update(c.Request.Context(), group); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar grupo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// AssignSchedule define a jornada própria, o grupo e a escala de revezamento
+// de um usuário. Campos vazios removem a atribuição.
+func (h *ScheduleHandler) AssignSchedule(c *gin.Context) {
+	var req AssignScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	targetID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Usuário inválido"})
+		return
+	}
+
+	companyID := currentCompanyID(c)
+	user, err := h.store.Users.FindByID(c.Request.Context(), companyID, targetID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Usuário não encontrado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar usuário"})
+		return
+	}
+
+	scheduleID, ok := h.parseSchedule(c, companyID, req.ScheduleID)
+	if !ok {
+		return
+	}
+
+	var groupID primitive.ObjectID
+	if req.GroupID != "" {
+		groupID, err = primitive.ObjectIDFromHex(req.GroupID)
+		if err == nil {
+			_, err = h.store.Groups.FindByID(c.Request.Context(), companyID, groupID)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Grupo inválido"})
+			return
+		}
+	}
+
+	var shiftID primitive.ObjectID
+	var shift *models.ShiftPattern
+	if req.ShiftID != "" {
+		shiftID, err = primitive.ObjectIDFromHex(req.ShiftID)
+		if err == nil {
+			shift, err = h.store.Shifts.FindByID(c.Request.Context(), companyID, shiftID)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Escala inválida"})
+			return
+		}
+	}
+
+	var shiftAnchor time.Time
+	if req.ShiftAnchor != "" {
+		shiftAnchor, err = time.ParseInLocation("2006-01-02", req.ShiftAnchor, h.sheets.Location)
+		if err != nil || shiftID.IsZero() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Data de início da escala inválida"})
+			return
+		}
+	}
+
+	user.ScheduleID = scheduleID
+	user.GroupID = groupID
+	user.ShiftID = shiftID
+	user.ShiftAnchor = shiftAnchor
+	user.UpdatedAt = time.Now()
+	if err := h.store.Users.UpdateSchedule(c.Request.Context(), user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar usuário"})
+		return
+	}
+
+	schedule, err := h.sheets.ScheduleFor(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar jornada"})
+		return
+	}
+
+	response := gin.H{
+		"user":     userResponse(user),
+		"schedule": schedule,
+	}
+	if shift != nil {
+		// A escala de revezamento tem precedência sobre a jornada semanal
+		response["shift"] = shift
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// parseSchedule valida que a jornada informada pertence à empresa. Um valor
+// vazio retorna o identificador nulo. Em caso de falha a resposta de erro já é
+// enviada e ok é falso.
+func (h *ScheduleHandler) parseSchedule(c *gin.Context, companyID primitive.ObjectID, value string) (primitive.ObjectID, bool) {
+	if value == "" {
+		return primitive.NilObjectID, true
+	}
+
+	scheduleID, err := primitive.ObjectIDFromHex(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Jornada inválida"})
+		return primitive.NilObjectID, false
+	}
+
+	if _, err := h.store.Schedules.FindByID(c.Request.Context(), companyID, scheduleID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Jornada inválida"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar jornada"})
+		}
+		return primitive.NilObjectID, false
+	}
+
+	return scheduleID, true
+}
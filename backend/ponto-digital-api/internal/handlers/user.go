@@ -1,24 +1,29 @@
 package handlers
 
 import (
-	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 	"log"
 	"net/http"
+	"ponto-digital-api/internal/afd"
 	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/pin"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/session"
+	"ponto-digital-api/internal/utils"
 	"time"
 )
 
 type UserHandler struct {
-	db *mongo.Database
+	store    *repository.Store
+	sessions *session.Service
+	rep      *afd.Registry
 }
 
-func NewUserHandler(db *mongo.Database) *UserHandler {
-	return &UserHandler{db: db}
+func NewUserHandler(store *repository.Store) *UserHandler {
+	return &UserHandler{store: store, sessions: session.NewService(store), rep: afd.NewRegistry(store)}
 }
 
 type UpdateProfileRequest struct {
@@ -27,8 +32,32 @@ type UpdateProfileRequest struct {
 	NewPassword     string `json:"newPassword,omitempty"`
 }
 
+type CreateUserRequest struct {
+	Name     string      `json:"name" binding:"required"`
+	Email    string      `json:"email" binding:"required,email"`
+	Password string      `json:"password" binding:"required,min=6"`
+	Role     models.Role `json:"role"`
+	BranchID string      `json:"branchId"`
+	CPF      string      `json:"cpf"`
+}
+
+type UpdateBranchRequest struct {
+	BranchID string `json:"branchId"`
+}
+
+type UpdateCPFRequest struct {
+	CPF string `json:"cpf" binding:"required"`
+}
+
+type UpdateRoleRequest struct {
+	Role      models.Role `json:"role" binding:"required"`
+	ManagerID string      `json:"managerId"`
+}
+
 type SetupPinRequest struct {
-    Pin string `json:"pin" binding:"required,min=4,max=6"`
+    Pin        string `json:"pin" binding:"required,min=4,max=6"`
+    CurrentPin string `json:"currentPin"` // exigido na troca, ou a senha
+    Password   string `json:"password"`
 }
 
 func (h *UserHandler) UpdateProfile(c *gin.Context) {
@@ -45,24 +74,12 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	}
 
 	// Buscar usuário atual
-	var user models.User
-	err := h.db.Collection("users").FindOne(
-		context.Background(),
-		bson.M{"_id": userID.(primitive.ObjectID)},
-	).Decode(&user)
+	user, err := h.store.Users.FindByID(c.Request.Context(), currentCompanyID(c), userID.(primitive.ObjectID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar usuário"})
 		return
 	}
 
-	// Preparar atualização
-	update := bson.M{
-		"$set": bson.M{
-			"name":       req.Name,
-			"updated_at": time.Now(),
-		},
-	}
-
 	// Se forneceu senha, validar e atualizar
 	if req.NewPassword != "" {
 		// Verificar senha atual
@@ -84,23 +101,26 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 			return
 		}
 
-		update["$set"].(bson.M)["password"] = string(hashedPassword)
+		user.Password = string(hashedPassword)
 	}
 
 	// Atualizar usuário
-	result, err := h.db.Collection("users").UpdateOne(
-		context.Background(),
-		bson.M{"_id": userID.(primitive.ObjectID)},
-		update,
-	)
-	if err != nil {
+	before := *user
+	user.Name = req.Name
+	user.UpdatedAt = time.Now()
+	if err := h.store.Users.UpdateProfile(c.Request.Context(), user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar perfil"})
 		return
 	}
+	logRepEvent(h.rep.Employee(c.Request.Context(), &before, user, user.ID, user.UpdatedAt))
 
-	if result.ModifiedCount == 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Nenhuma alteração realizada"})
-		return
+	// A troca de senha encerra as sessões dos demais aparelhos
+	if req.NewPassword != "" {
+		if err := h.sessions.RevokeAll(c.Request.Context(), user, currentSessionID(c), time.Now()); err != nil {
+			log.Printf("Erro ao encerrar sessões do usuário %s: %v", user.ID.Hex(), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Senha alterada, mas houve erro ao encerrar as demais sessões"})
+			return
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -120,64 +140,370 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	var user models.User
-	err := h.db.Collection("users").FindOne(
-		context.Background(),
-		bson.M{"_id": userID.(primitive.ObjectID)},
-	).Decode(&user)
+	user, err := h.store.Users.FindByID(c.Request.Context(), currentCompanyID(c), userID.(primitive.ObjectID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar usuário"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, userResponse(user))
+}
+
+// ListTeam retorna os membros da equipe do gestor autenticado
+func (h *UserHandler) ListTeam(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	h.respondUsers(c, repository.UserFilter{
+		CompanyID: currentCompanyID(c),
+		ManagerID: userID.(primitive.ObjectID),
+	})
+}
+
+// ListUsers retorna todos os usuários, opcionalmente filtrados por perfil
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	role := models.Role(c.Query("role"))
+	if role != "" && !role.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Perfil inválido"})
+		return
+	}
+
+	h.respondUsers(c, repository.UserFilter{CompanyID: currentCompanyID(c), Role: role})
+}
+
+func (h *UserHandler) respondUsers(c *gin.Context, filter repository.UserFilter) {
+	users, err := h.store.Users.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar usuários"})
+		return
+	}
+
+	response := make([]gin.H, 0, len(users))
+	for i := range users {
+		response = append(response, userResponse(&users[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateUserRole altera o perfil de um usuário e o gestor da sua equipe
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !req.Role.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Perfil inválido"})
+		return
+	}
+
+	targetID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Usuário inválido"})
+		return
+	}
+
+	user, err := h.store.Users.FindByID(c.Request.Context(), currentCompanyID(c), targetID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Usuário não encontrado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar usuário"})
+		return
+	}
+
+	var managerID primitive.ObjectID
+	if req.ManagerID != "" {
+		managerID, err = primitive.ObjectIDFromHex(req.ManagerID)
+		if err != nil || managerID == user.ID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Gestor inválido"})
+			return
+		}
+
+		manager, err := h.store.Users.FindByID(c.Request.Context(), user.CompanyID, managerID)
+		if err != nil || manager.EffectiveRole() == models.RoleEmployee {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Gestor inválido"})
+			return
+		}
+	}
+
+	user.Role = req.Role
+	user.ManagerID = managerID
+	user.UpdatedAt = time.Now()
+	if err := h.store.Users.UpdateRole(c.Request.Context(), user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar usuário"})
+		return
+	}
+
+	c.JSON(http.StatusOK, userResponse(user))
+}
+
+// CreateUser cadastra um usuário na empresa do administrador autenticado
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	var req CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Role == "" {
+		req.Role = models.RoleEmployee
+	}
+	if !req.Role.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Perfil inválido"})
+		return
+	}
+
+	if req.CPF != "" && !utils.ValidCPF(req.CPF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CPF inválido"})
+		return
+	}
+
+	companyID := currentCompanyID(c)
+	branchID, ok := h.parseBranch(c, companyID, req.BranchID)
+	if !ok {
+		return
+	}
+
+	_, err := h.store.Users.FindByEmail(c.Request.Context(), req.Email)
+	if err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email já cadastrado"})
+		return
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao verificar email"})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao processar senha"})
+		return
+	}
+
+	user := models.User{
+		Name:      req.Name,
+		Email:     req.Email,
+		CPF:       utils.NormalizeCPF(req.CPF),
+		Password:  string(hashedPassword),
+		Role:      req.Role,
+		CompanyID: companyID,
+		BranchID:  branchID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := h.store.Users.Create(c.Request.Context(), &user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar usuário"})
+		return
+	}
+	logRepEvent(h.rep.Employee(c.Request.Context(), nil, &user, currentUserID(c), user.CreatedAt))
+
+	c.JSON(http.StatusCreated, userResponse(&user))
+}
+
+// UpdateUserBranch altera o estabelecimento onde o usuário trabalha
+func (h *UserHandler) UpdateUserBranch(c *gin.Context) {
+	var req UpdateBranchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	targetID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Usuário inválido"})
+		return
+	}
+
+	user, err := h.store.Users.FindByID(c.Request.Context(), currentCompanyID(c), targetID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Usuário não encontrado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar usuário"})
+		return
+	}
+
+	branchID, ok := h.parseBranch(c, user.CompanyID, req.BranchID)
+	if !ok {
+		return
+	}
+
+	before := *user
+	user.BranchID = branchID
+	user.UpdatedAt = time.Now()
+	if err := h.store.Users.UpdateBranch(c.Request.Context(), user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar usuário"})
+		return
+	}
+	logRepEvent(h.rep.Employee(c.Request.Context(), &before, user, currentUserID(c), user.UpdatedAt))
+
+	c.JSON(http.StatusOK, userResponse(user))
+}
+
+// UpdateUserCPF altera o CPF do usuário, que o identifica nos arquivos
+// fiscais do ponto
+func (h *UserHandler) UpdateUserCPF(c *gin.Context) {
+	var req UpdateCPFRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !utils.ValidCPF(req.CPF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CPF inválido"})
+		return
+	}
+
+	targetID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Usuário inválido"})
+		return
+	}
+
+	user, err := h.store.Users.FindByID(c.Request.Context(), currentCompanyID(c), targetID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Usuário não encontrado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar usuário"})
+		return
+	}
+
+	before := *user
+	user.CPF = utils.NormalizeCPF(req.CPF)
+	user.UpdatedAt = time.Now()
+	if err := h.store.Users.UpdateCPF(c.Request.Context(), user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar usuário"})
+		return
+	}
+	logRepEvent(h.rep.Employee(c.Request.Context(), &before, user, currentUserID(c), user.UpdatedAt))
+
+	c.JSON(http.StatusOK, userResponse(user))
+}
+
+// parseBranch valida que o estabelecimento informado pertence à empresa. Um
+// valor vazio retorna o identificador nulo. Em caso de falha a resposta de
+// erro já é enviada e ok é falso.
+func (h *UserHandler) parseBranch(c *gin.Context, companyID primitive.ObjectID, value string) (primitive.ObjectID, bool) {
+	if value == "" {
+		return primitive.NilObjectID, true
+	}
+
+	branchID, err := primitive.ObjectIDFromHex(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Estabelecimento inválido"})
+		return primitive.NilObjectID, false
+	}
+
+	if _, err := h.store.Branches.FindByID(c.Request.Context(), companyID, branchID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Estabelecimento inválido"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar estabelecimento"})
+		}
+		return primitive.NilObjectID, false
+	}
+
+	return branchID, true
+}
+
+// userResponse monta a representação pública de um usuário, sem senha e PIN
+func userResponse(user *models.User) gin.H {
+	response := gin.H{
 		"id":    user.ID,
 		"name":  user.Name,
 		"email": user.Email,
-	})
+		"role":  user.EffectiveRole(),
+	}
+	if !user.ManagerID.IsZero() {
+		response["managerId"] = user.ManagerID
+	}
+	if !user.CompanyID.IsZero() {
+		response["companyId"] = user.CompanyID
+	}
+	if !user.BranchID.IsZero() {
+		response["branchId"] = user.BranchID
+	}
+	if !user.ShiftID.IsZero() {
+		response["shiftId"] = user.ShiftID
+	}
+	if user.CPF != "" {
+		response["cpf"] = user.CPF
+	}
+	return response
 }
 
+// SetupPin configura ou troca o PIN de registro de ponto do usuário
+// autenticado. PINs triviais e os últimos pin.HistorySize PINs são recusados.
+// A troca exige o PIN atual ou a senha da conta e não é aceita enquanto o PIN
+// estiver bloqueado
 func (h *UserHandler) SetupPin(c *gin.Context) {
     var req SetupPinRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "PIN deve ter entre 4 e 6 dígitos"})
+        c.JSON(http.StatusBadRequest, gin.H{"error": pin.ErrFormat.Error()})
         return
     }
 
-    userID, exists := c.Get("user_id")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+    user, ok := authenticatedUser(c, h.store)
+    if !ok {
         return
     }
 
-    // Log para debug
-    log.Printf("Configurando PIN para usuário %v: %s", userID, req.Pin)
-
-    // Atualizar o PIN do usuário
-    update := bson.M{
-        "$set": bson.M{
-            "pin": req.Pin,
-            "updated_at": time.Now(),
-        },
+    now := time.Now()
+    if user.PinLockedUntil != nil && now.Before(*user.PinLockedUntil) {
+        locked := &pin.LockedError{Until: *user.PinLockedUntil}
+        c.JSON(http.StatusTooManyRequests, gin.H{
+            "error":       locked.Error(),
+            "lockedUntil": locked.Until,
+        })
+        return
     }
 
-    result, err := h.db.Collection("users").UpdateOne(
-        context.Background(),
-        bson.M{"_id": userID},
-        update,
-    )
+    if user.Pin != "" {
+        switch {
+        case req.CurrentPin != "":
+            // O PIN atual errado conta como tentativa, como no registro
+            if !verifyPin(c, h.store, user, req.CurrentPin) {
+                return
+            }
+        case req.Password != "":
+            if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+                c.JSON(http.StatusUnauthorized, gin.H{"error": "Senha incorreta"})
+                return
+            }
+        default:
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Informe o PIN atual ou a senha da conta"})
+            return
+        }
+    }
 
-    if err != nil {
-        log.Printf("Erro ao configurar PIN: %v", err)
+    if err := pin.Set(user, req.Pin, now); err != nil {
+        if errors.Is(err, pin.ErrFormat) || errors.Is(err, pin.ErrTrivial) || errors.Is(err, pin.ErrReused) {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        log.Printf("Erro ao gerar hash do PIN do usuário %s: %v", user.ID.Hex(), err)
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao configurar PIN"})
         return
     }
+    user.UpdatedAt = now
 
-    if result.ModifiedCount == 0 {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Usuário não encontrado"})
+    // Apenas o PIN e o histórico são gravados, sem sobrescrever tentativas
+    // erradas contadas enquanto isso
+    if err := h.store.Users.ChangePin(c.Request.Context(), user); err != nil {
+        log.Printf("Erro ao configurar PIN: %v", err)
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao configurar PIN"})
         return
     }
 
-    log.Printf("PIN configurado com sucesso para usuário %v", userID)
+    log.Printf("PIN configurado para usuário %s", user.ID.Hex())
     c.JSON(http.StatusOK, gin.H{"message": "PIN configurado com sucesso"})
 }
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/pin"
+	"ponto-digital-api/internal/repository"
+)
+
+func TestSetupPin(t *testing.T) {
+	ctx := context.Background()
+	password, err := bcrypt.GenerateFromPassword([]byte("segredo1"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	locked := time.Now().Add(pin.LockDuration)
+
+	tests := []struct {
+		name         string
+		configured   bool       // usuário já tem o PIN 2580
+		failed       int        // tentativas erradas já contadas
+		lockedUntil  *time.Time // bloqueio em vigor
+		body         string
+		wantCode     int
+		wantPin      string // PIN gravado ao final
+		wantAttempts int
+	}{
+		{name: "primeiro PIN", body: `{"pin":"3691"}`, wantCode: http.StatusOK, wantPin: "3691"},
+		{name: "troca sem PIN atual nem senha", configured: true, body: `{"pin":"3691"}`, wantCode: http.StatusBadRequest, wantPin: "2580"},
+		{name: "troca com o PIN atual", configured: true, body: `{"pin":"3691","currentPin":"2580"}`, wantCode: http.StatusOK, wantPin: "3691"},
+		{name: "troca com PIN atual errado conta a tentativa", configured: true, failed: 1, body: `{"pin":"3691","currentPin":"1357"}`, wantCode: http.StatusUnauthorized, wantPin: "2580", wantAttempts: 2},
+		{name: "troca com a senha mantém o contador", configured: true, failed: 2, body: `{"pin":"3691","password":"segredo1"}`, wantCode: http.StatusOK, wantPin: "3691", wantAttempts: 2},
+		{name: "troca com senha errada", configured: true, body: `{"pin":"3691","password":"outra"}`, wantCode: http.StatusUnauthorized, wantPin: "2580"},
+		{name: "troca com o PIN bloqueado", configured: true, failed: pin.MaxAttempts, lockedUntil: &locked, body: `{"pin":"3691","password":"segredo1"}`, wantCode: http.StatusTooManyRequests, wantPin: "2580", wantAttempts: pin.MaxAttempts},
+		{name: "PIN trivial", configured: true, body: `{"pin":"1234","currentPin":"2580"}`, wantCode: http.StatusBadRequest, wantPin: "2580"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			store := repository.NewMemoryStore()
+			company := models.Company{Name: "ACME", CNPJ: "11222333000181"}
+			if err := store.Companies.Create(ctx, &company); err != nil {
+				t.Fatal(err)
+			}
+			user := models.User{
+				Name:              "Ana",
+				Password:          string(password),
+				CompanyID:         company.ID,
+				Role:              models.RoleEmployee,
+				PinFailedAttempts: tt.failed,
+				PinLockedUntil:    tt.lockedUntil,
+			}
+			if tt.configured {
+				if err := pin.Set(&user, "2580", time.Now()); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := store.Users.Create(ctx, &user); err != nil {
+				t.Fatal(err)
+			}
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("user_id", user.ID)
+				c.Set("company_id", user.CompanyID)
+				c.Set("role", user.Role)
+			})
+			router.POST("/setup-pin", NewUserHandler(store).SetupPin)
+
+			w := serve(router, http.MethodPost, "/setup-pin", tt.body)
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+
+			stored, err := store.Users.FindByID(ctx, user.CompanyID, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bcrypt.CompareHashAndPassword([]byte(stored.Pin), []byte(tt.wantPin)) != nil {
+				t.Errorf("stored PIN is not %s", tt.wantPin)
+			}
+			if stored.PinFailedAttempts != tt.wantAttempts {
+				t.Errorf("PinFailedAttempts = %d, want %d", stored.PinFailedAttempts, tt.wantAttempts)
+			}
+		})
+	}
+}
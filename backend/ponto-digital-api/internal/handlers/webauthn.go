@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/webauthn"
+)
+
+// WebAuthnHandler trata o cadastro das credenciais WebAuthn (leitor
+// biométrico do aparelho) usadas na marcação de ponto e a emissão dos
+// desafios assinados pelo autenticador.
+type WebAuthnHandler struct {
+	store *repository.Store
+	rp    *webauthn.RelyingParty
+}
+
+func NewWebAuthnHandler(store *repository.Store, rp *webauthn.RelyingParty) *WebAuthnHandler {
+	return &WebAuthnHandler{store: store, rp: rp}
+}
+
+// WebAuthnRegistrationRequest é a credencial criada pelo navegador com as
+// opções de /webauthn/register/options e um apelido para identificá-la.
+type WebAuthnRegistrationRequest struct {
+	Name       string                         `json:"name"`
+	Credential *webauthn.RegistrationResponse `json:"credential" binding:"required"`
+}
+
+// RegistrationOptions emite o desafio e as opções de
+// navigator.credentials.create() para o usuário autenticado cadastrar uma
+// credencial.
+func (h *WebAuthnHandler) RegistrationOptions(c *gin.Context) {
+	user, ok := authenticatedUser(c, h.store)
+	if !ok {
+		return
+	}
+
+	credentials, err := h.store.WebAuthnCredentials.ListByUser(c.Request.Context(), user.CompanyID, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar credenciais"})
+		return
+	}
+
+	challenge, ok := issueChallenge(c, h.store, user, models.WebAuthnRegistration)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, h.rp.CreationOptions(challenge, user.ID[:], user.Email, user.Name, credentialIDs(credentials)))
+}
+
+// RegisterCredential valida a credencial criada pelo navegador e a vincula ao
+// usuário autenticado.
+func (h *WebAuthnHandler) RegisterCredential(c *gin.Context) {
+	var req WebAuthnRegistrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := authenticatedUser(c, h.store)
+	if !ok {
+		return
+	}
+
+	challenge, ok := consumeChallenge(c, h.store, user, models.WebAuthnRegistration, req.Credential.Response.ClientDataJSON)
+	if !ok {
+		return
+	}
+
+	verified, err := h.rp.VerifyRegistration(req.Credential, challenge)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	_, err = h.store.WebAuthnCredentials.FindByCredentialID(c.Request.Context(), user.CompanyID, verified.ID)
+	if err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Credencial já cadastrada"})
+		return
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar credenciais"})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = "Biometria"
+	}
+	credential := models.WebAuthnCredential{
+		CompanyID:    user.CompanyID,
+		UserID:       user.ID,
+		CredentialID: verified.ID,
+		PublicKey:    verified.PublicKey,
+		SignCount:    verified.SignCount,
+		AAGUID:       verified.AAGUID,
+		Name:         name,
+		CreatedAt:    time.Now(),
+	}
+	if err := h.store.WebAuthnCredentials.Create(c.Request.Context(), &credential); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao cadastrar credencial"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, credential)
+}
+
+// ListCredentials lista as credenciais do usuário autenticado.
+func (h *WebAuthnHandler) ListCredentials(c *gin.Context) {
+	user, ok := authenticatedUser(c, h.store)
+	if !ok {
+		return
+	}
+
+	credentials, err := h.store.WebAuthnCredentials.ListByUser(c.Request.Context(), user.CompanyID, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar credenciais"})
+		return
+	}
+
+	c.JSON(http.StatusOK, credentials)
+}
+
+// DeleteCredential remove uma credencial do usuário autenticado, por exemplo
+// de um aparelho perdido.
+func (h *WebAuthnHandler) DeleteCredential(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	user, ok := authenticatedUser(c, h.store)
+	if !ok {
+		return
+	}
+
+	err = h.store.WebAuthnCredentials.Delete(c.Request.Context(), user.CompanyID, user.ID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Credencial não encontrada"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao remover credencial"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Credencial removida com sucesso"})
+}
+
+// AuthenticationOptions emite o desafio e as opções de
+// navigator.credentials.get() para a marcação biométrica do usuário
+// autenticado.
+func (h *WebAuthnHandler) AuthenticationOptions(c *gin.Context) {
+	user, ok := authenticatedUser(c, h.store)
+	if !ok {
+		return
+	}
+
+	credentials, err := h.store.WebAuthnCredentials.ListByUser(c.Request.Context(), user.CompanyID, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar credenciais"})
+		return
+	}
+	if len(credentials) == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Nenhuma credencial biométrica cadastrada"})
+		return
+	}
+
+	challenge, ok := issueChallenge(c, h.store, user, models.WebAuthnAuthentication)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, h.rp.RequestOptions(challenge, credentialIDs(credentials)))
+}
+
+// verifyAssertion confere a asserção WebAuthn do usuário contra o desafio
+// emitido em /webauthn/authenticate/options e a credencial cadastrada, e
+// atualiza o contador e o último uso da credencial. Em caso de falha a
+// resposta de erro já é enviada e ok é falso.
+func verifyAssertion(c *gin.Context, store *repository.Store, rp *webauthn.RelyingParty, user *models.User, assertion *webauthn.AssertionResponse) bool {
+	challenge, ok := consumeChallenge(c, store, user, models.WebAuthnAuthentication, assertion.Response.ClientDataJSON)
+	if !ok {
+		return false
+	}
+
+	ctx := c.Request.Context()
+	credential, err := store.WebAuthnCredentials.FindByCredentialID(ctx, user.CompanyID, assertion.RawID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && credential.UserID != user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Credencial biométrica não cadastrada"})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar credencial"})
+		return false
+	}
+	if handle := assertion.Response.UserHandle; len(handle) > 0 && !bytes.Equal(handle, user.ID[:]) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Credencial biométrica de outro usuário"})
+		return false
+	}
+
+	signCount, err := rp.VerifyAssertion(assertion, challenge, credential.PublicKey, credential.SignCount)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return false
+	}
+
+	now := time.Now()
+	credential.SignCount = signCount
+	credential.LastUsedAt = &now
+	if err := store.WebAuthnCredentials.Update(ctx, credential); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar credencial"})
+		return false
+	}
+	return true
+}
+
+// issueChallenge gera e guarda um desafio do usuário para a cerimônia kind.
+// Em caso de falha a resposta de erro já é enviada e ok é falso.
+func issueChallenge(c *gin.Context, store *repository.Store, user *models.User, kind models.WebAuthnChallengeKind) ([]byte, bool) {
+	challenge, err := webauthn.NewChallenge()
+	if err == nil {
+		err = store.WebAuthnChallenges.Create(c.Request.Context(), &models.WebAuthnChallenge{
+			UserID:    user.ID,
+			Kind:      kind,
+			Challenge: challenge,
+			ExpiresAt: time.Now().Add(webauthn.ChallengeTimeout),
+		})
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar desafio"})
+		return nil, false
+	}
+	return challenge, true
+}
+
+// consumeChallenge localiza e invalida o desafio presente no clientDataJSON,
+// que precisa ter sido emitido ao usuário para a cerimônia kind e estar
+// dentro da validade. Em caso de falha a resposta de erro já é enviada e ok é
+// falso.
+func consumeChallenge(c *gin.Context, store *repository.Store, user *models.User, kind models.WebAuthnChallengeKind, clientDataJSON []byte) ([]byte, bool) {
+	value, err := webauthn.Challenge(clientDataJSON)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	challenge, err := store.WebAuthnChallenges.Consume(c.Request.Context(), user.ID, kind, value)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && time.Now().After(challenge.ExpiresAt)) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Desafio WebAuthn inválido ou expirado"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar desafio"})
+		return nil, false
+	}
+	return challenge.Challenge, true
+}
+
+func credentialIDs(credentials []models.WebAuthnCredential) [][]byte {
+	ids := make([][]byte, 0, len(credentials))
+	for _, credential := range credentials {
+		ids = append(ids, credential.CredentialID)
+	}
+	return ids
+}
@@ -0,0 +1,90 @@
+package holiday
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+)
+
+// dateLayout é o formato das chaves do calendário.
+const dateLayout = "2006-01-02"
+
+// Calendar indexa os feriados pela data no formato AAAA-MM-DD.
+type Calendar map[string]models.Holiday
+
+// Lookup retorna o feriado da data, se houver.
+func (c Calendar) Lookup(date time.Time) (models.Holiday, bool) {
+	holiday, ok := c[date.Format(dateLayout)]
+	return holiday, ok
+}
+
+// List retorna os feriados do calendário ordenados por data.
+func (c Calendar) List() []models.Holiday {
+	holidays := make([]models.Holiday, 0, len(c))
+	for _, holiday := range c {
+		holidays = append(holidays, holiday)
+	}
+	sort.Slice(holidays, func(i, j int) bool {
+		return holidays[i].Date.Before(holidays[j].Date)
+	})
+	return holidays
+}
+
+// Service monta o calendário de feriados a partir dos dados persistidos.
+type Service struct {
+	store    *repository.Store
+	location *time.Location
+}
+
+// NewService cria um Service que usa loc para as datas dos feriados.
+func NewService(store *repository.Store, loc *time.Location) *Service {
+	return &Service{store: store, location: loc}
+}
+
+// For retorna os feriados do usuário entre from e to, inclusive: os nacionais,
+// os da empresa sem estabelecimento e os do estabelecimento do usuário. Um
+// feriado cadastrado substitui o nacional da mesma data. Pontos facultativos
+// são omitidos quando a empresa trabalha neles.
+func (s *Service) For(ctx context.Context, user *models.User, policy models.HolidayPolicy, from, to time.Time) (Calendar, error) {
+	from = s.date(from)
+	end := s.date(to).AddDate(0, 0, 1)
+
+	calendar := Calendar{}
+	add := func(holiday models.Holiday) {
+		if holiday.Date.Before(from) || !holiday.Date.Before(end) {
+			return
+		}
+		if holiday.Optional && policy.WorkOnOptional {
+			return
+		}
+		calendar[holiday.Date.In(s.location).Format(dateLayout)] = holiday
+	}
+
+	for year := from.Year(); year <= end.Year(); year++ {
+		for _, holiday := range National(year, s.location) {
+			add(holiday)
+		}
+	}
+
+	if user.CompanyID.IsZero() {
+		return calendar, nil
+	}
+	stored, err := s.store.Holidays.ListByCompany(ctx, user.CompanyID, from, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, holiday := range stored {
+		if holiday.BranchID.IsZero() || holiday.BranchID == user.BranchID {
+			add(holiday)
+		}
+	}
+	return calendar, nil
+}
+
+func (s *Service) date(t time.Time) time.Time {
+	t = t.In(s.location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.location)
+}
@@ -0,0 +1,69 @@
+package holiday
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+)
+
+func TestServiceFor(t *testing.T) {
+	ctx := context.Background()
+	loc := time.FixedZone("BRT", -3*60*60)
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2025, month, d, 0, 0, 0, 0, loc)
+	}
+
+	store := repository.NewMemoryStore()
+	companyID := primitive.NewObjectID()
+	branchID := primitive.NewObjectID()
+	otherBranchID := primitive.NewObjectID()
+	for _, holiday := range []models.Holiday{
+		{CompanyID: companyID, Scope: models.HolidayMunicipal, Date: day(time.June, 24), Name: "São João"},
+		{CompanyID: companyID, BranchID: branchID, Scope: models.HolidayState, Date: day(time.March, 6), Name: "Revolução Pernambucana"},
+		{CompanyID: companyID, BranchID: otherBranchID, Scope: models.HolidayMunicipal, Date: day(time.January, 25), Name: "Aniversário de São Paulo"},
+		{CompanyID: companyID, Scope: models.HolidayCompany, Date: day(time.June, 19), Name: "Folga de Corpus Christi"},
+		{CompanyID: primitive.NewObjectID(), Scope: models.HolidayCompany, Date: day(time.May, 2), Name: "Folga de outra empresa"},
+	} {
+		if err := store.Holidays.Create(ctx, &holiday); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		user   models.User
+		policy models.HolidayPolicy
+		date   time.Time
+		want   string // vazio quando não é feriado
+	}{
+		{name: "nacional", user: models.User{CompanyID: companyID}, date: day(time.April, 18), want: "Paixão de Cristo"},
+		{name: "da empresa", user: models.User{CompanyID: companyID}, date: day(time.June, 24), want: "São João"},
+		{name: "do estabelecimento do usuário", user: models.User{CompanyID: companyID, BranchID: branchID}, date: day(time.March, 6), want: "Revolução Pernambucana"},
+		{name: "de outro estabelecimento", user: models.User{CompanyID: companyID, BranchID: branchID}, date: day(time.January, 25)},
+		{name: "de estabelecimento para usuário da sede", user: models.User{CompanyID: companyID}, date: day(time.March, 6)},
+		{name: "cadastrado substitui o nacional", user: models.User{CompanyID: companyID}, date: day(time.June, 19), want: "Folga de Corpus Christi"},
+		{name: "ponto facultativo", user: models.User{CompanyID: companyID}, date: day(time.March, 4), want: "Carnaval"},
+		{name: "ponto facultativo trabalhado", user: models.User{CompanyID: companyID}, policy: models.HolidayPolicy{WorkOnOptional: true}, date: day(time.March, 4)},
+		{name: "de outra empresa", user: models.User{CompanyID: companyID}, date: day(time.May, 2)},
+		{name: "usuário sem empresa recebe apenas os nacionais", user: models.User{}, date: day(time.June, 24)},
+	}
+
+	service := NewService(store, loc)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar, err := service.For(ctx, &tt.user, tt.policy, day(time.January, 1), day(time.December, 31))
+			if err != nil {
+				t.Fatalf("For() error = %v", err)
+			}
+
+			holiday, ok := calendar.Lookup(tt.date)
+			if got := holiday.Name; got != tt.want || ok != (tt.want != "") {
+				t.Errorf("Lookup(%s) = %q, %v, want %q", tt.date.Format(dateLayout), got, ok, tt.want)
+			}
+		})
+	}
+}
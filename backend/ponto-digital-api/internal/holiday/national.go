@@ -0,0 +1,79 @@
+// Package holiday monta o calendário de feriados dos funcionários: os
+// feriados nacionais, calculados para cada ano, e os feriados estaduais,
+// municipais e folgas cadastrados pela empresa.
+package holiday
+
+import (
+	"time"
+
+	"ponto-digital-api/internal/models"
+)
+
+// fixed são os feriados nacionais de data fixa (Leis 662/1949, 6.802/1980 e
+// 14.759/2023).
+var fixed = []struct {
+	month time.Month
+	day   int
+	name  string
+	since int // primeiro ano de vigência
+}{
+	{time.January, 1, "Confraternização Universal", 0},
+	{time.April, 21, "Tiradentes", 0},
+	{time.May, 1, "Dia do Trabalho", 0},
+	{time.September, 7, "Independência do Brasil", 0},
+	{time.October, 12, "Nossa Senhora Aparecida", 0},
+	{time.November, 2, "Finados", 0},
+	{time.November, 15, "Proclamação da República", 0},
+	{time.November, 20, "Dia Nacional de Zumbi e da Consciência Negra", 2024},
+	{time.December, 25, "Natal", 0},
+}
+
+// National retorna os feriados nacionais do ano, incluindo os móveis, que
+// dependem da Páscoa. Carnaval e Corpus Christi são pontos facultativos.
+func National(year int, loc *time.Location) []models.Holiday {
+	var holidays []models.Holiday
+	for _, f := range fixed {
+		if year < f.since {
+			continue
+		}
+		holidays = append(holidays, national(time.Date(year, f.month, f.day, 0, 0, 0, 0, loc), f.name, false))
+	}
+
+	easter := Easter(year, loc)
+	holidays = append(holidays,
+		national(easter.AddDate(0, 0, -48), "Carnaval", true),
+		national(easter.AddDate(0, 0, -47), "Carnaval", true),
+		national(easter.AddDate(0, 0, -2), "Paixão de Cristo", false),
+		national(easter.AddDate(0, 0, 60), "Corpus Christi", true),
+	)
+	return holidays
+}
+
+func national(date time.Time, name string, optional bool) models.Holiday {
+	return models.Holiday{
+		Scope:    models.HolidayNational,
+		Date:     date,
+		Name:     name,
+		Optional: optional,
+	}
+}
+
+// Easter retorna o domingo de Páscoa do ano pelo calendário gregoriano
+// (algoritmo de Meeus/Jones/Butcher).
+func Easter(year int, loc *time.Location) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,80 @@
+package holiday
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEaster(t *testing.T) {
+	tests := []struct {
+		year  int
+		month time.Month
+		day   int
+	}{
+		{1818, time.March, 22}, // data mais cedo possível
+		{1943, time.April, 25}, // data mais tarde possível
+		{2000, time.April, 23},
+		{2008, time.March, 23},
+		{2019, time.April, 21},
+		{2024, time.March, 31},
+		{2025, time.April, 20},
+		{2026, time.April, 5},
+		{2038, time.April, 25},
+	}
+
+	for _, tt := range tests {
+		got := Easter(tt.year, time.UTC)
+		want := time.Date(tt.year, tt.month, tt.day, 0, 0, 0, 0, time.UTC)
+		if !got.Equal(want) {
+			t.Errorf("Easter(%d) = %s, want %s", tt.year, got.Format(dateLayout), want.Format(dateLayout))
+		}
+	}
+}
+
+func TestNational(t *testing.T) {
+	loc := time.FixedZone("BRT", -3*60*60)
+
+	tests := []struct {
+		name     string
+		year     int
+		date     string
+		want     string
+		optional bool
+		missing  bool
+	}{
+		{name: "Carnaval de 2025, segunda", year: 2025, date: "2025-03-03", want: "Carnaval", optional: true},
+		{name: "Carnaval de 2025, terça", year: 2025, date: "2025-03-04", want: "Carnaval", optional: true},
+		{name: "Paixão de Cristo de 2025", year: 2025, date: "2025-04-18", want: "Paixão de Cristo"},
+		{name: "Corpus Christi de 2025", year: 2025, date: "2025-06-19", want: "Corpus Christi", optional: true},
+		{name: "Carnaval em ano bissexto", year: 2024, date: "2024-02-13", want: "Carnaval", optional: true},
+		{name: "Paixão de Cristo em março", year: 2024, date: "2024-03-29", want: "Paixão de Cristo"},
+		{name: "Corpus Christi de 2024", year: 2024, date: "2024-05-30", want: "Corpus Christi", optional: true},
+		{name: "data fixa", year: 2025, date: "2025-04-21", want: "Tiradentes"},
+		{name: "Consciência Negra a partir de 2024", year: 2024, date: "2024-11-20", want: "Dia Nacional de Zumbi e da Consciência Negra"},
+		{name: "Consciência Negra antes da lei", year: 2023, date: "2023-11-20", missing: true},
+		{name: "Domingo de Páscoa não é feriado", year: 2025, date: "2025-04-20", missing: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar := Calendar{}
+			for _, holiday := range National(tt.year, loc) {
+				if holiday.Date.Location() != loc || holiday.Date.Hour() != 0 {
+					t.Fatalf("%s is not midnight in %s", holiday.Date, loc)
+				}
+				calendar[holiday.Date.Format(dateLayout)] = holiday
+			}
+
+			holiday, ok := calendar[tt.date]
+			if tt.missing {
+				if ok {
+					t.Errorf("National(%d) has %q on %s", tt.year, holiday.Name, tt.date)
+				}
+				return
+			}
+			if !ok || holiday.Name != tt.want || holiday.Optional != tt.optional {
+				t.Errorf("National(%d)[%s] = %+v, want %q (optional %v)", tt.year, tt.date, holiday, tt.want, tt.optional)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// Package hourbank apura o banco de horas dos funcionários. O saldo de cada
+// dia de jornada é creditado ou debitado no banco, junto com os lançamentos
+// manuais dos gestores. Os créditos são consumidos na ordem em que foram
+// gerados e expiram após o prazo da política da empresa.
+package hourbank
+
+import (
+	"sort"
+	"time"
+
+	"ponto-digital-api/internal/timesheet"
+)
+
+// Source identifica a origem de um movimento do banco de horas.
+type Source string
+
+const (
+	SourceTimesheet  Source = "jornada"   // saldo de um dia de jornada
+	SourceAdjustment Source = "ajuste"    // lançamento manual do gestor
+	SourceExpiration Source = "expiracao" // crédito vencido sem compensação
+)
+
+// Movement é um crédito (minutos positivos) ou débito do banco de horas.
+type Movement struct {
+	Date    time.Time `json:"-"`
+	Day     string    `json:"date"`
+	Source  Source    `json:"source"`
+	Minutes int       `json:"minutes"`
+	Reason  string    `json:"reason,omitempty"`
+	Balance int       `json:"balance"` // saldo após o movimento
+}
+
+// Lot é um crédito ainda não compensado.
+type Lot struct {
+	Date      string `json:"date"`
+	Minutes   int    `json:"minutes"` // minutos restantes
+	ExpiresOn string `json:"expires_on"`
+
+	expiresAt time.Time
+}
+
+// Statement é o extrato do banco de horas.
+type Statement struct {
+	BalanceMinutes int        `json:"balance_minutes"` // créditos em aberto menos débitos
+	CreditMinutes  int        `json:"credit_minutes"`
+	DebtMinutes    int        `json:"debt_minutes"`
+	ExpiredMinutes int        `json:"expired_minutes"` // a pagar como horas extras
+	Lots           []Lot      `json:"lots"`
+	Movements      []Movement `json:"movements"`
+}
+
+// Compute aplica os movimentos em ordem de data e vence os créditos com mais
+// de expirationMonths meses até until, inclusive. Débitos consomem primeiro os
+// créditos mais antigos; sem créditos, ficam como saldo devedor, que é quitado
+// pelos créditos seguintes e não expira.
+func Compute(movements []Movement, expirationMonths int, until time.Time) Statement {
+	sorted := append([]Movement(nil), movements...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+
+	statement := Statement{Lots: []Lot{}, Movements: []Movement{}}
+	for _, movement := range sorted {
+		if movement.Date.After(until) {
+			break
+		}
+		statement.expire(movement.Date)
+		statement.apply(movement, expirationMonths)
+	}
+	statement.expire(until)
+
+	for _, lot := range statement.Lots {
+		statement.CreditMinutes += lot.Minutes
+	}
+	return statement
+}
+
+// apply registra o movimento, consumindo ou gerando créditos.
+func (s *Statement) apply(movement Movement, expirationMonths int) {
+	minutes := movement.Minutes
+	if minutes > 0 {
+		paid := min(minutes, s.DebtMinutes)
+		s.DebtMinutes -= paid
+		if remaining := minutes - paid; remaining > 0 {
+			expiresAt := movement.Date.AddDate(0, expirationMonths, 0)
+			s.Lots = append(s.Lots, Lot{
+				Date:      movement.Date.Format(timesheet.DateLayout),
+				Minutes:   remaining,
+				ExpiresOn: expiresAt.Format(timesheet.DateLayout),
+				expiresAt: expiresAt,
+			})
+		}
+	} else {
+		debit := -minutes
+		for debit > 0 && len(s.Lots) > 0 {
+			used := min(debit, s.Lots[0].Minutes)
+			s.Lots[0].Minutes -= used
+			debit -= used
+			if s.Lots[0].Minutes == 0 {
+				s.Lots = s.Lots[1:]
+			}
+		}
+		s.DebtMinutes += debit
+	}
+
+	s.BalanceMinutes += minutes
+	movement.Day = movement.Date.Format(timesheet.DateLayout)
+	movement.Balance = s.BalanceMinutes
+	s.Movements = append(s.Movements, movement)
+}
+
+// expire vence os créditos cuja validade termina até date.
+func (s *Statement) expire(date time.Time) {
+	for len(s.Lots) > 0 && !s.Lots[0].expiresAt.After(date) {
+		lot := s.Lots[0]
+		s.Lots = s.Lots[1:]
+
+		s.ExpiredMinutes += lot.Minutes
+		s.BalanceMinutes -= lot.Minutes
+		s.Movements = append(s.Movements, Movement{
+			Date:    lot.expiresAt,
+			Day:     lot.ExpiresOn,
+			Source:  SourceExpiration,
+			Minutes: -lot.Minutes,
+			Reason:  "Crédito de " + lot.Date + " não compensado no prazo",
+			Balance: s.BalanceMinutes,
+		})
+	}
+}
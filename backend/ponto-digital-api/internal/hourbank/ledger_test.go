@@ -0,0 +1,148 @@
+package hourbank
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func date(month time.Month, day int) time.Time {
+	return time.Date(2025, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func movement(month time.Month, day, minutes int) Movement {
+	return Movement{Date: date(month, day), Source: SourceTimesheet, Minutes: minutes}
+}
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name        string
+		movements   []Movement
+		months      int
+		until       time.Time
+		wantBalance int
+		wantDebt    int
+		wantExpired int
+		wantLots    map[string]int // minutos restantes por data do crédito
+	}{
+		{
+			name:        "créditos em aberto",
+			movements:   []Movement{movement(time.January, 10, 60), movement(time.February, 1, 30)},
+			months:      6,
+			until:       date(time.March, 1),
+			wantBalance: 90,
+			wantLots:    map[string]int{"2025-01-10": 60, "2025-02-01": 30},
+		},
+		{
+			name:        "débito consome o crédito mais antigo",
+			movements:   []Movement{movement(time.January, 10, 60), movement(time.February, 1, 30), movement(time.March, 1, -70)},
+			months:      6,
+			until:       date(time.March, 1),
+			wantBalance: 20,
+			wantLots:    map[string]int{"2025-02-01": 20},
+		},
+		{
+			name:        "movimentos fora de ordem",
+			movements:   []Movement{movement(time.March, 1, -70), movement(time.February, 1, 30), movement(time.January, 10, 60)},
+			months:      6,
+			until:       date(time.March, 1),
+			wantBalance: 20,
+			wantLots:    map[string]int{"2025-02-01": 20},
+		},
+		{
+			name:        "crédito vence no prazo",
+			movements:   []Movement{movement(time.January, 10, 60), movement(time.February, 1, 30), movement(time.February, 5, -20)},
+			months:      6,
+			until:       date(time.July, 10),
+			wantBalance: 30,
+			wantExpired: 40,
+			wantLots:    map[string]int{"2025-02-01": 30},
+		},
+		{
+			name:        "véspera do vencimento",
+			movements:   []Movement{movement(time.January, 10, 60), movement(time.February, 1, 30), movement(time.February, 5, -20)},
+			months:      6,
+			until:       date(time.July, 9),
+			wantBalance: 70,
+			wantLots:    map[string]int{"2025-01-10": 40, "2025-02-01": 30},
+		},
+		{
+			name:        "prazo de 12 meses",
+			movements:   []Movement{movement(time.January, 10, 60)},
+			months:      12,
+			until:       date(time.July, 10),
+			wantBalance: 60,
+			wantLots:    map[string]int{"2025-01-10": 60},
+		},
+		{
+			name:        "crédito vencido não compensa débito posterior",
+			movements:   []Movement{movement(time.January, 10, 60), movement(time.August, 1, -30)},
+			months:      6,
+			until:       date(time.August, 1),
+			wantBalance: -30,
+			wantDebt:    30,
+			wantExpired: 60,
+			wantLots:    map[string]int{},
+		},
+		{
+			name:        "saldo devedor quitado por crédito seguinte",
+			movements:   []Movement{movement(time.January, 10, -60), movement(time.January, 20, 90)},
+			months:      6,
+			until:       date(time.December, 31),
+			wantBalance: 0,
+			wantExpired: 30,
+			wantLots:    map[string]int{},
+		},
+		{
+			name:        "saldo devedor não expira",
+			movements:   []Movement{movement(time.January, 10, -60)},
+			months:      6,
+			until:       date(time.December, 31),
+			wantBalance: -60,
+			wantDebt:    60,
+			wantLots:    map[string]int{},
+		},
+		{
+			name:        "movimentos após until",
+			movements:   []Movement{movement(time.January, 10, 60), movement(time.March, 2, -60)},
+			months:      6,
+			until:       date(time.March, 1),
+			wantBalance: 60,
+			wantLots:    map[string]int{"2025-01-10": 60},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement := Compute(tt.movements, tt.months, tt.until)
+
+			if statement.BalanceMinutes != tt.wantBalance {
+				t.Errorf("BalanceMinutes = %d, want %d", statement.BalanceMinutes, tt.wantBalance)
+			}
+			if statement.DebtMinutes != tt.wantDebt {
+				t.Errorf("DebtMinutes = %d, want %d", statement.DebtMinutes, tt.wantDebt)
+			}
+			if statement.ExpiredMinutes != tt.wantExpired {
+				t.Errorf("ExpiredMinutes = %d, want %d", statement.ExpiredMinutes, tt.wantExpired)
+			}
+
+			lots := map[string]int{}
+			credit := 0
+			for _, lot := range statement.Lots {
+				lots[lot.Date] = lot.Minutes
+				credit += lot.Minutes
+			}
+			if !reflect.DeepEqual(lots, tt.wantLots) {
+				t.Errorf("Lots = %v, want %v", lots, tt.wantLots)
+			}
+			if statement.CreditMinutes != credit {
+				t.Errorf("CreditMinutes = %d, want %d", statement.CreditMinutes, credit)
+			}
+			if len(statement.Movements) > 0 {
+				if last := statement.Movements[len(statement.Movements)-1]; last.Balance != statement.BalanceMinutes {
+					t.Errorf("last movement balance = %d, want %d", last.Balance, statement.BalanceMinutes)
+				}
+			}
+		})
+	}
+}
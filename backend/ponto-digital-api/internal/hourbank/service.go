@@ -0,0 +1,73 @@
+package hourbank
+
+import (
+	"context"
+	"time"
+
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/timesheet"
+)
+
+// Service monta o extrato do banco de horas a partir das marcações e dos
+// lançamentos manuais persistidos.
+type Service struct {
+	store  *repository.Store
+	sheets *timesheet.Service
+}
+
+// NewService cria um Service que usa sheets para apurar os dias de jornada.
+func NewService(store *repository.Store, sheets *timesheet.Service) *Service {
+	return &Service{store: store, sheets: sheets}
+}
+
+// Statement apura o banco de horas do usuário desde o seu cadastro até today.
+// Os dias de jornada entram até a véspera, pois o dia corrente ainda não está
+// fechado; os lançamentos manuais entram até today.
+func (s *Service) Statement(ctx context.Context, user *models.User, today time.Time) (Statement, error) {
+	policy, err := s.sheets.PolicyFor(ctx, user)
+	if err != nil {
+		return Statement{}, err
+	}
+
+	until := s.sheets.Date(today).AddDate(0, 0, -1)
+	start := s.sheets.Date(user.CreatedAt)
+	if user.CreatedAt.IsZero() {
+		// Cadastros antigos sem data: apura os créditos ainda não vencidos
+		start = until.AddDate(0, -policy.HourBank.ExpirationMonths, 0)
+	}
+
+	var movements []Movement
+	if !start.After(until) {
+		days, err := s.sheets.Period(ctx, user, start, until)
+		if err != nil {
+			return Statement{}, err
+		}
+		for i := range days {
+			day := &days[i]
+			if day.AdjustedBalanceMinutes == 0 {
+				continue
+			}
+			movements = append(movements, Movement{
+				Date:    day.Time(),
+				Source:  SourceTimesheet,
+				Minutes: day.AdjustedBalanceMinutes,
+			})
+		}
+	}
+
+	adjustments, err := s.store.HourBank.ListByUser(ctx, user.CompanyID, user.ID)
+	if err != nil {
+		return Statement{}, err
+	}
+	for _, adjustment := range adjustments {
+		movements = append(movements, Movement{
+			Date:    s.sheets.Date(adjustment.Date),
+			Source:  SourceAdjustment,
+			Minutes: adjustment.Minutes,
+			Reason:  adjustment.Reason,
+		})
+	}
+
+	return Compute(movements, policy.HourBank.ExpirationMonths, s.sheets.Date(today)), nil
+}
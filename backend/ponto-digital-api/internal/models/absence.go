@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AbsenceKind é o motivo de uma ausência justificada
+type AbsenceKind string
+
+const (
+	AbsenceMedical       AbsenceKind = "atestado"
+	AbsenceVacation      AbsenceKind = "ferias"
+	AbsenceMaternity     AbsenceKind = "licenca_maternidade"
+	AbsencePaternity     AbsenceKind = "licenca_paternidade"
+	AbsenceBereavement   AbsenceKind = "falecimento"  // art. 473, I, da CLT
+	AbsenceMarriage      AbsenceKind = "casamento"    // art. 473, II, da CLT
+	AbsenceBloodDonation AbsenceKind = "doacao_sangue" // art. 473, IV, da CLT
+	AbsenceOther         AbsenceKind = "outro"
+)
+
+// AbsenceStatus é a situação de uma ausência. Apenas as aprovadas são abonadas
+type AbsenceStatus string
+
+const (
+	AbsencePending  AbsenceStatus = "pendente"
+	AbsenceApproved AbsenceStatus = "aprovada"
+	AbsenceRejected AbsenceStatus = "rejeitada"
+)
+
+// Absence é uma ausência justificada de StartDate a EndDate, inclusive. O
+// tempo previsto nesses dias é abonado e conta como trabalhado. Um abono
+// parcial, de StartTime a EndTime, vale para um único dia
+type Absence struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	CompanyID    primitive.ObjectID `bson:"company_id"`
+	UserID       primitive.ObjectID `bson:"user_id"`
+	Kind         AbsenceKind       `bson:"kind"`
+	StartDate    time.Time         `bson:"start_date"` // meia-noite no fuso da empresa
+	EndDate      time.Time         `bson:"end_date"`
+	StartTime    string            `bson:"start_time,omitempty"` // "HH:MM", vazio para o dia inteiro
+	EndTime      string            `bson:"end_time,omitempty"`
+	Reason       string            `bson:"reason"`
+	AttachmentID primitive.ObjectID `bson:"attachment_id,omitempty"`
+	Status       AbsenceStatus     `bson:"status"`
+	CreatedBy    primitive.ObjectID `bson:"created_by"`
+	ReviewerID   primitive.ObjectID `bson:"reviewer_id,omitempty"`
+	ReviewNote   string            `bson:"review_note,omitempty"`
+	ReviewedAt   time.Time         `bson:"reviewed_at,omitempty"`
+	CreatedAt    time.Time         `bson:"created_at"`
+	UpdatedAt    time.Time         `bson:"updated_at"`
+}
+
+// Partial informa se o abono cobre apenas parte do dia
+func (a *Absence) Partial() bool {
+	return a.StartTime != ""
+}
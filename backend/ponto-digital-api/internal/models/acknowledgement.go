@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AcknowledgementStatus é a resposta do funcionário ao espelho de ponto de
+// uma competência fechada
+type AcknowledgementStatus string
+
+const (
+	AcknowledgementPending  AcknowledgementStatus = "pendente" // sem resposta; não é gravado
+	AcknowledgementAgreed   AcknowledgementStatus = "ciente"
+	AcknowledgementDisputed AcknowledgementStatus = "contestado"
+)
+
+// Acknowledgement é a assinatura eletrônica do espelho de ponto pelo
+// funcionário: a concordância ou a contestação, com comentário, do documento
+// identificado por DocumentKey e DocumentHash. Um novo fechamento da
+// competência gera outro espelho, que precisa de nova assinatura
+type Acknowledgement struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	CompanyID    primitive.ObjectID `bson:"company_id"`
+	UserID       primitive.ObjectID `bson:"user_id"`
+	Year         int               `bson:"year"`
+	Month        int               `bson:"month"`
+	Status       AcknowledgementStatus `bson:"status"`
+	Comment      string            `bson:"comment,omitempty"`
+	DocumentKey  string            `bson:"document_key"`
+	DocumentHash string            `bson:"document_hash"` // SHA-256 do espelho assinado
+	IP           string            `bson:"ip"`
+	Device       string            `bson:"device,omitempty"`
+	UserAgent    string            `bson:"user_agent,omitempty"`
+	CreatedAt    time.Time         `bson:"created_at"`
+}
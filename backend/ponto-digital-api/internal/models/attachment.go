@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Attachment descreve um arquivo enviado pelo usuário, como um atestado. O
+// conteúdo fica no armazenamento de arquivos, sob a chave Key
+type Attachment struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	CompanyID   primitive.ObjectID `bson:"company_id"`
+	UserID      primitive.ObjectID `bson:"user_id"`
+	FileName    string            `bson:"file_name"`
+	ContentType string            `bson:"content_type"`
+	Size        int64             `bson:"size"`
+	SHA256      string            `bson:"sha256"`
+	Key         string            `bson:"key"`
+	CreatedAt   time.Time         `bson:"created_at"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ClosingStatus é a situação do fechamento de uma competência
+type ClosingStatus string
+
+const (
+	ClosingClosed   ClosingStatus = "fechada"
+	ClosingReopened ClosingStatus = "reaberta"
+)
+
+// PeriodClosing é o fechamento de uma competência (mês) da empresa. Enquanto
+// fechada, as marcações, os ajustes, as ausências e o banco de horas do mês
+// não podem ser alterados. Totals guarda a apuração usada na folha de
+// pagamento
+type PeriodClosing struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	CompanyID  primitive.ObjectID `bson:"company_id"`
+	Year       int               `bson:"year"`
+	Month      int               `bson:"month"`
+	Status     ClosingStatus     `bson:"status"`
+	ClosedBy   primitive.ObjectID `bson:"closed_by"`
+	ClosedAt   time.Time         `bson:"closed_at"`
+	Totals     []ClosingTotals   `bson:"totals"`
+	Reopenings []ClosingReopening `bson:"reopenings"` // reaberturas, da mais antiga à mais recente
+	CreatedAt  time.Time         `bson:"created_at"`
+	UpdatedAt  time.Time         `bson:"updated_at"`
+}
+
+// ClosingTotals é a apuração de um funcionário na competência, no momento do
+// fechamento. O espelho de ponto emitido fica guardado em DocumentKey
+type ClosingTotals struct {
+	UserID           primitive.ObjectID `bson:"user_id"`
+	Name             string            `bson:"name"`
+	CPF              string            `bson:"cpf,omitempty"`
+	ExpectedMinutes  int               `bson:"expected_minutes"`
+	WorkedMinutes    int               `bson:"worked_minutes"` // com tolerância e abonos
+	BalanceMinutes   int               `bson:"balance_minutes"`
+	OvertimeMinutes  int               `bson:"overtime_minutes"`
+	Overtime         []ClosingOvertime `bson:"overtime"`
+	NightPaidMinutes int               `bson:"night_paid_minutes"`
+	AbonoMinutes     int               `bson:"abono_minutes"`
+	AbonoDays        int               `bson:"abono_days"`
+	MissedDays       int               `bson:"missed_days"`
+	LateDays         int               `bson:"late_days"`
+	HourBankMinutes  int               `bson:"hour_bank_minutes"` // saldo ao fim do mês
+	DocumentKey      string            `bson:"document_key"`
+	DocumentHash     string            `bson:"document_hash"` // SHA-256 do espelho em PDF
+}
+
+// ClosingOvertime são os minutos extras pagos com um adicional percentual
+type ClosingOvertime struct {
+	Rate    int `bson:"rate"`
+	Minutes int `bson:"minutes"`
+}
+
+// ClosingReopening registra a reabertura de uma competência fechada
+type ClosingReopening struct {
+	ReopenedBy primitive.ObjectID `bson:"reopened_by"`
+	ReopenedAt time.Time         `bson:"reopened_at"`
+	Reason     string            `bson:"reason"`
+	ClosedAt   time.Time         `bson:"closed_at"` // fechamento desfeito pela reabertura
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Company representa um empregador cliente da plataforma
+type Company struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Name      string            `bson:"name"`  // razão social
+	CNPJ      string            `bson:"cnpj"`  // apenas dígitos
+	Policy    *LaborPolicy      `bson:"labor_policy,omitempty"` // nulo usa DefaultLaborPolicy
+	CreatedAt time.Time         `bson:"created_at"`
+	UpdatedAt time.Time         `bson:"updated_at"`
+}
+
+// EffectivePolicy retorna as regras trabalhistas da empresa, assumindo as da
+// CLT quando não configuradas
+func (c *Company) EffectivePolicy() LaborPolicy {
+	if c.Policy == nil {
+		return DefaultLaborPolicy()
+	}
+	return c.Policy.withDefaults()
+}
+
+// Branch representa um estabelecimento da empresa, com CNPJ próprio
+type Branch struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	CompanyID primitive.ObjectID `bson:"company_id"`
+	Name      string            `bson:"name"`
+	CNPJ      string            `bson:"cnpj"`  // apenas dígitos
+	Address   string            `bson:"address,omitempty"`
+	City      string            `bson:"city,omitempty"`
+	State     string            `bson:"state,omitempty"` // UF
+	AgreementID primitive.ObjectID `bson:"agreement_id,omitempty"` // convenção coletiva aplicável
+	CreatedAt time.Time         `bson:"created_at"`
+	UpdatedAt time.Time         `bson:"updated_at"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CorrectionKind identifica o ajuste solicitado
+type CorrectionKind string
+
+const (
+	CorrectionInclude   CorrectionKind = "inclusao"        // incluir uma marcação esquecida
+	CorrectionDisregard CorrectionKind = "desconsideracao" // desconsiderar uma marcação indevida
+)
+
+// CorrectionStatus é a situação de uma solicitação de ajuste
+type CorrectionStatus string
+
+const (
+	CorrectionPending  CorrectionStatus = "pendente"
+	CorrectionApproved CorrectionStatus = "aprovada"
+	CorrectionRejected CorrectionStatus = "rejeitada"
+)
+
+// CorrectionRequest é uma solicitação de ajuste de ponto feita pelo
+// funcionário e analisada pelo gestor
+type CorrectionRequest struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	CompanyID     primitive.ObjectID `bson:"company_id"`
+	UserID        primitive.ObjectID `bson:"user_id"`
+	Kind          CorrectionKind    `bson:"kind"`
+	Type          PunchType         `bson:"type,omitempty"`      // inclusão: tipo da marcação
+	Timestamp     time.Time         `bson:"timestamp,omitempty"` // inclusão: horário da marcação
+	RecordID      primitive.ObjectID `bson:"record_id,omitempty"` // desconsideração: marcação original
+	Justification string            `bson:"justification"`
+	AttachmentID  primitive.ObjectID `bson:"attachment_id,omitempty"`
+	Status        CorrectionStatus  `bson:"status"`
+	ReviewerID    primitive.ObjectID `bson:"reviewer_id,omitempty"`
+	ReviewNote    string            `bson:"review_note,omitempty"`
+	ReviewedAt    time.Time         `bson:"reviewed_at,omitempty"`
+	AppliedID     primitive.ObjectID `bson:"applied_id,omitempty"` // registro gerado na aprovação
+	CreatedAt     time.Time         `bson:"created_at"`
+	UpdatedAt     time.Time         `bson:"updated_at"`
+}
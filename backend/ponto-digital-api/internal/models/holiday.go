@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HolidayScope indica a origem de um feriado
+type HolidayScope string
+
+const (
+	HolidayNational  HolidayScope = "nacional"
+	HolidayState     HolidayScope = "estadual"
+	HolidayMunicipal HolidayScope = "municipal"
+	HolidayCompany   HolidayScope = "empresa" // folga concedida pela empresa
+)
+
+// Holiday é um dia sem expediente. Feriados nacionais são calculados e não
+// ficam gravados; os demais pertencem a uma empresa e, quando BranchID é
+// informado, valem apenas para o estabelecimento.
+type Holiday struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	CompanyID primitive.ObjectID `bson:"company_id"`
+	BranchID  primitive.ObjectID `bson:"branch_id,omitempty"`
+	Scope     HolidayScope      `bson:"scope"`
+	Date      time.Time         `bson:"date"` // à meia-noite no fuso da empresa
+	Name      string            `bson:"name"`
+	Optional  bool              `bson:"optional"` // ponto facultativo
+	CreatedAt time.Time         `bson:"created_at"`
+}
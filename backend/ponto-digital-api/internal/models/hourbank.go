@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HourBankAdjustment é um lançamento manual no banco de horas do funcionário,
+// feito pelo gestor. Minutes positivo credita e negativo debita.
+type HourBankAdjustment struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	CompanyID primitive.ObjectID `bson:"company_id"`
+	UserID    primitive.ObjectID `bson:"user_id"`
+	Date      time.Time         `bson:"date"` // data de referência, à meia-noite
+	Minutes   int               `bson:"minutes"`
+	Reason    string            `bson:"reason"`
+	CreatedBy primitive.ObjectID `bson:"created_by"`
+	CreatedAt time.Time         `bson:"created_at"`
+}
@@ -1,26 +1,111 @@
 package models
 
 import (
+	"strings"
 	"time"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Role define o perfil de acesso de um usuário
+type Role string
+
+const (
+	RoleEmployee Role = "employee" // acesso apenas aos próprios registros
+	RoleManager  Role = "manager"  // acesso aos registros da sua equipe
+	RoleAdmin    Role = "admin"    // gestão de todos os usuários
+)
+
+// Valid informa se o perfil é um dos perfis conhecidos
+func (r Role) Valid() bool {
+	switch r {
+	case RoleEmployee, RoleManager, RoleAdmin:
+		return true
+	}
+	return false
+}
+
 type User struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty"`
 	Email     string            `bson:"email"`
 	Password  string            `bson:"password"`
 	Name      string            `bson:"name"`
-	Pin       string            `bson:"pin,omitempty"`    // PIN para registro de ponto
+	CPF       string            `bson:"cpf,omitempty"`    // apenas dígitos, exigido nos arquivos fiscais
+	Pin       string            `bson:"pin,omitempty"`    // hash bcrypt do PIN para registro de ponto
+	PinHistory []PinChange      `bson:"pin_history,omitempty"` // PINs configurados, do mais antigo ao atual
+	PinFailedAttempts int       `bson:"pin_failed_attempts,omitempty"`
+	PinLockedUntil *time.Time   `bson:"pin_locked_until,omitempty"` // bloqueio temporário após tentativas erradas
+	Role      Role              `bson:"role,omitempty"`
+	ManagerID primitive.ObjectID `bson:"manager_id,omitempty"` // gestor responsável pela equipe do usuário
+	CompanyID primitive.ObjectID `bson:"company_id,omitempty"` // empresa (tenant) do usuário
+	BranchID  primitive.ObjectID `bson:"branch_id,omitempty"`  // estabelecimento onde trabalha
+	GroupID   primitive.ObjectID `bson:"group_id,omitempty"`   // grupo de jornada
+	ScheduleID primitive.ObjectID `bson:"schedule_id,omitempty"` // jornada própria, prevalece sobre a do grupo
+	ShiftID   primitive.ObjectID `bson:"shift_id,omitempty"`   // escala de revezamento, prevalece sobre a jornada
+	ShiftAnchor time.Time        `bson:"shift_anchor,omitempty"` // início do ciclo do usuário, se diferente do da escala
 	CreatedAt time.Time         `bson:"created_at"`
 	UpdatedAt time.Time         `bson:"updated_at"`
 }
 
+// PinChange registra uma troca de PIN: o hash bcrypt do PIN configurado e a
+// data da troca
+type PinChange struct {
+	Hash      string    `bson:"hash"`
+	ChangedAt time.Time `bson:"changed_at"`
+}
+
+// EffectiveRole retorna o perfil do usuário, considerando como funcionário
+// os cadastros anteriores à criação dos perfis
+func (u *User) EffectiveRole() Role {
+	if u.Role == "" {
+		return RoleEmployee
+	}
+	return u.Role
+}
+
+// PunchType identifica o tipo de uma marcação de ponto
+type PunchType string
+
+const (
+	PunchEntrada PunchType = "entrada"
+	PunchSaida   PunchType = "saída"
+)
+
+// ParsePunchType converte o tipo informado pelo cliente, aceitando variações
+// de caixa e a grafia sem acento ("saida")
+func ParsePunchType(value string) (PunchType, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "entrada":
+		return PunchEntrada, true
+	case "saída", "saida":
+		return PunchSaida, true
+	}
+	return "", false
+}
+
 type TimeRecord struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty"`
 	UserID      primitive.ObjectID `bson:"user_id"`
-	Type        string            `bson:"type"`           // "entrada" ou "saida"
+	CompanyID   primitive.ObjectID `bson:"company_id,omitempty"`
+	BranchID    primitive.ObjectID `bson:"branch_id,omitempty"`
+	Type        PunchType         `bson:"type"`           // "entrada" ou "saída"
 	Timestamp   time.Time         `bson:"timestamp"`
 	Location    string            `bson:"location,omitempty"`
 	Device      string            `bson:"device,omitempty"`
-	AuthMethod  string            `bson:"auth_method"`    // "pin" ou "biometric"
-}
\ No newline at end of file
+	AuthMethod  string            `bson:"auth_method"`    // "pin", "biometric" ou "correcao"
+	Origin      RecordOrigin      `bson:"origin,omitempty"`        // vazio nas marcações originais
+	TargetID    primitive.ObjectID `bson:"target_id,omitempty"`    // marcação desconsiderada
+	CorrectionID primitive.ObjectID `bson:"correction_id,omitempty"` // solicitação de ajuste que gerou o registro
+	NSR         int64             `bson:"nsr,omitempty"`           // número sequencial do registro no estabelecimento
+	Hash        string            `bson:"hash,omitempty"`          // SHA-256 encadeado ao registro anterior
+}
+
+// RecordOrigin identifica os registros gerados por ajustes aprovados. As
+// marcações originais nunca são alteradas: uma marcação esquecida é incluída
+// como um novo registro e uma marcação indevida é desconsiderada por um
+// registro que aponta para ela
+type RecordOrigin string
+
+const (
+	OriginInclusion   RecordOrigin = "inclusao"        // marcação incluída por ajuste
+	OriginDisregarded RecordOrigin = "desconsideracao" // desconsidera a marcação TargetID
+)
\ No newline at end of file
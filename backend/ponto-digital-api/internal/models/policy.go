@@ -0,0 +1,121 @@
+package models
+
+import (
+	"time"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LaborPolicy reúne as regras trabalhistas da empresa usadas no cálculo da
+// jornada
+type LaborPolicy struct {
+	Tolerance TolerancePolicy `bson:"tolerance"`
+	Overtime  OvertimePolicy  `bson:"overtime"`
+	Night     NightPolicy     `bson:"night"`
+	HourBank  HourBankPolicy  `bson:"hour_bank"`
+	Holidays  HolidayPolicy   `bson:"holidays"`
+}
+
+// TolerancePolicy define as variações de marcação desconsideradas no cálculo
+// das horas (CLT, art. 58, §1º)
+type TolerancePolicy struct {
+	PerPunchMinutes int `bson:"per_punch_minutes"` // variação máxima tolerada em cada marcação
+	PerDayMinutes   int `bson:"per_day_minutes"`   // soma máxima das variações toleradas no dia
+}
+
+// OvertimePolicy define os adicionais das horas extras. As faixas são
+// aplicadas em ordem sobre as horas extras do dia; a última não tem limite.
+// Em domingos de folga e feriados todas as horas trabalhadas recebem
+// RestDayRate.
+type OvertimePolicy struct {
+	Bands             []OvertimeBand `bson:"bands"`
+	RestDayRate       int            `bson:"rest_day_rate"`       // adicional percentual em domingos de folga e feriados
+	DailyLimitMinutes int            `bson:"daily_limit_minutes"` // limite de horas extras por dia (CLT, art. 59)
+}
+
+// OvertimeBand é uma faixa de horas extras com o seu adicional
+type OvertimeBand struct {
+	UpToMinutes int `bson:"up_to_minutes"` // minutos extras acumulados no dia até o fim da faixa
+	Rate        int `bson:"rate"`          // adicional percentual, como 50 ou 100
+}
+
+// withDefaults completa as regras gravadas antes da existência de algum
+// grupo de regras com os valores da CLT
+func (p LaborPolicy) withDefaults() LaborPolicy {
+	if len(p.Overtime.Bands) == 0 {
+		p.Overtime = DefaultLaborPolicy().Overtime
+	}
+	if p.Night.Start == "" {
+		p.Night = DefaultLaborPolicy().Night
+	}
+	if p.HourBank.ExpirationMonths == 0 {
+		p.HourBank = DefaultLaborPolicy().HourBank
+	}
+	return p
+}
+
+// NightPolicy define o trabalho noturno (CLT, art. 73). Horários no formato
+// "HH:MM"; um fim anterior ao início indica período que atravessa a
+// meia-noite.
+type NightPolicy struct {
+	Start       string `bson:"start"`
+	End         string `bson:"end"`
+	Rate        int    `bson:"rate"`         // adicional noturno percentual
+	ReducedHour bool   `bson:"reduced_hour"` // hora noturna de 52 minutos e 30 segundos
+	// ExtendAfterEnd considera noturnas as horas trabalhadas após o fim do
+	// período por quem cumpriu nele toda a jornada (TST, Súmula 60, II)
+	ExtendAfterEnd bool `bson:"extend_after_end"`
+}
+
+// HourBankPolicy define o banco de horas (CLT, art. 59, §§ 2º e 5º). Créditos
+// não compensados no prazo expiram e devem ser pagos como horas extras.
+type HourBankPolicy struct {
+	ExpirationMonths int `bson:"expiration_months"` // 6 por acordo individual, 12 por acordo coletivo
+}
+
+// HolidayPolicy define como os feriados afetam a jornada
+type HolidayPolicy struct {
+	WorkOnOptional bool `bson:"work_on_optional"` // pontos facultativos são dias normais de trabalho
+}
+
+// CollectiveAgreement é uma convenção ou acordo coletivo cujas regras
+// substituem as da empresa nos estabelecimentos vinculados a ele
+type CollectiveAgreement struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	CompanyID primitive.ObjectID `bson:"company_id"`
+	Name      string            `bson:"name"`
+	Policy    LaborPolicy       `bson:"policy"`
+	CreatedAt time.Time         `bson:"created_at"`
+	UpdatedAt time.Time         `bson:"updated_at"`
+}
+
+// EffectivePolicy retorna as regras da convenção completadas com as da CLT
+func (a *CollectiveAgreement) EffectivePolicy() LaborPolicy {
+	return a.Policy.withDefaults()
+}
+
+// DefaultLaborPolicy retorna as regras da CLT: variações de até 5 minutos por
+// marcação, limitadas a 10 minutos por dia, horas extras com adicional de 50%
+// até o limite de 2 horas diárias e de 100% além dele e nos domingos de folga e
+// adicional noturno urbano de 20% das 22h às 5h, com hora reduzida, e banco de
+// horas com créditos válidos por 6 meses
+func DefaultLaborPolicy() LaborPolicy {
+	return LaborPolicy{
+		Tolerance: TolerancePolicy{PerPunchMinutes: 5, PerDayMinutes: 10},
+		Overtime: OvertimePolicy{
+			Bands: []OvertimeBand{
+				{UpToMinutes: 120, Rate: 50},
+				{Rate: 100},
+			},
+			RestDayRate:       100,
+			DailyLimitMinutes: 120,
+		},
+		Night: NightPolicy{
+			Start:          "22:00",
+			End:            "05:00",
+			Rate:           20,
+			ReducedHour:    true,
+			ExtendAfterEnd: true,
+		},
+		HourBank: HourBankPolicy{ExpirationMonths: 6},
+	}
+}
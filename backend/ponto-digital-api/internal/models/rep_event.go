@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RepEventKind identifica os eventos de cadastro do REP, que ocupam NSRs da
+// mesma sequência das marcações do estabelecimento
+type RepEventKind string
+
+const (
+	RepEventEmployer RepEventKind = "empregador" // identificação do empregador (AFD tipo 2)
+	RepEventEmployee RepEventKind = "empregado"  // cadastro de empregado (AFD tipo 5)
+)
+
+// RepEventOperation é a operação do evento de cadastro, com os códigos do
+// registro tipo 5 do AFD
+type RepEventOperation string
+
+const (
+	RepEventInclusion RepEventOperation = "I"
+	RepEventChange    RepEventOperation = "A"
+	RepEventExclusion RepEventOperation = "E"
+)
+
+// RepEvent é a inclusão ou alteração do empregador, ou a inclusão, alteração
+// ou exclusão de um empregado, no REP de um estabelecimento. Guarda os dados
+// vigentes no momento do evento, para que o AFD reproduza sempre os mesmos
+// registros
+type RepEvent struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	CompanyID     primitive.ObjectID `bson:"company_id"`
+	BranchID      primitive.ObjectID `bson:"branch_id,omitempty"`
+	NSR           int64             `bson:"nsr"`
+	Kind          RepEventKind      `bson:"kind"`
+	Operation     RepEventOperation `bson:"operation"`
+	UserID        primitive.ObjectID `bson:"user_id,omitempty"` // empregado
+	CPF           string            `bson:"cpf,omitempty"`      // empregado
+	CNPJ          string            `bson:"cnpj,omitempty"`     // empregador
+	Name          string            `bson:"name"`               // razão social ou nome do empregado
+	Location      string            `bson:"location,omitempty"` // local de prestação de serviços
+	ResponsibleID primitive.ObjectID `bson:"responsible_id,omitempty"`
+	CreatedAt     time.Time         `bson:"created_at"`
+}
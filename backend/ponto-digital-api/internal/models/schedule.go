@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WorkSchedule é uma jornada de trabalho semanal, definida dia a dia
+type WorkSchedule struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	CompanyID primitive.ObjectID `bson:"company_id,omitempty"`
+	Name      string            `bson:"name"`
+	Days      []ScheduleDay     `bson:"days"` // dias sem registro são folga
+	CreatedAt time.Time         `bson:"created_at"`
+	UpdatedAt time.Time         `bson:"updated_at"`
+}
+
+// WorkHours são os horários previstos de um dia de trabalho. Os horários usam
+// o formato "HH:MM"; uma saída anterior à entrada indica jornada que termina
+// no dia seguinte.
+type WorkHours struct {
+	Entry        string `bson:"entry"`
+	Exit         string `bson:"exit"`
+	BreakStart   string `bson:"break_start,omitempty"`
+	BreakEnd     string `bson:"break_end,omitempty"`
+	DailyMinutes int    `bson:"daily_minutes"` // total previsto, descontado o intervalo
+}
+
+// ScheduleDay é a jornada prevista para um dia da semana
+type ScheduleDay struct {
+	Weekday   time.Weekday `bson:"weekday"`
+	WorkHours `bson:",inline"`
+}
+
+// Day retorna a jornada prevista para o dia da semana, se houver
+func (s *WorkSchedule) Day(weekday time.Weekday) (ScheduleDay, bool) {
+	for _, day := range s.Days {
+		if day.Weekday == weekday {
+			return day, true
+		}
+	}
+	return ScheduleDay{}, false
+}
+
+// WorkGroup agrupa funcionários que compartilham a mesma jornada
+type WorkGroup struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	CompanyID  primitive.ObjectID `bson:"company_id,omitempty"`
+	Name       string            `bson:"name"`
+	ScheduleID primitive.ObjectID `bson:"schedule_id,omitempty"`
+	CreatedAt  time.Time         `bson:"created_at"`
+	UpdatedAt  time.Time         `bson:"updated_at"`
+}
+
+// ShiftKind identifica o tipo de escala de revezamento
+type ShiftKind string
+
+const (
+	Shift12x36  ShiftKind = "12x36"  // 12 horas de trabalho por 36 de descanso
+	Shift6x1    ShiftKind = "6x1"    // 6 dias de trabalho por 1 de folga
+	Shift5x2    ShiftKind = "5x2"    // 5 dias de trabalho por 2 de folga
+	ShiftCustom ShiftKind = "custom" // ciclo definido dia a dia
+)
+
+// ShiftPattern é uma escala cíclica. O primeiro dia do ciclo coincide com
+// AnchorDate e o ciclo se repete indefinidamente antes e depois dessa data.
+type ShiftPattern struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	CompanyID  primitive.ObjectID `bson:"company_id,omitempty"`
+	Name       string            `bson:"name"`
+	Kind       ShiftKind         `bson:"kind"`
+	AnchorDate time.Time         `bson:"anchor_date"`
+	Cycle      []ShiftDay        `bson:"cycle"`
+	CreatedAt  time.Time         `bson:"created_at"`
+	UpdatedAt  time.Time         `bson:"updated_at"`
+}
+
+// ShiftDay é um dia do ciclo da escala: trabalho, com seus horários, ou folga
+type ShiftDay struct {
+	Working   bool `bson:"working"`
+	WorkHours `bson:",inline"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Session é uma sessão de login do usuário em um aparelho. O refresh token
+// da sessão é trocado a cada renovação e apenas o hash SHA-256 dele é
+// guardado; os tokens de acesso carregam o ID da sessão e deixam de valer
+// quando ela é encerrada
+type Session struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	CompanyID    primitive.ObjectID `bson:"company_id"`
+	UserID       primitive.ObjectID `bson:"user_id"`
+	RefreshHash  string            `bson:"refresh_hash"`
+	PreviousHash string            `bson:"previous_hash,omitempty"` // refresh token substituído na última renovação
+	RotatedAt    time.Time         `bson:"rotated_at"`
+	IP           string            `bson:"ip"`
+	UserAgent    string            `bson:"user_agent,omitempty"`
+	CreatedAt    time.Time         `bson:"created_at"`
+	ExpiresAt    time.Time         `bson:"expires_at"` // renovado a cada troca do refresh token
+	RevokedAt    *time.Time        `bson:"revoked_at,omitempty"`
+}
+
+// Active informa se a sessão não foi encerrada nem expirou
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
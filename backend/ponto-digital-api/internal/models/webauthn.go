@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WebAuthnCredential é uma credencial WebAuthn (leitor biométrico do
+// aparelho) cadastrada pelo usuário para registrar o ponto. PublicKey é a
+// chave pública no formato COSE e SignCount o último contador de assinaturas
+// informado pelo autenticador
+type WebAuthnCredential struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	CompanyID    primitive.ObjectID `bson:"company_id"`
+	UserID       primitive.ObjectID `bson:"user_id"`
+	CredentialID []byte            `bson:"credential_id"`
+	PublicKey    []byte            `bson:"public_key"`
+	SignCount    uint32            `bson:"sign_count"`
+	AAGUID       []byte            `bson:"aaguid,omitempty"`
+	Name         string            `bson:"name"` // apelido dado pelo usuário, como "Celular"
+	CreatedAt    time.Time         `bson:"created_at"`
+	LastUsedAt   *time.Time        `bson:"last_used_at,omitempty"`
+}
+
+// WebAuthnChallengeKind identifica a cerimônia para a qual o desafio foi
+// emitido
+type WebAuthnChallengeKind string
+
+const (
+	WebAuthnRegistration   WebAuthnChallengeKind = "cadastro"
+	WebAuthnAuthentication WebAuthnChallengeKind = "autenticacao"
+)
+
+// WebAuthnChallenge é um desafio emitido ao navegador, válido até ExpiresAt
+// e usado uma única vez
+type WebAuthnChallenge struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id"`
+	Kind      WebAuthnChallengeKind `bson:"kind"`
+	Challenge []byte            `bson:"challenge"`
+	ExpiresAt time.Time         `bson:"expires_at"`
+}
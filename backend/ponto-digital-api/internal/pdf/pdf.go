@@ -0,0 +1,124 @@
+// Package pdf gera documentos PDF simples, com texto nas fontes padrão
+// Helvetica e linhas, suficientes para comprovantes e relatórios.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Dimensões da página A4 em pontos.
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// Font é uma das fontes padrão do documento.
+type Font int
+
+const (
+	Regular Font = iota
+	Bold
+)
+
+// Document é um documento PDF em construção.
+type Document struct {
+	Title string
+	pages []*Page
+}
+
+// Page é uma página do documento. As coordenadas são medidas em pontos a
+// partir do canto superior esquerdo; no texto, y é a linha de base.
+type Page struct {
+	Width   float64
+	Height  float64
+	content bytes.Buffer
+}
+
+// New cria um documento vazio.
+func New(title string) *Document {
+	return &Document{Title: title}
+}
+
+// AddPage acrescenta uma página com as dimensões informadas.
+func (d *Document) AddPage(width, height float64) *Page {
+	page := &Page{Width: width, Height: height}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// Text escreve text a partir de (x, y). Caracteres fora do Latin-1 são
+// substituídos por "?".
+func (p *Page) Text(x, y float64, font Font, size float64, text string) {
+	fmt.Fprintf(&p.content, "BT /F%d %s Tf %s %s Td (%s) Tj ET\n",
+		font+1, number(size), number(x), number(p.Height-y), escape(text))
+}
+
+// Line traça uma linha de (x1, y1) a (x2, y2) com a espessura informada.
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s m %s %s l S\n",
+		number(width), number(x1), number(p.Height-y1), number(x2), number(p.Height-y2))
+}
+
+// Bytes retorna o documento codificado.
+func (d *Document) Bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objetos fixos: 1 catálogo, 2 árvore de páginas, 3 e 4 fontes, 5
+	// informações; cada página ocupa dois objetos a partir do 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title (%s) /Producer (Ponto Digital) >>", escape(d.Title)))
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			number(page.Width), number(page.Height), 7+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.content.Len(), page.content.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+// escape codifica text em WinAnsi, que coincide com o Latin-1 nos
+// caracteres acentuados, e protege os delimitadores de string do PDF.
+func escape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+func number(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,174 @@
+// Package pin trata o PIN numérico usado no registro de ponto: o PIN é
+// guardado apenas como hash bcrypt, PINs triviais ou usados recentemente são
+// recusados e tentativas erradas seguidas bloqueiam o PIN temporariamente.
+package pin
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"ponto-digital-api/internal/models"
+)
+
+const (
+	MinLength = 4
+	MaxLength = 6
+	// HistorySize é a quantidade de PINs guardados no histórico, incluindo o
+	// atual, que não podem ser reutilizados.
+	HistorySize = 5
+	// MaxAttempts tentativas erradas seguidas bloqueiam o PIN por
+	// LockDuration.
+	MaxAttempts  = 5
+	LockDuration = 15 * time.Minute
+)
+
+var (
+	ErrNotConfigured = errors.New("PIN não configurado")
+	ErrMismatch      = errors.New("PIN incorreto")
+	ErrFormat        = fmt.Errorf("O PIN deve ter de %d a %d dígitos numéricos", MinLength, MaxLength)
+	ErrTrivial       = errors.New("PIN fácil de adivinhar: evite dígitos repetidos ou em sequência")
+	ErrReused        = fmt.Errorf("O PIN não pode repetir nenhum dos últimos %d PINs", HistorySize)
+)
+
+// InvalidError indica um PIN incorreto; Remaining é o número de tentativas
+// antes do bloqueio.
+type InvalidError struct {
+	Remaining int
+}
+
+func (e *InvalidError) Error() string {
+	if e.Remaining == 1 {
+		return "PIN inválido; resta 1 tentativa antes do bloqueio"
+	}
+	return fmt.Sprintf("PIN inválido; restam %d tentativas antes do bloqueio", e.Remaining)
+}
+
+// LockedError indica um PIN bloqueado por tentativas erradas até Until.
+type LockedError struct {
+	Until time.Time
+}
+
+func (e *LockedError) Error() string {
+	minutes := int(time.Until(e.Until).Round(time.Minute) / time.Minute)
+	return fmt.Sprintf("PIN bloqueado por excesso de tentativas; tente novamente em %d min", max(minutes, 1))
+}
+
+// Validate recusa PINs fora do formato ou triviais: todos os dígitos iguais
+// (1111), em sequência crescente ou decrescente (1234, 9876) ou um par de
+// dígitos repetido (1212).
+func Validate(value string) error {
+	if len(value) < MinLength || len(value) > MaxLength {
+		return ErrFormat
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return ErrFormat
+		}
+	}
+
+	ascending, descending := true, true
+	for i := 1; i < len(value); i++ {
+		step := (int(value[i]) - int(value[i-1]) + 10) % 10
+		ascending = ascending && step == 1
+		descending = descending && step == 9
+	}
+	if ascending || descending {
+		return ErrTrivial
+	}
+	if len(value)%2 == 0 && strings.Repeat(value[:2], len(value)/2) == value {
+		return ErrTrivial
+	}
+	if strings.Count(value, value[:1]) == len(value) {
+		return ErrTrivial
+	}
+	return nil
+}
+
+// Set valida e configura um novo PIN para o usuário e guarda o hash no
+// histórico. O contador de tentativas erradas e o bloqueio não mudam: trocar
+// o PIN não libera um PIN bloqueado.
+func Set(user *models.User, value string, now time.Time) error {
+	if err := Validate(value); err != nil {
+		return err
+	}
+	if user.Pin != "" && matches(user.Pin, value) {
+		return ErrReused
+	}
+	for _, change := range user.PinHistory {
+		if matches(change.Hash, value) {
+			return ErrReused
+		}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(value), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	user.Pin = string(hash)
+	user.PinHistory = append(user.PinHistory, models.PinChange{Hash: user.Pin, ChangedAt: now})
+	if len(user.PinHistory) > HistorySize {
+		user.PinHistory = user.PinHistory[len(user.PinHistory)-HistorySize:]
+	}
+	return nil
+}
+
+// Verify confere o PIN informado. Retorna ErrNotConfigured, *LockedError
+// quando o PIN está bloqueado, mesmo que correto, e ErrMismatch quando está
+// errado; a tentativa errada deve ser contada pelo chamador, de forma
+// atômica, e o resultado informado por Failure. No acerto, o contador e um
+// bloqueio expirado são zerados e os PINs gravados em texto puro, anteriores
+// ao hash, são convertidos para bcrypt; save indica que esses campos
+// mudaram e precisam ser gravados.
+func Verify(user *models.User, value string, now time.Time) (save bool, err error) {
+	if user.Pin == "" {
+		return false, ErrNotConfigured
+	}
+	if user.PinLockedUntil != nil && now.Before(*user.PinLockedUntil) {
+		return false, &LockedError{Until: *user.PinLockedUntil}
+	}
+	if !matches(user.Pin, value) {
+		return false, ErrMismatch
+	}
+
+	save = user.PinFailedAttempts > 0 || user.PinLockedUntil != nil
+	user.PinFailedAttempts = 0
+	user.PinLockedUntil = nil
+	if !isHash(user.Pin) {
+		hash, err := bcrypt.GenerateFromPassword([]byte(value), bcrypt.DefaultCost)
+		if err != nil {
+			return false, err
+		}
+		user.Pin = string(hash)
+		user.PinHistory = append(user.PinHistory, models.PinChange{Hash: user.Pin, ChangedAt: now})
+		save = true
+	}
+	return save, nil
+}
+
+// Failure descreve a tentativa errada a partir do usuário com o contador já
+// atualizado: *LockedError se a tentativa bloqueou o PIN ou *InvalidError com
+// as tentativas restantes.
+func Failure(user *models.User, now time.Time) error {
+	if user.PinLockedUntil != nil && now.Before(*user.PinLockedUntil) {
+		return &LockedError{Until: *user.PinLockedUntil}
+	}
+	return &InvalidError{Remaining: max(MaxAttempts-user.PinFailedAttempts, 1)}
+}
+
+// matches compara o PIN com o valor guardado, um hash bcrypt ou, nos
+// cadastros antigos, o próprio PIN. Ambas as comparações têm tempo constante.
+func matches(stored, value string) bool {
+	if isHash(stored) {
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(value)) == nil
+	}
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(value)) == 1
+}
+
+func isHash(stored string) bool {
+	return strings.HasPrefix(stored, "$2")
+}
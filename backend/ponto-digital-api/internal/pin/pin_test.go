@@ -0,0 +1,131 @@
+package pin
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"ponto-digital-api/internal/models"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  error
+	}{
+		{"2580", nil},
+		{"135790", nil},
+		{"0481", nil},
+		{"123", ErrFormat},
+		{"1234567", ErrFormat},
+		{"", ErrFormat},
+		{"12a4", ErrFormat},
+		{"١٢٣٤", ErrFormat}, // dígitos não ASCII
+		{"1111", ErrTrivial},
+		{"000000", ErrTrivial},
+		{"1234", ErrTrivial},
+		{"456789", ErrTrivial},
+		{"8901", ErrTrivial}, // sequência que passa do 9 para o 0
+		{"9876", ErrTrivial},
+		{"3210", ErrTrivial},
+		{"1212", ErrTrivial},
+		{"474747", ErrTrivial},
+		{"12312", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if err := Validate(tt.value); !errors.Is(err, tt.want) {
+				t.Errorf("Validate(%q) = %v, want %v", tt.value, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSet(t *testing.T) {
+	now := time.Now()
+	locked := now.Add(LockDuration)
+	user := &models.User{PinFailedAttempts: 3, PinLockedUntil: &locked}
+
+	for _, value := range []string{"2580", "3691", "4702", "5813", "6924", "7035"} {
+		if err := Set(user, value, now); err != nil {
+			t.Fatalf("Set(%q) error = %v", value, err)
+		}
+	}
+	if len(user.PinHistory) != HistorySize || !matches(user.Pin, "7035") {
+		t.Errorf("history has %d PINs, want %d ending with the current one", len(user.PinHistory), HistorySize)
+	}
+	if user.PinFailedAttempts != 3 || user.PinLockedUntil != &locked {
+		t.Errorf("PinFailedAttempts = %d, PinLockedUntil = %v, want them unchanged", user.PinFailedAttempts, user.PinLockedUntil)
+	}
+
+	tests := []struct {
+		value string
+		want  error
+	}{
+		{"7035", ErrReused}, // atual
+		{"3691", ErrReused}, // no histórico
+		{"2580", nil},       // já saiu do histórico
+		{"1234", ErrTrivial},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			candidate := *user
+			if err := Set(&candidate, tt.value, now); !errors.Is(err, tt.want) {
+				t.Errorf("Set(%q) = %v, want %v", tt.value, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	hash, err := bcrypt.GenerateFromPassword([]byte("2580"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	locked := now.Add(time.Minute)
+	expired := now.Add(-time.Minute)
+
+	tests := []struct {
+		name       string
+		user       models.User
+		value      string
+		wantErr    error
+		wantLocked bool
+		wantSave   bool
+	}{
+		{name: "PIN não configurado", value: "2580", wantErr: ErrNotConfigured},
+		{name: "PIN correto", user: models.User{Pin: string(hash)}, value: "2580"},
+		{name: "PIN errado", user: models.User{Pin: string(hash)}, value: "2581", wantErr: ErrMismatch},
+		{name: "PIN correto zera o contador", user: models.User{Pin: string(hash), PinFailedAttempts: 2}, value: "2580", wantSave: true},
+		{name: "PIN correto com bloqueio expirado", user: models.User{Pin: string(hash), PinFailedAttempts: MaxAttempts, PinLockedUntil: &expired}, value: "2580", wantSave: true},
+		{name: "PIN correto bloqueado", user: models.User{Pin: string(hash), PinLockedUntil: &locked}, value: "2580", wantLocked: true},
+		{name: "PIN em texto puro é convertido", user: models.User{Pin: "2580"}, value: "2580", wantSave: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := tt.user
+			save, err := Verify(&user, tt.value, now)
+
+			var lockedErr *LockedError
+			if tt.wantLocked {
+				if !errors.As(err, &lockedErr) || !lockedErr.Until.Equal(locked) {
+					t.Fatalf("Verify() error = %v, want locked until %v", err, locked)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if save != tt.wantSave {
+				t.Errorf("save = %v, want %v", save, tt.wantSave)
+			}
+			if err == nil && (user.PinFailedAttempts != 0 || user.PinLockedUntil != nil || !isHash(user.Pin)) {
+				t.Errorf("user = %+v, want the counter reset and a bcrypt hash", user)
+			}
+		})
+	}
+}
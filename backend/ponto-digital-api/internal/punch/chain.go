@@ -0,0 +1,128 @@
+package punch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"ponto-digital-api/internal/models"
+)
+
+// Hash calcula o hash encadeado do registro: o SHA-256 dos seus campos e do
+// hash do registro anterior do estabelecimento, vazio no primeiro registro.
+// O horário entra com precisão de milissegundos, a mesma do MongoDB.
+func Hash(record *models.TimeRecord, previousHash string) string {
+	fields, _ := json.Marshal([]string{
+		strconv.FormatInt(record.NSR, 10),
+		record.ID.Hex(),
+		record.CompanyID.Hex(),
+		record.BranchID.Hex(),
+		record.UserID.Hex(),
+		string(record.Type),
+		strconv.FormatInt(record.Timestamp.UnixMilli(), 10),
+		record.Location,
+		record.Device,
+		record.AuthMethod,
+		string(record.Origin),
+		record.TargetID.Hex(),
+		record.CorrectionID.Hex(),
+		previousHash,
+	})
+	sum := sha256.Sum256(fields)
+	return hex.EncodeToString(sum[:])
+}
+
+// ChainIssue é uma inconsistência encontrada na sequência de registros.
+type ChainIssue struct {
+	NSR      int64  `json:"nsr"`
+	RecordID string `json:"record_id,omitempty"`
+	Problem  string `json:"problem"`
+}
+
+// ChainReport é o resultado da verificação dos registros de um
+// estabelecimento.
+type ChainReport struct {
+	Records int          `json:"records"`
+	LastNSR int64        `json:"last_nsr"`
+	Valid   bool         `json:"valid"`
+	Issues  []ChainIssue `json:"issues"`
+}
+
+// VerifyChain confere a numeração e o encadeamento dos registros numerados de
+// um estabelecimento. Os eventos de cadastro do REP ocupam NSRs da mesma
+// sequência, mas não entram no encadeamento: o hash de cada registro é
+// encadeado ao do registro anterior. headNSR e headHash são o último NSR
+// emitido e o hash do último registro, guardados à parte, que revelam a
+// exclusão dos últimos registros. Lacunas indicam registros excluídos, NSR
+// repetido indica registros duplicados e hash divergente indica registros
+// alterados.
+func VerifyChain(records []models.TimeRecord, events []models.RepEvent, headNSR int64, headHash string) ChainReport {
+	type entry struct {
+		nsr    int64
+		id     string
+		record *models.TimeRecord // nulo nos eventos de cadastro
+	}
+	entries := make([]entry, 0, len(records)+len(events))
+	for i := range records {
+		if records[i].NSR > 0 {
+			entries = append(entries, entry{nsr: records[i].NSR, id: records[i].ID.Hex(), record: &records[i]})
+		}
+	}
+	for _, event := range events {
+		entries = append(entries, entry{nsr: event.NSR, id: event.ID.Hex()})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].nsr < entries[j].nsr })
+
+	report := ChainReport{Records: len(entries), Issues: []ChainIssue{}}
+	expected := int64(1)
+	previousHash := ""
+	for _, entry := range entries {
+		switch {
+		case entry.nsr < expected:
+			report.Issues = append(report.Issues, ChainIssue{
+				NSR:      entry.nsr,
+				RecordID: entry.id,
+				Problem:  "NSR repetido: registro duplicado",
+			})
+			continue
+		case entry.nsr > expected:
+			// Sem o registro anterior o encadeamento só pode ser conferido a
+			// partir do próximo
+			report.Issues = append(report.Issues, missingIssue(expected, entry.nsr-1))
+		case entry.record != nil && Hash(entry.record, previousHash) != entry.record.Hash:
+			report.Issues = append(report.Issues, ChainIssue{
+				NSR:      entry.nsr,
+				RecordID: entry.id,
+				Problem:  "Hash não confere: registro alterado",
+			})
+		}
+		if entry.record != nil {
+			previousHash = entry.record.Hash
+		}
+		expected = entry.nsr + 1
+		report.LastNSR = entry.nsr
+	}
+
+	switch {
+	case headNSR > report.LastNSR:
+		report.Issues = append(report.Issues, missingIssue(report.LastNSR+1, headNSR))
+	case headNSR == report.LastNSR && headHash != previousHash:
+		report.Issues = append(report.Issues, ChainIssue{
+			NSR:     headNSR,
+			Problem: "Hash do último registro difere do emitido",
+		})
+	}
+	report.Valid = len(report.Issues) == 0
+	return report
+}
+
+func missingIssue(from, to int64) ChainIssue {
+	problem := fmt.Sprintf("NSR %d ausente: registro excluído", from)
+	if to > from {
+		problem = fmt.Sprintf("NSR %d a %d ausentes: registros excluídos", from, to)
+	}
+	return ChainIssue{NSR: from, Problem: problem}
+}
@@ -0,0 +1,160 @@
+package punch
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/models"
+)
+
+// chain monta n registros numerados e encadeados como os grava o repositório.
+func chain(n int) []models.TimeRecord {
+	companyID := primitive.NewObjectID()
+	start := time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+
+	records := make([]models.TimeRecord, n)
+	previousHash := ""
+	for i := range records {
+		record := &records[i]
+		record.ID = primitive.NewObjectID()
+		record.CompanyID = companyID
+		record.UserID = companyID
+		record.Type = models.PunchEntrada
+		record.Timestamp = start.Add(time.Duration(i) * time.Hour)
+		record.NSR = int64(i + 1)
+		record.Hash = Hash(record, previousHash)
+		previousHash = record.Hash
+	}
+	return records
+}
+
+func TestVerifyChain(t *testing.T) {
+	tests := []struct {
+		name     string
+		records  func() []models.TimeRecord
+		events   []models.RepEvent
+		headNSR  int64
+		headHash func([]models.TimeRecord) string // padrão: o hash do registro headNSR
+		problems []int64                          // NSR de cada inconsistência esperada
+	}{
+		{
+			name:    "sequência íntegra",
+			records: func() []models.TimeRecord { return chain(4) },
+			headNSR: 4,
+		},
+		{
+			name:    "sem registros",
+			records: func() []models.TimeRecord { return nil },
+		},
+		{
+			name: "registro do meio excluído",
+			records: func() []models.TimeRecord {
+				records := chain(4)
+				return append(records[:1], records[2:]...)
+			},
+			headNSR:  4,
+			problems: []int64{2},
+		},
+		{
+			name: "últimos registros excluídos",
+			records: func() []models.TimeRecord {
+				return chain(4)[:2]
+			},
+			headNSR:  4,
+			headHash: func([]models.TimeRecord) string { return "" },
+			problems: []int64{3},
+		},
+		{
+			name: "registro alterado",
+			records: func() []models.TimeRecord {
+				records := chain(3)
+				records[1].Timestamp = records[1].Timestamp.Add(-30 * time.Minute)
+				return records
+			},
+			headNSR:  3,
+			problems: []int64{2},
+		},
+		{
+			name: "registro duplicado",
+			records: func() []models.TimeRecord {
+				records := chain(3)
+				duplicate := records[1]
+				duplicate.ID = primitive.NewObjectID()
+				return append(records, duplicate)
+			},
+			headNSR:  3,
+			problems: []int64{2},
+		},
+		{
+			name: "último registro substituído",
+			records: func() []models.TimeRecord {
+				return chain(3)
+			},
+			headNSR:  3,
+			headHash: func([]models.TimeRecord) string { return "outro" },
+			problems: []int64{3},
+		},
+		{
+			name: "eventos de cadastro ocupam NSRs fora do encadeamento",
+			records: func() []models.TimeRecord {
+				records := chain(2)
+				// As marcações seguintes aos eventos 3 e 4 se encadeiam à
+				// marcação 2
+				next := records[1]
+				next.ID = primitive.NewObjectID()
+				next.NSR = 5
+				next.Hash = Hash(&next, records[1].Hash)
+				return append(records, next)
+			},
+			events:  []models.RepEvent{{NSR: 3}, {NSR: 4}},
+			headNSR: 5,
+		},
+		{
+			name:     "evento de cadastro excluído",
+			records:  func() []models.TimeRecord { return chain(2) },
+			events:   []models.RepEvent{{NSR: 4}},
+			headNSR:  4,
+			headHash: func(records []models.TimeRecord) string { return records[1].Hash },
+			problems: []int64{3},
+		},
+		{
+			name: "ajustes sem NSR são ignorados",
+			records: func() []models.TimeRecord {
+				return append(chain(2), models.TimeRecord{ID: primitive.NewObjectID()})
+			},
+			headNSR: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := tt.records()
+			headHash := ""
+			if tt.headHash != nil {
+				headHash = tt.headHash(records)
+			} else {
+				for _, record := range records {
+					if record.NSR == tt.headNSR {
+						headHash = record.Hash
+						break
+					}
+				}
+			}
+
+			report := VerifyChain(records, tt.events, tt.headNSR, headHash)
+
+			if report.Valid != (len(tt.problems) == 0) {
+				t.Errorf("Valid = %v, issues %+v", report.Valid, report.Issues)
+			}
+			if len(report.Issues) != len(tt.problems) {
+				t.Fatalf("issues = %+v, want NSRs %v", report.Issues, tt.problems)
+			}
+			for i, nsr := range tt.problems {
+				if report.Issues[i].NSR != nsr {
+					t.Errorf("issue %d NSR = %d, want %d (%s)", i, report.Issues[i].NSR, nsr, report.Issues[i].Problem)
+				}
+			}
+		})
+	}
+}
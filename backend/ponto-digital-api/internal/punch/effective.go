@@ -0,0 +1,38 @@
+package punch
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/models"
+)
+
+// Effective retorna as marcações que valem para o cálculo da jornada: remove
+// os registros de desconsideração e as marcações desconsideradas por eles.
+// Marcações incluídas por ajuste são mantidas. A ordem de records é
+// preservada.
+func Effective(records []models.TimeRecord) []models.TimeRecord {
+	disregarded := make(map[primitive.ObjectID]bool)
+	for _, record := range records {
+		if record.Origin == models.OriginDisregarded {
+			disregarded[record.TargetID] = true
+		}
+	}
+
+	effective := make([]models.TimeRecord, 0, len(records))
+	for _, record := range records {
+		if record.Origin == models.OriginDisregarded || disregarded[record.ID] {
+			continue
+		}
+		effective = append(effective, record)
+	}
+	return effective
+}
+
+// Last retorna a última marcação efetiva de records, ordenados por horário, ou
+// nil quando não há nenhuma.
+func Last(records []models.TimeRecord) *models.TimeRecord {
+	effective := Effective(records)
+	if len(effective) == 0 {
+		return nil
+	}
+	return &effective[len(effective)-1]
+}
@@ -0,0 +1,63 @@
+// Package punch define a sequência válida de marcações de ponto: cada entrada
+// deve ser seguida de uma saída e vice-versa.
+package punch
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"ponto-digital-api/internal/models"
+)
+
+// MaxOpenDuration é o tempo máximo que uma entrada pode ficar sem a saída
+// correspondente. Depois disso a saída é considerada esquecida e a próxima
+// marcação esperada volta a ser uma entrada, iniciando uma nova jornada.
+const MaxOpenDuration = 16 * time.Hour
+
+// ErrInvalidType indica um tipo de marcação desconhecido.
+var ErrInvalidType = errors.New("Tipo de marcação inválido: use \"entrada\" ou \"saída\"")
+
+// SequenceError indica uma marcação fora da sequência esperada.
+type SequenceError struct {
+	Expected models.PunchType
+	Received models.PunchType
+}
+
+func (e *SequenceError) Error() string {
+	return fmt.Sprintf("Marcação fora de sequência: esperada %q, recebida %q", e.Expected, e.Received)
+}
+
+// Next determina o próximo tipo de marcação esperado a partir da última
+// marcação do usuário, que pode ser nil quando ele ainda não possui registros.
+func Next(last *models.TimeRecord, now time.Time) models.PunchType {
+	if last == nil {
+		return models.PunchEntrada
+	}
+
+	lastType, _ := models.ParsePunchType(string(last.Type))
+	if lastType == models.PunchEntrada && now.Sub(last.Timestamp) <= MaxOpenDuration {
+		return models.PunchSaida
+	}
+	return models.PunchEntrada
+}
+
+// Resolve valida o tipo solicitado pelo cliente contra a sequência esperada.
+// Quando requested é vazio, o tipo é determinado pelo servidor. Retorna
+// ErrInvalidType para tipos desconhecidos e *SequenceError quando o tipo não é
+// o esperado.
+func Resolve(requested string, last *models.TimeRecord, now time.Time) (models.PunchType, error) {
+	expected := Next(last, now)
+	if requested == "" {
+		return expected, nil
+	}
+
+	punchType, ok := models.ParsePunchType(requested)
+	if !ok {
+		return "", ErrInvalidType
+	}
+	if punchType != expected {
+		return "", &SequenceError{Expected: expected, Received: punchType}
+	}
+	return punchType, nil
+}
@@ -0,0 +1,62 @@
+package punch
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"ponto-digital-api/internal/models"
+)
+
+func TestResolve(t *testing.T) {
+	now := time.Date(2025, 3, 10, 18, 0, 0, 0, time.UTC)
+	entrada := &models.TimeRecord{Type: models.PunchEntrada, Timestamp: now.Add(-9 * time.Hour)}
+	saida := &models.TimeRecord{Type: models.PunchSaida, Timestamp: now.Add(-time.Hour)}
+	forgotten := &models.TimeRecord{Type: models.PunchEntrada, Timestamp: now.Add(-MaxOpenDuration - time.Minute)}
+	legacy := &models.TimeRecord{Type: "ENTRADA", Timestamp: now.Add(-time.Hour)}
+
+	tests := []struct {
+		name      string
+		requested string
+		last      *models.TimeRecord
+		want      models.PunchType
+		wantErr   error
+		sequence  bool
+	}{
+		{name: "primeira marcação", last: nil, want: models.PunchEntrada},
+		{name: "saída após entrada", last: entrada, want: models.PunchSaida},
+		{name: "entrada após saída", last: saida, want: models.PunchEntrada},
+		{name: "saída esquecida", last: forgotten, want: models.PunchEntrada},
+		{name: "tipo gravado em maiúsculas", last: legacy, want: models.PunchSaida},
+		{name: "tipo solicitado esperado", requested: "saída", last: entrada, want: models.PunchSaida},
+		{name: "tipo solicitado sem acento", requested: "saida", last: entrada, want: models.PunchSaida},
+		{name: "tipo desconhecido", requested: "intervalo", last: entrada, wantErr: ErrInvalidType},
+		{name: "duas entradas seguidas", requested: "entrada", last: entrada, sequence: true},
+		{name: "saída sem entrada", requested: "saída", last: nil, sequence: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(tt.requested, tt.last, now)
+
+			var sequenceErr *SequenceError
+			switch {
+			case tt.sequence:
+				if !errors.As(err, &sequenceErr) {
+					t.Fatalf("Resolve() error = %v, want *SequenceError", err)
+				}
+				if sequenceErr.Received == sequenceErr.Expected {
+					t.Errorf("SequenceError = %+v, want different types", sequenceErr)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Resolve() error = %v, want %v", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatalf("Resolve() error = %v", err)
+			case got != tt.want:
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package receipt
+
+import (
+	"fmt"
+	"strings"
+
+	"ponto-digital-api/internal/pdf"
+	"ponto-digital-api/internal/utils"
+)
+
+// Dimensões do comprovante em PDF: a largura de uma bobina de 80 mm.
+const (
+	pageWidth = 226.77
+	margin    = 12
+	lineGap   = 11
+)
+
+// PDF apresenta o comprovante em um documento PDF com a assinatura e os
+// dados necessários para conferi-lo.
+func (r *Receipt) PDF() []byte {
+	type line struct {
+		font pdf.Font
+		size float64
+		text string
+	}
+	lines := []line{
+		{pdf.Bold, 9, "Comprovante de Registro de Ponto"},
+		{pdf.Bold, 9, "do Trabalhador"},
+		{pdf.Regular, 0, ""},
+		{pdf.Bold, 7, "Empregador"},
+		{pdf.Regular, 7, r.EmployerName},
+		{pdf.Regular, 7, "CNPJ " + utils.FormatCNPJ(r.CNPJ)},
+	}
+	for _, text := range wrap(r.Location, 48) {
+		lines = append(lines, line{pdf.Regular, 7, text})
+	}
+	lines = append(lines,
+		line{pdf.Regular, 0, ""},
+		line{pdf.Bold, 7, "Trabalhador"},
+		line{pdf.Regular, 7, r.EmployeeName},
+		line{pdf.Regular, 7, "CPF " + utils.FormatCPF(r.CPF)},
+		line{pdf.Regular, 0, ""},
+		line{pdf.Bold, 8, fmt.Sprintf("NSR %09d", r.NSR)},
+		line{pdf.Bold, 8, r.Timestamp.Format("02/01/2006 15:04") + " - " + string(r.Type)},
+		line{pdf.Regular, 0, ""},
+	)
+	if r.INPI != "" {
+		lines = append(lines, line{pdf.Regular, 6, "Programa registrado no INPI sob o nº " + r.INPI})
+	}
+	lines = append(lines, line{pdf.Bold, 6, "Hash do registro"})
+	for _, text := range wrap(r.Hash, 40) {
+		lines = append(lines, line{pdf.Regular, 6, text})
+	}
+	lines = append(lines, line{pdf.Bold, 6, "Registro " + r.RecordID}, line{pdf.Bold, 6, "Assinatura"})
+	for _, text := range wrap(r.Signature, 44) {
+		lines = append(lines, line{pdf.Regular, 6, text})
+	}
+
+	doc := pdf.New("Comprovante de Registro de Ponto")
+	page := doc.AddPage(pageWidth, float64(2*margin+len(lines)*lineGap))
+	y := float64(margin + lineGap)
+	for _, l := range lines {
+		if l.text != "" {
+			page.Text(margin, y, l.font, l.size, l.text)
+		}
+		y += lineGap
+	}
+	return doc.Bytes()
+}
+
+// wrap divide text em linhas de até width caracteres, de preferência nos
+// espaços.
+func wrap(text string, width int) []string {
+	runes := []rune(text)
+	var lines []string
+	for len(runes) > width {
+		cut := width
+		for i := width; i > 0; i-- {
+			if runes[i] == ' ' {
+				cut = i
+				break
+			}
+		}
+		lines = append(lines, string(runes[:cut]))
+		runes = []rune(strings.TrimLeft(string(runes[cut:]), " "))
+	}
+	if len(runes) > 0 {
+		lines = append(lines, string(runes))
+	}
+	return lines
+}
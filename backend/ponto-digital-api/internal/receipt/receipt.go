@@ -0,0 +1,174 @@
+// Package receipt emite o comprovante de registro de ponto do trabalhador
+// exigido pela Portaria 671 e confere os comprovantes apresentados.
+package receipt
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/afd"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/signature"
+)
+
+var (
+	ErrInvalidSignature = errors.New("Assinatura do comprovante inválida")
+	ErrRecordNotFound   = errors.New("Registro do comprovante não encontrado")
+	ErrMismatch         = errors.New("Comprovante não corresponde ao registro gravado")
+)
+
+// Receipt é o comprovante de uma marcação. Signature é a assinatura, em
+// base64, dos demais campos.
+type Receipt struct {
+	RecordID     string           `json:"record_id"`
+	EmployerName string           `json:"employer_name"`
+	CNPJ         string           `json:"cnpj"`
+	Location     string           `json:"location,omitempty"` // local de prestação de serviços
+	EmployeeName string           `json:"employee_name"`
+	CPF          string           `json:"cpf"`
+	NSR          int64            `json:"nsr"`
+	Timestamp    time.Time        `json:"timestamp"`
+	Type         models.PunchType `json:"type"`
+	INPI         string           `json:"inpi,omitempty"` // registro do programa no INPI
+	Hash         string           `json:"hash"`           // hash encadeado do registro
+	Signature    string           `json:"signature"`
+}
+
+// payload é o conteúdo assinado do comprovante. O horário entra em
+// milissegundos para não depender do fuso nem da formatação.
+func (r *Receipt) payload() []byte {
+	data, _ := json.Marshal([]string{
+		r.RecordID,
+		r.EmployerName,
+		r.CNPJ,
+		r.Location,
+		r.EmployeeName,
+		r.CPF,
+		strconv.FormatInt(r.NSR, 10),
+		strconv.FormatInt(r.Timestamp.UnixMilli(), 10),
+		string(r.Type),
+		r.INPI,
+		r.Hash,
+	})
+	return data
+}
+
+// Service emite e confere comprovantes com o certificado do servidor.
+type Service struct {
+	store    *repository.Store
+	signer   *signature.Signer
+	inpi     string
+	location *time.Location
+}
+
+// NewService cria um Service que assina com signer, identifica o programa
+// pelo registro inpi e apresenta os horários no fuso loc.
+func NewService(store *repository.Store, signer *signature.Signer, inpi string, loc *time.Location) *Service {
+	return &Service{store: store, signer: signer, inpi: inpi, location: loc}
+}
+
+// Issue emite o comprovante assinado da marcação.
+func (s *Service) Issue(ctx context.Context, record *models.TimeRecord) (*Receipt, error) {
+	user, err := s.store.Users.FindByID(ctx, record.CompanyID, record.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := &Receipt{
+		RecordID:     record.ID.Hex(),
+		EmployeeName: user.Name,
+		CPF:          user.CPF,
+		NSR:          record.NSR,
+		Timestamp:    record.Timestamp.In(s.location).Truncate(time.Millisecond),
+		Type:         record.Type,
+		INPI:         s.inpi,
+		Hash:         record.Hash,
+	}
+	// Cadastros anteriores às empresas não têm empregador
+	if !record.CompanyID.IsZero() {
+		company, err := s.store.Companies.FindByID(ctx, record.CompanyID)
+		if err != nil {
+			return nil, err
+		}
+		receipt.EmployerName = company.Name
+		receipt.CNPJ = company.CNPJ
+	}
+	if !record.BranchID.IsZero() {
+		branch, err := s.store.Branches.FindByID(ctx, record.CompanyID, record.BranchID)
+		if err != nil {
+			return nil, err
+		}
+		receipt.CNPJ = branch.CNPJ
+		receipt.Location = afd.BranchLocation(branch)
+	}
+
+	signed, err := s.signer.Sign(receipt.payload())
+	if err != nil {
+		return nil, err
+	}
+	receipt.Signature = base64.StdEncoding.EncodeToString(signed)
+	return receipt, nil
+}
+
+// Verify confere a assinatura do comprovante e se ele corresponde a um
+// registro gravado, retornando esse registro. Os erros ErrInvalidSignature,
+// ErrRecordNotFound e ErrMismatch indicam um comprovante inválido.
+func (s *Service) Verify(ctx context.Context, receipt *Receipt) (*models.TimeRecord, error) {
+	signed, err := base64.StdEncoding.DecodeString(receipt.Signature)
+	if err != nil || s.signer.Verify(receipt.payload(), signed) != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	id, err := primitive.ObjectIDFromHex(receipt.RecordID)
+	if err != nil {
+		return nil, ErrRecordNotFound
+	}
+	companyID, err := s.employer(ctx, receipt.CNPJ)
+	if err != nil {
+		return nil, err
+	}
+	record, err := s.store.TimeRecords.FindByID(ctx, companyID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if record.NSR != receipt.NSR || record.Hash != receipt.Hash || record.Type != receipt.Type ||
+		record.Timestamp.UnixMilli() != receipt.Timestamp.UnixMilli() {
+		return nil, ErrMismatch
+	}
+	return record, nil
+}
+
+// employer localiza a empresa do estabelecimento ou da sede com o CNPJ do
+// comprovante. O CNPJ vazio é o dos cadastros anteriores às empresas.
+func (s *Service) employer(ctx context.Context, cnpj string) (primitive.ObjectID, error) {
+	if cnpj == "" {
+		return primitive.NilObjectID, nil
+	}
+
+	branch, err := s.store.Branches.FindByCNPJ(ctx, cnpj)
+	if err == nil {
+		return branch.CompanyID, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return primitive.NilObjectID, err
+	}
+
+	company, err := s.store.Companies.FindByCNPJ(ctx, cnpj)
+	if errors.Is(err, repository.ErrNotFound) {
+		return primitive.NilObjectID, ErrRecordNotFound
+	}
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return company.ID, nil
+}
@@ -0,0 +1,92 @@
+package receipt
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/signature"
+)
+
+func TestIssueVerify(t *testing.T) {
+	ctx := context.Background()
+	loc := time.FixedZone("BRT", -3*60*60)
+
+	store := repository.NewMemoryStore()
+	company := models.Company{Name: "ACME", CNPJ: "11222333000181"}
+	if err := store.Companies.Create(ctx, &company); err != nil {
+		t.Fatal(err)
+	}
+	branch := models.Branch{CompanyID: company.ID, CNPJ: "11222333000262", City: "Recife", State: "PE"}
+	if err := store.Branches.Create(ctx, &branch); err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{Name: "Ana", CPF: "12345678909", CompanyID: company.ID, BranchID: branch.ID}
+	if err := store.Users.Create(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+	record := models.TimeRecord{CompanyID: company.ID, BranchID: branch.ID, UserID: user.ID, Timestamp: time.Now()}
+	if err := store.TimeRecords.CreatePunch(ctx, &record, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := signature.NewSelfSigned("Ponto Digital")
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewService(store, signer, "BR512025000001", loc)
+
+	issued, err := service.Issue(ctx, &record)
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if issued.CNPJ != branch.CNPJ || issued.Location != "Recife - PE" || issued.EmployerName != company.Name {
+		t.Errorf("employer = %q, %q, %q, want the branch CNPJ and location", issued.EmployerName, issued.CNPJ, issued.Location)
+	}
+	if issued.NSR != 1 || issued.Hash != record.Hash || issued.CPF != user.CPF {
+		t.Errorf("NSR = %d, hash = %q, CPF = %q, want the stored record", issued.NSR, issued.Hash, issued.CPF)
+	}
+
+	// resign simula um comprovante assinado pelo servidor com outros dados
+	resign := func(r *Receipt) {
+		signed, err := signer.Sign(r.payload())
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Signature = base64.StdEncoding.EncodeToString(signed)
+	}
+
+	tests := []struct {
+		name    string
+		change  func(r *Receipt)
+		wantErr error
+	}{
+		{name: "comprovante íntegro", change: func(r *Receipt) {}},
+		{name: "NSR adulterado", change: func(r *Receipt) { r.NSR++ }, wantErr: ErrInvalidSignature},
+		{name: "nome adulterado", change: func(r *Receipt) { r.EmployeeName = "Bruno" }, wantErr: ErrInvalidSignature},
+		{name: "assinatura ilegível", change: func(r *Receipt) { r.Signature = "%%%" }, wantErr: ErrInvalidSignature},
+		{name: "registro inexistente", change: func(r *Receipt) { r.RecordID = primitive.NewObjectID().Hex(); resign(r) }, wantErr: ErrRecordNotFound},
+		{name: "CNPJ desconhecido", change: func(r *Receipt) { r.CNPJ = "99888777000166"; resign(r) }, wantErr: ErrRecordNotFound},
+		{name: "registro divergente", change: func(r *Receipt) { r.Timestamp = r.Timestamp.Add(time.Minute); resign(r) }, wantErr: ErrMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receipt := *issued
+			tt.change(&receipt)
+
+			got, err := service.Verify(ctx, &receipt)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got.ID != record.ID {
+				t.Errorf("Verify() record = %s, want %s", got.ID.Hex(), record.ID.Hex())
+			}
+		})
+	}
+}
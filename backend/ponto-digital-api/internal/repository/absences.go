@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"ponto-digital-api/internal/models"
+)
+
+// AbsenceRepository persiste as ausências justificadas. Todas as consultas
+// são restritas à empresa informada.
+type AbsenceRepository interface {
+	// Create insere a ausência e preenche absence.ID com o identificador
+	// gerado.
+	Create(ctx context.Context, absence *models.Absence) error
+	FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.Absence, error)
+	// List retorna as ausências que atendem ao filtro, ordenadas pela data de
+	// início.
+	List(ctx context.Context, filter AbsenceFilter) ([]models.Absence, error)
+	// Update substitui o documento da ausência identificada por absence.ID
+	// dentro da empresa absence.CompanyID.
+	Update(ctx context.Context, absence *models.Absence) error
+}
+
+// AbsenceFilter restringe as ausências retornadas por List. CompanyID é
+// sempre aplicado; os demais campos, quando vazios, não filtram. From e To
+// selecionam as ausências que têm algum dia em [From, To].
+type AbsenceFilter struct {
+	CompanyID primitive.ObjectID
+	UserIDs   []primitive.ObjectID
+	Statuses  []models.AbsenceStatus
+	From      time.Time
+	To        time.Time
+}
+
+func (f AbsenceFilter) matches(absence *models.Absence) bool {
+	if absence.CompanyID != f.CompanyID {
+		return false
+	}
+	if f.UserIDs != nil && !containsID(f.UserIDs, absence.UserID) {
+		return false
+	}
+	if len(f.Statuses) > 0 {
+		found := false
+		for _, status := range f.Statuses {
+			found = found || absence.Status == status
+		}
+		if !found {
+			return false
+		}
+	}
+	if !f.From.IsZero() && absence.EndDate.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && absence.StartDate.After(f.To) {
+		return false
+	}
+	return true
+}
+
+type mongoAbsenceRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoAbsenceRepository cria um AbsenceRepository sobre a coleção
+// "absences".
+func NewMongoAbsenceRepository(db *mongo.Database) AbsenceRepository {
+	return &mongoAbsenceRepository{collection: db.Collection("absences")}
+}
+
+func (r *mongoAbsenceRepository) Create(ctx context.Context, absence *models.Absence) error {
+	if absence.ID.IsZero() {
+		absence.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, absence)
+	return err
+}
+
+func (r *mongoAbsenceRepository) FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.Absence, error) {
+	var absence models.Absence
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "company_id": tenantFilter(companyID)}).Decode(&absence)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &absence, nil
+}
+
+func (r *mongoAbsenceRepository) List(ctx context.Context, filter AbsenceFilter) ([]models.Absence, error) {
+	query := bson.M{"company_id": tenantFilter(filter.CompanyID)}
+	if filter.UserIDs != nil {
+		query["user_id"] = bson.M{"$in": filter.UserIDs}
+	}
+	if len(filter.Statuses) > 0 {
+		query["status"] = bson.M{"$in": filter.Statuses}
+	}
+	if !filter.From.IsZero() {
+		query["end_date"] = bson.M{"$gte": filter.From}
+	}
+	if !filter.To.IsZero() {
+		query["start_date"] = bson.M{"$lte": filter.To}
+	}
+	opts := options.Find().SetSort(bson.M{"start_date": 1})
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	absences := []models.Absence{}
+	if err := cursor.All(ctx, &absences); err != nil {
+		return nil, err
+	}
+	return absences, nil
+}
+
+func (r *mongoAbsenceRepository) Update(ctx context.Context, absence *models.Absence) error {
+	filter := bson.M{"_id": absence.ID, "company_id": tenantFilter(absence.CompanyID)}
+	result, err := r.collection.ReplaceOne(ctx, filter, absence)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type memoryAbsenceRepository struct {
+	mu       sync.RWMutex
+	absences map[primitive.ObjectID]models.Absence
+}
+
+// NewMemoryAbsenceRepository cria um AbsenceRepository mantido em memória.
+func NewMemoryAbsenceRepository() AbsenceRepository {
+	return &memoryAbsenceRepository{absences: make(map[primitive.ObjectID]models.Absence)}
+}
+
+func (r *memoryAbsenceRepository) Create(ctx context.Context, absence *models.Absence) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if absence.ID.IsZero() {
+		absence.ID = primitive.NewObjectID()
+	}
+	r.absences[absence.ID] = *absence
+	return nil
+}
+
+func (r *memoryAbsenceRepository) FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.Absence, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	absence, ok := r.absences[id]
+	if !ok || absence.CompanyID != companyID {
+		return nil, ErrNotFound
+	}
+	return &absence, nil
+}
+
+func (r *memoryAbsenceRepository) List(ctx context.Context, filter AbsenceFilter) ([]models.Absence, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	absences := []models.Absence{}
+	for _, absence := range r.absences {
+		if filter.matches(&absence) {
+			absences = append(absences, absence)
+		}
+	}
+
+	sort.Slice(absences, func(i, j int) bool {
+		return absences[i].StartDate.Before(absences[j].StartDate)
+	})
+	return absences, nil
+}
+
+func (r *memoryAbsenceRepository) Update(ctx context.Context, absence *models.Absence) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.absences[absence.ID]
+	if !ok || current.CompanyID != absence.CompanyID {
+		return ErrNotFound
+	}
+	r.absences[absence.ID] = *absence
+	return nil
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"ponto-digital-api/internal/models"
+)
+
+// AcknowledgementRepository persiste as assinaturas dos espelhos de ponto.
+// As assinaturas não são alteradas nem removidas.
+type AcknowledgementRepository interface {
+	// Create insere a assinatura e preenche acknowledgement.ID com o
+	// identificador gerado.
+	Create(ctx context.Context, acknowledgement *models.Acknowledgement) error
+	// List retorna as assinaturas que atendem ao filtro, das mais antigas
+	// para as mais recentes.
+	List(ctx context.Context, filter AcknowledgementFilter) ([]models.Acknowledgement, error)
+}
+
+// AcknowledgementFilter restringe as assinaturas retornadas por List.
+// CompanyID é sempre aplicado; os demais campos, quando vazios, não filtram.
+type AcknowledgementFilter struct {
+	CompanyID primitive.ObjectID
+	UserIDs   []primitive.ObjectID
+	Year      int
+	Month     int
+}
+
+func (f AcknowledgementFilter) matches(acknowledgement *models.Acknowledgement) bool {
+	if acknowledgement.CompanyID != f.CompanyID {
+		return false
+	}
+	if f.UserIDs != nil && !containsID(f.UserIDs, acknowledgement.UserID) {
+		return false
+	}
+	if f.Year != 0 && acknowledgement.Year != f.Year {
+		return false
+	}
+	if f.Month != 0 && acknowledgement.Month != f.Month {
+		return false
+	}
+	return true
+}
+
+type mongoAcknowledgementRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoAcknowledgementRepository cria um AcknowledgementRepository sobre a
+// coleção "acknowledgements".
+func NewMongoAcknowledgementRepository(db *mongo.Database) AcknowledgementRepository {
+	return &mongoAcknowledgementRepository{collection: db.Collection("acknowledgements")}
+}
+
+func (r *mongoAcknowledgementRepository) Create(ctx context.Context, acknowledgement *models.Acknowledgement) error {
+	if acknowledgement.ID.IsZero() {
+		acknowledgement.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, acknowledgement)
+	return err
+}
+
+func (r *mongoAcknowledgementRepository) List(ctx context.Context, filter AcknowledgementFilter) ([]models.Acknowledgement, error) {
+	query := bson.M{"company_id": tenantFilter(filter.CompanyID)}
+	if filter.UserIDs != nil {
+		query["user_id"] = bson.M{"$in": filter.UserIDs}
+	}
+	if filter.Year != 0 {
+		query["year"] = filter.Year
+	}
+	if filter.Month != 0 {
+		query["month"] = filter.Month
+	}
+	opts := options.Find().SetSort(bson.M{"created_at": 1})
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	acknowledgements := []models.Acknowledgement{}
+	if err := cursor.All(ctx, &acknowledgements); err != nil {
+		return nil, err
+	}
+	return acknowledgements, nil
+}
+
+type memoryAcknowledgementRepository struct {
+	mu               sync.RWMutex
+	acknowledgements []models.Acknowledgement
+}
+
+// NewMemoryAcknowledgementRepository cria um AcknowledgementRepository
+// mantido em memória.
+func NewMemoryAcknowledgementRepository() AcknowledgementRepository {
+	return &memoryAcknowledgementRepository{}
+}
+
+func (r *memoryAcknowledgementRepository) Create(ctx context.Context, acknowledgement *models.Acknowledgement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if acknowledgement.ID.IsZero() {
+		acknowledgement.ID = primitive.NewObjectID()
+	}
+	r.acknowledgements = append(r.acknowledgements, *acknowledgement)
+	return nil
+}
+
+func (r *memoryAcknowledgementRepository) List(ctx context.Context, filter AcknowledgementFilter) ([]models.Acknowledgement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	acknowledgements := []models.Acknowledgement{}
+	for _, acknowledgement := range r.acknowledgements {
+		if filter.matches(&acknowledgement) {
+			acknowledgements = append(acknowledgements, acknowledgement)
+		}
+	}
+
+	sort.SliceStable(acknowledgements, func(i, j int) bool {
+		return acknowledgements[i].CreatedAt.Before(acknowledgements[j].CreatedAt)
+	})
+	return acknowledgements, nil
+}
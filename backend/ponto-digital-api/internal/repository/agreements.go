@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"ponto-digital-api/internal/models"
+)
+
+// AgreementRepository persiste as convenções coletivas das empresas. Todas as
+// consultas são restritas à empresa informada.
+type AgreementRepository interface {
+	// Create insere a convenção e preenche agreement.ID com o identificador
+	// gerado.
+	Create(ctx context.Context, agreement *models.CollectiveAgreement) error
+	FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.CollectiveAgreement, error)
+	// ListByCompany retorna as convenções da empresa ordenadas por nome.
+	ListByCompany(ctx context.Context, companyID primitive.ObjectID) ([]models.CollectiveAgreement, error)
+	// Update substitui o documento da convenção identificada por agreement.ID
+	// dentro da empresa agreement.CompanyID.
+	Update(ctx context.Context, agreement *models.CollectiveAgreement) error
+}
+
+type mongoAgreementRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoAgreementRepository cria um AgreementRepository sobre a coleção
+// "agreements".
+func NewMongoAgreementRepository(db *mongo.Database) AgreementRepository {
+	return &mongoAgreementRepository{collection: db.Collection("agreements")}
+}
+
+func (r *mongoAgreementRepository) Create(ctx context.Context, agreement *models.CollectiveAgreement) error {
+	if agreement.ID.IsZero() {
+		agreement.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, agreement)
+	return err
+}
+
+func (r *mongoAgreementRepository) FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.CollectiveAgreement, error) {
+	return r.findOne(ctx, bson.M{"_id": id, "company_id": tenantFilter(companyID)})
+}
+
+func (r *mongoAgreementRepository) findOne(ctx context.Context, filter bson.M) (*models.CollectiveAgreement, error) {
+	var agreement models.CollectiveAgreement
+	err := r.collection.FindOne(ctx, filter).Decode(&agreement)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &agreement, nil
+}
+
+func (r *mongoAgreementRepository) ListByCompany(ctx context.Context, companyID primitive.ObjectID) ([]models.CollectiveAgreement, error) {
+	opts := options.Find().SetSort(bson.M{"name": 1})
+	cursor, err := r.collection.Find(ctx, bson.M{"company_id": tenantFilter(companyID)}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	agreements := []models.CollectiveAgreement{}
+	if err := cursor.All(ctx, &agreements); err != nil {
+		return nil, err
+	}
+	return agreements, nil
+}
+
+func (r *mongoAgreementRepository) Update(ctx context.Context, agreement *models.CollectiveAgreement) error {
+	filter := bson.M{"_id": agreement.ID, "company_id": tenantFilter(agreement.CompanyID)}
+	result, err := r.collection.ReplaceOne(ctx, filter, agreement)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type memoryAgreementRepository struct {
+	mu         sync.RWMutex
+	agreements map[primitive.ObjectID]models.CollectiveAgreement
+}
+
+// NewMemoryAgreementRepository cria um AgreementRepository mantido em memória.
+func NewMemoryAgreementRepository() AgreementRepository {
+	return &memoryAgreementRepository{agreements: make(map[primitive.ObjectID]models.CollectiveAgreement)}
+}
+
+func (r *memoryAgreementRepository) Create(ctx context.Context, agreement *models.CollectiveAgreement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if agreement.ID.IsZero() {
+		agreement.ID = primitive.NewObjectID()
+	}
+	r.agreements[agreement.ID] = *agreement
+	return nil
+}
+
+func (r *memoryAgreementRepository) FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.CollectiveAgreement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	agreement, ok := r.agreements[id]
+	if !ok || agreement.CompanyID != companyID {
+		return nil, ErrNotFound
+	}
+	return &agreement, nil
+}
+
+func (r *memoryAgreementRepository) ListByCompany(ctx context.Context, companyID primitive.ObjectID) ([]models.CollectiveAgreement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	agreements := []models.CollectiveAgreement{}
+	for _, agreement := range r.agreements {
+		if agreement.CompanyID == companyID {
+			agreements = append(agreements, agreement)
+		}
+	}
+
+	sort.Slice(agreements, func(i, j int) bool {
+		return agreements[i].Name < agreements[j].Name
+	})
+	return agreements, nil
+}
+
+func (r *memoryAgreementRepository) Update(ctx context.Context, agreement *models.CollectiveAgreement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.agreements[agreement.ID]
+	if !ok || current.CompanyID != agreement.CompanyID {
+		return ErrNotFound
+	}
+	r.agreements[agreement.ID] = *agreement
+	return nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"ponto-digital-api/internal/models"
+)
+
+// AttachmentRepository persiste os dados dos arquivos enviados; o conteúdo
+// fica no FileStore. As consultas são restritas à empresa informada.
+type AttachmentRepository interface {
+	// Create insere o anexo e preenche attachment.ID com o identificador
+	// gerado.
+	Create(ctx context.Context, attachment *models.Attachment) error
+	FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.Attachment, error)
+}
+
+type mongoAttachmentRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoAttachmentRepository cria um AttachmentRepository sobre a coleção
+// "attachments".
+func NewMongoAttachmentRepository(db *mongo.Database) AttachmentRepository {
+	return &mongoAttachmentRepository{collection: db.Collection("attachments")}
+}
+
+func (r *mongoAttachmentRepository) Create(ctx context.Context, attachment *models.Attachment) error {
+	if attachment.ID.IsZero() {
+		attachment.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, attachment)
+	return err
+}
+
+func (r *mongoAttachmentRepository) FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.Attachment, error) {
+	var attachment models.Attachment
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "company_id": tenantFilter(companyID)}).Decode(&attachment)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+type memoryAttachmentRepository struct {
+	mu          sync.RWMutex
+	attachments map[primitive.ObjectID]models.Attachment
+}
+
+// NewMemoryAttachmentRepository cria um AttachmentRepository mantido em
+// memória.
+func NewMemoryAttachmentRepository() AttachmentRepository {
+	return &memoryAttachmentRepository{attachments: make(map[primitive.ObjectID]models.Attachment)}
+}
+
+func (r *memoryAttachmentRepository) Create(ctx context.Context, attachment *models.Attachment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if attachment.ID.IsZero() {
+		attachment.ID = primitive.NewObjectID()
+	}
+	r.attachments[attachment.ID] = *attachment
+	return nil
+}
+
+func (r *memoryAttachmentRepository) FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.Attachment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	attachment, ok := r.attachments[id]
+	if !ok || attachment.CompanyID != companyID {
+		return nil, ErrNotFound
+	}
+	return &attachment, nil
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"ponto-digital-api/internal/models"
+)
+
+// BranchRepository persiste os estabelecimentos das empresas. Todas as
+// consultas são restritas à empresa informada.
+type BranchRepository interface {
+	// Create insere o estabelecimento e preenche branch.ID com o identificador
+	// gerado.
+	Create(ctx context.Context, branch *models.Branch) error
+	FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.Branch, error)
+	FindByCNPJ(ctx context.Context, cnpj string) (*models.Branch, error)
+	// ListByCompany retorna os estabelecimentos da empresa ordenados por nome.
+	ListByCompany(ctx context.Context, companyID primitive.ObjectID) ([]models.Branch, error)
+	// Update substitui o documento do estabelecimento identificado por
+	// branch.ID dentro da empresa branch.CompanyID.
+	Update(ctx context.Context, branch *models.Branch) error
+}
+
+type mongoBranchRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoBranchRepository cria um BranchRepository sobre a coleção
+// "branches".
+func NewMongoBranchRepository(db *mongo.Database) BranchRepository {
+	return &mongoBranchRepository{collection: db.Collection("branches")}
+}
+
+func (r *mongoBranchRepository) Create(ctx context.Context, branch *models.Branch) error {
+	if branch.ID.IsZero() {
+		branch.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, branch)
+	return err
+}
+
+func (r *mongoBranchRepository) FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.Branch, error) {
+	return r.findOne(ctx, bson.M{"_id": id, "company_id": companyID})
+}
+
+func (r *mongoBranchRepository) FindByCNPJ(ctx context.Context, cnpj string) (*models.Branch, error) {
+	return r.findOne(ctx, bson.M{"cnpj": cnpj})
+}
+
+func (r *mongoBranchRepository) findOne(ctx context.Context, filter bson.M) (*models.Branch, error) {
+	var branch models.Branch
+	err := r.collection.FindOne(ctx, filter).Decode(&branch)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &branch, nil
+}
+
+func (r *mongoBranchRepository) ListByCompany(ctx context.Context, companyID primitive.ObjectID) ([]models.Branch, error) {
+	opts := options.Find().SetSort(bson.M{"name": 1})
+	cursor, err := r.collection.Find(ctx, bson.M{"company_id": companyID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	branches := []models.Branch{}
+	if err := cursor.All(ctx, &branches); err != nil {
+		return nil, err
+	}
+	return branches, nil
+}
+
+func (r *mongoBranchRepository) Update(ctx context.Context, branch *models.Branch) error {
+	filter := bson.M{"_id": branch.ID, "company_id": branch.CompanyID}
+	result, err := r.collection.ReplaceOne(ctx, filter, branch)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type memoryBranchRepository struct {
+	mu       sync.RWMutex
+	branches map[primitive.ObjectID]models.Branch
+}
+
+// NewMemoryBranchRepository cria um BranchRepository mantido em memória.
+func NewMemoryBranchRepository() BranchRepository {
+	return &memoryBranchRepository{branches: make(map[primitive.ObjectID]models.Branch)}
+}
+
+func (r *memoryBranchRepository) Create(ctx context.Context, branch *models.Branch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if branch.ID.IsZero() {
+		branch.ID = primitive.NewObjectID()
+	}
+	r.branches[branch.ID] = *branch
+	return nil
+}
+
+func (r *memoryBranchRepository) FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.Branch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	branch, ok := r.branches[id]
+	if !ok || branch.CompanyID != companyID {
+		return nil, ErrNotFound
+	}
+	return &branch, nil
+}
+
+func (r *memoryBranchRepository) FindByCNPJ(ctx context.Context, cnpj string) (*models.Branch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, branch := range r.branches {
+		if branch.CNPJ == cnpj {
+			return &branch, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryBranchRepository) ListByCompany(ctx context.Context, companyID primitive.ObjectID) ([]models.Branch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	branches := []models.Branch{}
+	for _, branch := range r.branches {
+		if branch.CompanyID == companyID {
+			branches = append(branches, branch)
+		}
+	}
+
+	sort.Slice(branches, func(i, j int) bool {
+		return branches[i].Name < branches[j].Name
+	})
+	return branches, nil
+}
+
+func (r *memoryBranchRepository) Update(ctx context.Context, branch *models.Branch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.branches[branch.ID]
+	if !ok || current.CompanyID != branch.CompanyID {
+		return ErrNotFound
+	}
+	r.branches[branch.ID] = *branch
+	return nil
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"ponto-digital-api/internal/models"
+)
+
+// ClosingRepository persiste os fechamentos de competência. Cada empresa tem
+// no máximo um fechamento por mês, que guarda as reaberturas.
+type ClosingRepository interface {
+	// Create insere o fechamento e preenche closing.ID com o identificador
+	// gerado.
+	Create(ctx context.Context, closing *models.PeriodClosing) error
+	// FindByMonth retorna o fechamento do mês da empresa ou ErrNotFound.
+	FindByMonth(ctx context.Context, companyID primitive.ObjectID, year, month int) (*models.PeriodClosing, error)
+	// List retorna os fechamentos da empresa, do mês mais recente para o
+	// mais antigo.
+	List(ctx context.Context, companyID primitive.ObjectID) ([]models.PeriodClosing, error)
+	// Update substitui o documento do fechamento identificado por closing.ID
+	// dentro da empresa closing.CompanyID.
+	Update(ctx context.Context, closing *models.PeriodClosing) error
+}
+
+type mongoClosingRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoClosingRepository cria um ClosingRepository sobre a coleção
+// "period_closings".
+func NewMongoClosingRepository(db *mongo.Database) ClosingRepository {
+	return &mongoClosingRepository{collection: db.Collection("period_closings")}
+}
+
+func (r *mongoClosingRepository) Create(ctx context.Context, closing *models.PeriodClosing) error {
+	if closing.ID.IsZero() {
+		closing.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, closing)
+	return err
+}
+
+func (r *mongoClosingRepository) FindByMonth(ctx context.Context, companyID primitive.ObjectID, year, month int) (*models.PeriodClosing, error) {
+	filter := bson.M{"company_id": tenantFilter(companyID), "year": year, "month": month}
+
+	var closing models.PeriodClosing
+	err := r.collection.FindOne(ctx, filter).Decode(&closing)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &closing, nil
+}
+
+func (r *mongoClosingRepository) List(ctx context.Context, companyID primitive.ObjectID) ([]models.PeriodClosing, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "year", Value: -1}, {Key: "month", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"company_id": tenantFilter(companyID)}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	closings := []models.PeriodClosing{}
+	if err := cursor.All(ctx, &closings); err != nil {
+		return nil, err
+	}
+	return closings, nil
+}
+
+func (r *mongoClosingRepository) Update(ctx context.Context, closing *models.PeriodClosing) error {
+	filter := bson.M{"_id": closing.ID, "company_id": tenantFilter(closing.CompanyID)}
+	result, err := r.collection.ReplaceOne(ctx, filter, closing)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type memoryClosingRepository struct {
+	mu       sync.RWMutex
+	closings map[primitive.ObjectID]models.PeriodClosing
+}
+
+// NewMemoryClosingRepository cria um ClosingRepository mantido em memória.
+func NewMemoryClosingRepository() ClosingRepository {
+	return &memoryClosingRepository{closings: make(map[primitive.ObjectID]models.PeriodClosing)}
+}
+
+func (r *memoryClosingRepository) Create(ctx context.Context, closing *models.PeriodClosing) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if closing.ID.IsZero() {
+		closing.ID = primitive.NewObjectID()
+	}
+	r.closings[closing.ID] = *closing
+	return nil
+}
+
+func (r *memoryClosingRepository) FindByMonth(ctx context.Context, companyID primitive.ObjectID, year, month int) (*models.PeriodClosing, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, closing := range r.closings {
+		if closing.CompanyID == companyID && closing.Year == year && closing.Month == month {
+			return &closing, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryClosingRepository) List(ctx context.Context, companyID primitive.ObjectID) ([]models.PeriodClosing, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	closings := []models.PeriodClosing{}
+	for _, closing := range r.closings {
+		if closing.CompanyID == companyID {
+			closings = append(closings, closing)
+		}
+	}
+
+	sort.Slice(closings, func(i, j int) bool {
+		if closings[i].Year != closings[j].Year {
+			return closings[i].Year > closings[j].Year
+		}
+		return closings[i].Month > closings[j].Month
+	})
+	return closings, nil
+}
+
+func (r *memoryClosingRepository) Update(ctx context.Context, closing *models.PeriodClosing) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.closings[closing.ID]
+	if !ok || current.CompanyID != closing.CompanyID {
+		return ErrNotFound
+	}
+	r.closings[closing.ID] = *closing
+	return nil
+}
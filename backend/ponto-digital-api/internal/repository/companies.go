@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"ponto-digital-api/internal/models"
+)
+
+// CompanyRepository persiste as empresas (tenants) atendidas pela API.
+type CompanyRepository interface {
+	// Create insere a empresa e preenche company.ID com o identificador gerado.
+	Create(ctx context.Context, company *models.Company) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Company, error)
+	FindByCNPJ(ctx context.Context, cnpj string) (*models.Company, error)
+	// Update substitui o documento da empresa identificada por company.ID.
+	Update(ctx context.Context, company *models.Company) error
+	// Delete remove a empresa. É usado apenas para desfazer um cadastro
+	// incompleto, antes de existirem dados vinculados a ela.
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+type mongoCompanyRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoCompanyRepository cria um CompanyRepository sobre a coleção
+// "companies".
+func NewMongoCompanyRepository(db *mongo.Database) CompanyRepository {
+	return &mongoCompanyRepository{collection: db.Collection("companies")}
+}
+
+func (r *mongoCompanyRepository) Create(ctx context.Context, company *models.Company) error {
+	if company.ID.IsZero() {
+		company.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, company)
+	return err
+}
+
+func (r *mongoCompanyRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Company, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoCompanyRepository) FindByCNPJ(ctx context.Context, cnpj string) (*models.Company, error) {
+	return r.findOne(ctx, bson.M{"cnpj": cnpj})
+}
+
+func (r *mongoCompanyRepository) findOne(ctx context.Context, filter bson.M) (*models.Company, error) {
+	var company models.Company
+	err := r.collection.FindOne(ctx, filter).Decode(&company)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &company, nil
+}
+
+func (r *mongoCompanyRepository) Update(ctx context.Context, company *models.Company) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": company.ID}, company)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoCompanyRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type memoryCompanyRepository struct {
+	mu        sync.RWMutex
+	companies map[primitive.ObjectID]models.Company
+}
+
+// NewMemoryCompanyRepository cria um CompanyRepository mantido em memória.
+func NewMemoryCompanyRepository() CompanyRepository {
+	return &memoryCompanyRepository{companies: make(map[primitive.ObjectID]models.Company)}
+}
+
+func (r *memoryCompanyRepository) Create(ctx context.Context, company *models.Company) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if company.ID.IsZero() {
+		company.ID = primitive.NewObjectID()
+	}
+	r.companies[company.ID] = *company
+	return nil
+}
+
+func (r *memoryCompanyRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Company, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	company, ok := r.companies[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &company, nil
+}
+
+func (r *memoryCompanyRepository) FindByCNPJ(ctx context.Context, cnpj string) (*models.Company, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, company := range r.companies {
+		if company.CNPJ == cnpj {
+			return &company, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryCompanyRepository) Update(ctx context.Context, company *models.Company) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.companies[company.ID]; !ok {
+		return ErrNotFound
+	}
+	r.companies[company.ID] = *company
+	return nil
+}
+
+func (r *memoryCompanyRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.companies[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.companies, id)
+	return nil
+}
@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"ponto-digital-api/internal/models"
+)
+
+// CorrectionRepository persiste as solicitações de ajuste de ponto. Todas as
+// consultas são restritas à empresa informada.
+type CorrectionRepository interface {
+	// Create insere a solicitação e preenche correction.ID com o
+	// identificador gerado.
+	Create(ctx context.Context, correction *models.CorrectionRequest) error
+	FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.CorrectionRequest, error)
+	// List retorna as solicitações que atendem ao filtro, das mais recentes
+	// para as mais antigas.
+	List(ctx context.Context, filter CorrectionFilter) ([]models.CorrectionRequest, error)
+	// Review grava a análise da solicitação correction.ID (status, revisor,
+	// observação e datas) desde que ela ainda esteja pendente. Retorna
+	// ErrNotFound quando a solicitação não existe ou já foi analisada, o que
+	// impede que duas análises simultâneas apliquem o mesmo ajuste.
+	Review(ctx context.Context, correction *models.CorrectionRequest) error
+	// SetApplied grava correction.AppliedID, o registro gerado pela
+	// aprovação.
+	SetApplied(ctx context.Context, correction *models.CorrectionRequest) error
+	// Reopen devolve ao estado pendente uma solicitação aprovada cujo ajuste
+	// não chegou a ser gravado, descartando os dados da análise.
+	Reopen(ctx context.Context, correction *models.CorrectionRequest) error
+}
+
+// CorrectionFilter restringe as solicitações retornadas por List. CompanyID é
+// sempre aplicado; os demais campos, quando vazios, não filtram.
+type CorrectionFilter struct {
+	CompanyID primitive.ObjectID
+	UserIDs   []primitive.ObjectID
+	Status    models.CorrectionStatus
+}
+
+func (f CorrectionFilter) matches(correction *models.CorrectionRequest) bool {
+	if correction.CompanyID != f.CompanyID {
+		return false
+	}
+	if f.UserIDs != nil && !containsID(f.UserIDs, correction.UserID) {
+		return false
+	}
+	if f.Status != "" && correction.Status != f.Status {
+		return false
+	}
+	return true
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+type mongoCorrectionRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoCorrectionRepository cria um CorrectionRepository sobre a coleção
+// "corrections".
+func NewMongoCorrectionRepository(db *mongo.Database) CorrectionRepository {
+	return &mongoCorrectionRepository{collection: db.Collection("corrections")}
+}
+
+func (r *mongoCorrectionRepository) Create(ctx context.Context, correction *models.CorrectionRequest) error {
+	if correction.ID.IsZero() {
+		correction.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, correction)
+	return err
+}
+
+func (r *mongoCorrectionRepository) FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.CorrectionRequest, error) {
+	var correction models.CorrectionRequest
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "company_id": tenantFilter(companyID)}).Decode(&correction)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &correction, nil
+}
+
+func (r *mongoCorrectionRepository) List(ctx context.Context, filter CorrectionFilter) ([]models.CorrectionRequest, error) {
+	query := bson.M{"company_id": tenantFilter(filter.CompanyID)}
+	if filter.UserIDs != nil {
+		query["user_id"] = bson.M{"$in": filter.UserIDs}
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	corrections := []models.CorrectionRequest{}
+	if err := cursor.All(ctx, &corrections); err != nil {
+		return nil, err
+	}
+	return corrections, nil
+}
+
+func (r *mongoCorrectionRepository) Review(ctx context.Context, correction *models.CorrectionRequest) error {
+	filter := bson.M{
+		"_id":        correction.ID,
+		"company_id": tenantFilter(correction.CompanyID),
+		"status":     models.CorrectionPending,
+	}
+	return r.updateOne(ctx, filter, bson.M{"$set": bson.M{
+		"status":      correction.Status,
+		"reviewer_id": correction.ReviewerID,
+		"review_note": correction.ReviewNote,
+		"reviewed_at": correction.ReviewedAt,
+		"updated_at":  correction.UpdatedAt,
+	}})
+}
+
+func (r *mongoCorrectionRepository) SetApplied(ctx context.Context, correction *models.CorrectionRequest) error {
+	filter := bson.M{"_id": correction.ID, "company_id": tenantFilter(correction.CompanyID)}
+	return r.updateOne(ctx, filter, bson.M{"$set": bson.M{
+		"applied_id": correction.AppliedID,
+		"updated_at": correction.UpdatedAt,
+	}})
+}
+
+func (r *mongoCorrectionRepository) Reopen(ctx context.Context, correction *models.CorrectionRequest) error {
+	filter := bson.M{
+		"_id":        correction.ID,
+		"company_id": tenantFilter(correction.CompanyID),
+		"status":     models.CorrectionApproved,
+		"applied_id": bson.M{"$exists": false},
+	}
+	return r.updateOne(ctx, filter, bson.M{
+		"$set":   bson.M{"status": models.CorrectionPending, "updated_at": correction.UpdatedAt},
+		"$unset": bson.M{"reviewer_id": "", "review_note": "", "reviewed_at": ""},
+	})
+}
+
+// updateOne aplica update ao documento que atende ao filtro e retorna
+// ErrNotFound quando nenhum atende.
+func (r *mongoCorrectionRepository) updateOne(ctx context.Context, filter, update bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type memoryCorrectionRepository struct {
+	mu          sync.RWMutex
+	corrections map[primitive.ObjectID]models.CorrectionRequest
+}
+
+// NewMemoryCorrectionRepository cria um CorrectionRepository mantido em
+// memória.
+func NewMemoryCorrectionRepository() CorrectionRepository {
+	return &memoryCorrectionRepository{corrections: make(map[primitive.ObjectID]models.CorrectionRequest)}
+}
+
+func (r *memoryCorrectionRepository) Create(ctx context.Context, correction *models.CorrectionRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if correction.ID.IsZero() {
+		correction.ID = primitive.NewObjectID()
+	}
+	r.corrections[correction.ID] = *correction
+	return nil
+}
+
+func (r *memoryCorrectionRepository) FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.CorrectionRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	correction, ok := r.corrections[id]
+	if !ok || correction.CompanyID != companyID {
+		return nil, ErrNotFound
+	}
+	return &correction, nil
+}
+
+func (r *memoryCorrectionRepository) List(ctx context.Context, filter CorrectionFilter) ([]models.CorrectionRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	corrections := []models.CorrectionRequest{}
+	for _, correction := range r.corrections {
+		if filter.matches(&correction) {
+			corrections = append(corrections, correction)
+		}
+	}
+
+	sort.Slice(corrections, func(i, j int) bool {
+		return corrections[i].CreatedAt.After(corrections[j].CreatedAt)
+	})
+	return corrections, nil
+}
+
+func (r *memoryCorrectionRepository) Review(ctx context.Context, correction *models.CorrectionRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.corrections[correction.ID]
+	if !ok || current.CompanyID != correction.CompanyID || current.Status != models.CorrectionPending {
+		return ErrNotFound
+	}
+	current.Status = correction.Status
+	current.ReviewerID = correction.ReviewerID
+	current.ReviewNote = correction.ReviewNote
+	current.ReviewedAt = correction.ReviewedAt
+	current.UpdatedAt = correction.UpdatedAt
+	r.corrections[correction.ID] = current
+	return nil
+}
+
+func (r *memoryCorrectionRepository) SetApplied(ctx context.Context, correction *models.CorrectionRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.corrections[correction.ID]
+	if !ok || current.CompanyID != correction.CompanyID {
+		return ErrNotFound
+	}
+	current.AppliedID = correction.AppliedID
+	current.UpdatedAt = correction.UpdatedAt
+	r.corrections[correction.ID] = current
+	return nil
+}
+
+func (r *memoryCorrectionRepository) Reopen(ctx context.Context, correction *models.CorrectionRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.corrections[correction.ID]
+	if !ok || current.CompanyID != correction.CompanyID ||
+		current.Status != models.CorrectionApproved || !current.AppliedID.IsZero() {
+		return ErrNotFound
+	}
+	current.Status = models.CorrectionPending
+	current.ReviewerID = primitive.NilObjectID
+	current.ReviewNote = ""
+	current.ReviewedAt = time.Time{}
+	current.UpdatedAt = correction.UpdatedAt
+	r.corrections[correction.ID] = current
+	return nil
+}
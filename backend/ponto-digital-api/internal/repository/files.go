@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FileStore guarda o conteúdo dos arquivos enviados, identificados por uma
+// chave no formato "diretório/arquivo".
+type FileStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	// Get retorna o conteúdo do arquivo ou ErrNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+type diskFileStore struct {
+	dir string
+}
+
+// NewDiskFileStore cria um FileStore que grava os arquivos abaixo de dir.
+func NewDiskFileStore(dir string) FileStore {
+	return &diskFileStore{dir: dir}
+}
+
+func (s *diskFileStore) Put(ctx context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o640)
+}
+
+func (s *diskFileStore) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// path converte a chave em um caminho dentro do diretório base, recusando
+// chaves que escapariam dele.
+func (s *diskFileStore) path(key string) (string, error) {
+	if key == "" || strings.Contains(key, "..") || filepath.IsAbs(key) {
+		return "", errors.New("chave de arquivo inválida")
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+type memoryFileStore struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+}
+
+// NewMemoryFileStore cria um FileStore mantido em memória.
+func NewMemoryFileStore() FileStore {
+	return &memoryFileStore{files: make(map[string][]byte)}
+}
+
+func (s *memoryFileStore) Put(ctx context.Context, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.files[key] = append([]byte(nil), data...)
+	return nil
+}
+
+func (s *memoryFileStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.files[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return data, nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"ponto-digital-api/internal/models"
+)
+
+// GroupRepository persiste os grupos de jornada das empresas. Todas as
+// consultas são restritas à empresa informada.
+type GroupRepository interface {
+	// Create insere o grupo e preenche group.ID com o identificador gerado.
+	Create(ctx context.Context, group *models.WorkGroup) error
+	FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.WorkGroup, error)
+	// ListByCompany retorna os grupos da empresa ordenados por nome.
+	ListByCompany(ctx context.Context, companyID primitive.ObjectID) ([]models.WorkGroup, error)
+	// Update substitui o documento do grupo identificado por group.ID dentro
+	// da empresa group.CompanyID.
+	Update(ctx context.Context, group *models.WorkGroup) error
+}
+
+type mongoGroupRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoGroupRepository cria um GroupRepository sobre a coleção
+// "work_groups".
+func NewMongoGroupRepository(db *mongo.Database) GroupRepository {
+	return &mongoGroupRepository{collection: db.Collection("work_groups")}
+}
+
+func (r *mongoGroupRepository) Create(ctx context.Context, group *models.WorkGroup) error {
+	if group.ID.IsZero() {
+		group.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, group)
+	return err
+}
+
+func (r *mongoGroupRepository) FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.WorkGroup, error) {
+	return r.findOne(ctx, bson.M{"_id": id, "company_id": tenantFilter(companyID)})
+}
+
+func (r *mongoGroupRepository) findOne(ctx context.Context, filter bson.M) (*models.WorkGroup, error) {
+	var group models.WorkGroup
+	err := r.collection.FindOne(ctx, filter).Decode(&group)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *mongoGroupRepository) ListByCompany(ctx context.Context, companyID primitive.ObjectID) ([]models.WorkGroup, error) {
+	opts := options.Find().SetSort(bson.M{"name": 1})
+	cursor, err := r.collection.Find(ctx, bson.M{"company_id": tenantFilter(companyID)}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	groupes := []models.WorkGroup{}
+	if err := cursor.All(ctx, &groupes); err != nil {
+		return nil, err
+	}
+	return groupes, nil
+}
+
+func (r *mongoGroupRepository) Update(ctx context.Context, group *models.WorkGroup) error {
+	filter := bson.M{"_id": group.ID, "company_id": tenantFilter(group.CompanyID)}
+	result, err := r.collection.ReplaceOne(ctx, filter, group)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type memoryGroupRepository struct {
+	mu     sync.RWMutex
+	groups map[primitive.ObjectID]models.WorkGroup
+}
+
+// NewMemoryGroupRepository cria um GroupRepository mantido em memória.
+func NewMemoryGroupRepository() GroupRepository {
+	return &memoryGroupRepository{groups: make(map[primitive.ObjectID]models.WorkGroup)}
+}
+
+func (r *memoryGroupRepository) Create(ctx context.Context, group *models.WorkGroup) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if group.ID.IsZero() {
+		group.ID = primitive.NewObjectID()
+	}
+	r.groups[group.ID] = *group
+	return nil
+}
+
+func (r *memoryGroupRepository) FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.WorkGroup, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	group, ok := r.groups[id]
+	if !ok || group.CompanyID != companyID {
+		return nil, ErrNotFound
+	}
+	return &group, nil
+}
+
+func (r *memoryGroupRepository) ListByCompany(ctx context.Context, companyID primitive.ObjectID) ([]models.WorkGroup, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	groupes := []models.WorkGroup{}
+	for _, group := range r.groups {
+		if group.CompanyID == companyID {
+			groupes = append(groupes, group)
+		}
+	}
+
+	sort.Slice(groupes, func(i, j int) bool {
+		return groupes[i].Name < groupes[j].Name
+	})
+	return groupes, nil
+}
+
+func (r *memoryGroupRepository) Update(ctx context.Context, group *models.WorkGroup) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.groups[group.ID]
+	if !ok || current.CompanyID != group.CompanyID {
+		return ErrNotFound
+	}
+	r.groups[group.ID] = *group
+	return nil
+}
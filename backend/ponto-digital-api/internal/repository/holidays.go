@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"ponto-digital-api/internal/models"
+)
+
+// HolidayRepository persiste os feriados estaduais, municipais e as folgas
+// das empresas. Todas as consultas são restritas à empresa informada.
+type HolidayRepository interface {
+	// Create insere o feriado e preenche holiday.ID com o identificador gerado.
+	Create(ctx context.Context, holiday *models.Holiday) error
+	// ListByCompany retorna os feriados da empresa, de todos os
+	// estabelecimentos, com data em [start, end), ordenados por data.
+	ListByCompany(ctx context.Context, companyID primitive.ObjectID, start, end time.Time) ([]models.Holiday, error)
+	Delete(ctx context.Context, companyID, id primitive.ObjectID) error
+}
+
+type mongoHolidayRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoHolidayRepository cria um HolidayRepository sobre a coleção
+// "holidays".
+func NewMongoHolidayRepository(db *mongo.Database) HolidayRepository {
+	return &mongoHolidayRepository{collection: db.Collection("holidays")}
+}
+
+func (r *mongoHolidayRepository) Create(ctx context.Context, holiday *models.Holiday) error {
+	if holiday.ID.IsZero() {
+		holiday.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, holiday)
+	return err
+}
+
+func (r *mongoHolidayRepository) ListByCompany(ctx context.Context, companyID primitive.ObjectID, start, end time.Time) ([]models.Holiday, error) {
+	filter := bson.M{
+		"company_id": tenantFilter(companyID),
+		"date": bson.M{
+			"$gte": start,
+			"$lt":  end,
+		},
+	}
+	opts := options.Find().SetSort(bson.M{"date": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	holidays := []models.Holiday{}
+	if err := cursor.All(ctx, &holidays); err != nil {
+		return nil, err
+	}
+	return holidays, nil
+}
+
+func (r *mongoHolidayRepository) Delete(ctx context.Context, companyID, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "company_id": tenantFilter(companyID)})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type memoryHolidayRepository struct {
+	mu       sync.RWMutex
+	holidays map[primitive.ObjectID]models.Holiday
+}
+
+// NewMemoryHolidayRepository cria um HolidayRepository mantido em memória.
+func NewMemoryHolidayRepository() HolidayRepository {
+	return &memoryHolidayRepository{holidays: make(map[primitive.ObjectID]models.Holiday)}
+}
+
+func (r *memoryHolidayRepository) Create(ctx context.Context, holiday *models.Holiday) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if holiday.ID.IsZero() {
+		holiday.ID = primitive.NewObjectID()
+	}
+	r.holidays[holiday.ID] = *holiday
+	return nil
+}
+
+func (r *memoryHolidayRepository) ListByCompany(ctx context.Context, companyID primitive.ObjectID, start, end time.Time) ([]models.Holiday, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	holidays := []models.Holiday{}
+	for _, holiday := range r.holidays {
+		if holiday.CompanyID != companyID {
+			continue
+		}
+		if holiday.Date.Before(start) || !holiday.Date.Before(end) {
+			continue
+		}
+		holidays = append(holidays, holiday)
+	}
+
+	sort.Slice(holidays, func(i, j int) bool {
+		return holidays[i].Date.Before(holidays[j].Date)
+	})
+	return holidays, nil
+}
+
+func (r *memoryHolidayRepository) Delete(ctx context.Context, companyID, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	holiday, ok := r.holidays[id]
+	if !ok || holiday.CompanyID != companyID {
+		return ErrNotFound
+	}
+	delete(r.holidays, id)
+	return nil
+}
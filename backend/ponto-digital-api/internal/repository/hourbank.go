@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"ponto-digital-api/internal/models"
+)
+
+// HourBankRepository persiste os lançamentos manuais do banco de horas. As
+// consultas são restritas à empresa informada.
+type HourBankRepository interface {
+	// Create insere o lançamento e preenche adjustment.ID com o identificador
+	// gerado.
+	Create(ctx context.Context, adjustment *models.HourBankAdjustment) error
+	// ListByUser retorna os lançamentos do usuário ordenados por data.
+	ListByUser(ctx context.Context, companyID, userID primitive.ObjectID) ([]models.HourBankAdjustment, error)
+}
+
+type mongoHourBankRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoHourBankRepository cria um HourBankRepository sobre a coleção
+// "hour_bank_adjustments".
+func NewMongoHourBankRepository(db *mongo.Database) HourBankRepository {
+	return &mongoHourBankRepository{collection: db.Collection("hour_bank_adjustments")}
+}
+
+func (r *mongoHourBankRepository) Create(ctx context.Context, adjustment *models.HourBankAdjustment) error {
+	if adjustment.ID.IsZero() {
+		adjustment.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, adjustment)
+	return err
+}
+
+func (r *mongoHourBankRepository) ListByUser(ctx context.Context, companyID, userID primitive.ObjectID) ([]models.HourBankAdjustment, error) {
+	filter := bson.M{"company_id": tenantFilter(companyID), "user_id": userID}
+	opts := options.Find().SetSort(bson.D{{Key: "date", Value: 1}, {Key: "created_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	adjustments := []models.HourBankAdjustment{}
+	if err := cursor.All(ctx, &adjustments); err != nil {
+		return nil, err
+	}
+	return adjustments, nil
+}
+
+type memoryHourBankRepository struct {
+	mu          sync.RWMutex
+	adjustments []models.HourBankAdjustment
+}
+
+// NewMemoryHourBankRepository cria um HourBankRepository mantido em memória.
+func NewMemoryHourBankRepository() HourBankRepository {
+	return &memoryHourBankRepository{}
+}
+
+func (r *memoryHourBankRepository) Create(ctx context.Context, adjustment *models.HourBankAdjustment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if adjustment.ID.IsZero() {
+		adjustment.ID = primitive.NewObjectID()
+	}
+	r.adjustments = append(r.adjustments, *adjustment)
+	return nil
+}
+
+func (r *memoryHourBankRepository) ListByUser(ctx context.Context, companyID, userID primitive.ObjectID) ([]models.HourBankAdjustment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	adjustments := []models.HourBankAdjustment{}
+	for _, adjustment := range r.adjustments {
+		if adjustment.CompanyID == companyID && adjustment.UserID == userID {
+			adjustments = append(adjustments, adjustment)
+		}
+	}
+
+	sort.SliceStable(adjustments, func(i, j int) bool {
+		return adjustments[i].Date.Before(adjustments[j].Date)
+	})
+	return adjustments, nil
+}
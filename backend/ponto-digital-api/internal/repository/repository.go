@@ -0,0 +1,100 @@
+// Package repository isola o acesso a dados dos handlers. Cada coleção é
+// exposta por uma interface com duas implementações: uma sobre o MongoDB,
+// usada em produção, e outra em memória, usada para rodar a API e os testes
+// sem depender de um banco externo.
+package repository
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrNotFound é retornado quando o documento procurado não existe.
+var ErrNotFound = errors.New("registro não encontrado")
+
+// Store agrupa todos os repositórios usados pela API.
+type Store struct {
+	Companies           CompanyRepository
+	Branches            BranchRepository
+	Users               UserRepository
+	TimeRecords         TimeRecordRepository
+	Schedules           ScheduleRepository
+	Groups              GroupRepository
+	Shifts              ShiftRepository
+	Agreements          AgreementRepository
+	HourBank            HourBankRepository
+	Holidays            HolidayRepository
+	Corrections         CorrectionRepository
+	Attachments         AttachmentRepository
+	Absences            AbsenceRepository
+	Closings            ClosingRepository
+	Acknowledgements    AcknowledgementRepository
+	WebAuthnCredentials WebAuthnCredentialRepository
+	WebAuthnChallenges  WebAuthnChallengeRepository
+	Sessions            SessionRepository
+	// Files guarda o conteúdo dos anexos; NewMongoStore não o preenche, pois
+	// os arquivos ficam fora do banco.
+	Files FileStore
+}
+
+// NewMongoStore cria um Store com todos os repositórios apontando para o
+// banco MongoDB informado.
+func NewMongoStore(db *mongo.Database) *Store {
+	return &Store{
+		Companies:           NewMongoCompanyRepository(db),
+		Branches:            NewMongoBranchRepository(db),
+		Users:               NewMongoUserRepository(db),
+		TimeRecords:         NewMongoTimeRecordRepository(db),
+		Schedules:           NewMongoScheduleRepository(db),
+		Groups:              NewMongoGroupRepository(db),
+		Shifts:              NewMongoShiftRepository(db),
+		Agreements:          NewMongoAgreementRepository(db),
+		HourBank:            NewMongoHourBankRepository(db),
+		Holidays:            NewMongoHolidayRepository(db),
+		Corrections:         NewMongoCorrectionRepository(db),
+		Attachments:         NewMongoAttachmentRepository(db),
+		Absences:            NewMongoAbsenceRepository(db),
+		Closings:            NewMongoClosingRepository(db),
+		Acknowledgements:    NewMongoAcknowledgementRepository(db),
+		WebAuthnCredentials: NewMongoWebAuthnCredentialRepository(db),
+		WebAuthnChallenges:  NewMongoWebAuthnChallengeRepository(db),
+		Sessions:            NewMongoSessionRepository(db),
+	}
+}
+
+// NewMemoryStore cria um Store com todos os repositórios em memória.
+func NewMemoryStore() *Store {
+	return &Store{
+		Companies:           NewMemoryCompanyRepository(),
+		Branches:            NewMemoryBranchRepository(),
+		Users:               NewMemoryUserRepository(),
+		TimeRecords:         NewMemoryTimeRecordRepository(),
+		Schedules:           NewMemoryScheduleRepository(),
+		Groups:              NewMemoryGroupRepository(),
+		Shifts:              NewMemoryShiftRepository(),
+		Agreements:          NewMemoryAgreementRepository(),
+		HourBank:            NewMemoryHourBankRepository(),
+		Holidays:            NewMemoryHolidayRepository(),
+		Corrections:         NewMemoryCorrectionRepository(),
+		Attachments:         NewMemoryAttachmentRepository(),
+		Absences:            NewMemoryAbsenceRepository(),
+		Closings:            NewMemoryClosingRepository(),
+		Acknowledgements:    NewMemoryAcknowledgementRepository(),
+		WebAuthnCredentials: NewMemoryWebAuthnCredentialRepository(),
+		WebAuthnChallenges:  NewMemoryWebAuthnChallengeRepository(),
+		Sessions:            NewMemorySessionRepository(),
+		Files:               NewMemoryFileStore(),
+	}
+}
+
+// tenantFilter devolve o valor usado para filtrar company_id nas consultas.
+// Cadastros anteriores às empresas não possuem company_id e pertencem ao
+// tenant vazio, que no MongoDB é encontrado com o filtro nulo.
+func tenantFilter(companyID primitive.ObjectID) interface{} {
+	if companyID.IsZero() {
+		return nil
+	}
+	return companyID
+}
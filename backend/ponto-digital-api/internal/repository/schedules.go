@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"ponto-digital-api/internal/models"
+)
+
+// ScheduleRepository persiste as jornadas de trabalho das empresas. Todas as
+// consultas são restritas à empresa informada.
+type ScheduleRepository interface {
+	// Create insere a jornada e preenche schedule.ID com o identificador
+	// gerado.
+	Create(ctx context.Context, schedule *models.WorkSchedule) error
+	FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.WorkSchedule, error)
+	// ListByCompany retorna as jornadas da empresa ordenadas por nome.
+	ListByCompany(ctx context.Context, companyID primitive.ObjectID) ([]models.WorkSchedule, error)
+	// Update substitui o documento da jornada identificada por schedule.ID
+	// dentro da empresa schedule.CompanyID.
+	Update(ctx context.Context, schedule *models.WorkSchedule) error
+}
+
+type mongoScheduleRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoScheduleRepository cria um ScheduleRepository sobre a coleção
+// "schedules".
+func NewMongoScheduleRepository(db *mongo.Database) ScheduleRepository {
+	return &mongoScheduleRepository{collection: db.Collection("schedules")}
+}
+
+func (r *mongoScheduleRepository) Create(ctx context.Context, schedule *models.WorkSchedule) error {
+	if schedule.ID.IsZero() {
+		schedule.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, schedule)
+	return err
+}
+
+func (r *mongoScheduleRepository) FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.WorkSchedule, error) {
+	return r.findOne(ctx, bson.M{"_id": id, "company_id": tenantFilter(companyID)})
+}
+
+func (r *mongoScheduleRepository) findOne(ctx context.Context, filter bson.M) (*models.WorkSchedule, error) {
+	var schedule models.WorkSchedule
+	err := r.collection.FindOne(ctx, filter).Decode(&schedule)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (r *mongoScheduleRepository) ListByCompany(ctx context.Context, companyID primitive.ObjectID) ([]models.WorkSchedule, error) {
+	opts := options.Find().SetSort(bson.M{"name": 1})
+	cursor, err := r.collection.Find(ctx, bson.M{"company_id": tenantFilter(companyID)}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	schedulees := []models.WorkSchedule{}
+	if err := cursor.All(ctx, &schedulees); err != nil {
+		return nil, err
+	}
+	return schedulees, nil
+}
+
+func (r *mongoScheduleRepository) Update(ctx context.Context, schedule *models.WorkSchedule) error {
+	filter := bson.M{"_id": schedule.ID, "company_id": tenantFilter(schedule.CompanyID)}
+	result, err := r.collection.ReplaceOne(ctx, filter, schedule)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type memoryScheduleRepository struct {
+	mu        sync.RWMutex
+	schedules map[primitive.ObjectID]models.WorkSchedule
+}
+
+// NewMemoryScheduleRepository cria um ScheduleRepository mantido em memória.
+func NewMemoryScheduleRepository() ScheduleRepository {
+	return &memoryScheduleRepository{schedules: make(map[primitive.ObjectID]models.WorkSchedule)}
+}
+
+func (r *memoryScheduleRepository) Create(ctx context.Context, schedule *models.WorkSchedule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if schedule.ID.IsZero() {
+		schedule.ID = primitive.NewObjectID()
+	}
+	r.schedules[schedule.ID] = *schedule
+	return nil
+}
+
+func (r *memoryScheduleRepository) FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.WorkSchedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schedule, ok := r.schedules[id]
+	if !ok || schedule.CompanyID != companyID {
+		return nil, ErrNotFound
+	}
+	return &schedule, nil
+}
+
+func (r *memoryScheduleRepository) ListByCompany(ctx context.Context, companyID primitive.ObjectID) ([]models.WorkSchedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schedulees := []models.WorkSchedule{}
+	for _, schedule := range r.schedules {
+		if schedule.CompanyID == companyID {
+			schedulees = append(schedulees, schedule)
+		}
+	}
+
+	sort.Slice(schedulees, func(i, j int) bool {
+		return schedulees[i].Name < schedulees[j].Name
+	})
+	return schedulees, nil
+}
+
+func (r *memoryScheduleRepository) Update(ctx context.Context, schedule *models.WorkSchedule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.schedules[schedule.ID]
+	if !ok || current.CompanyID != schedule.CompanyID {
+		return ErrNotFound
+	}
+	r.schedules[schedule.ID] = *schedule
+	return nil
+}
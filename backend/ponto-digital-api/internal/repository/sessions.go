@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"ponto-digital-api/internal/models"
+)
+
+// SessionRepository persiste as sessões de login. FindByID não é restrito a
+// uma empresa: as sessões são localizadas pelo ID presente nos tokens, antes
+// de o usuário ser conhecido.
+type SessionRepository interface {
+	// Create insere a sessão e preenche session.ID com o identificador
+	// gerado.
+	Create(ctx context.Context, session *models.Session) error
+	// FindByID retorna a sessão ou ErrNotFound.
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Session, error)
+	// Rotate troca o refresh token da sessão id, desde que ela esteja ativa em
+	// now e o token atual seja refreshHash: refreshHash passa a ser o token
+	// anterior, newHash o atual, e a troca, a validade e o IP são renovados.
+	// Retorna ErrNotFound se a sessão não atender às condições, por exemplo
+	// se foi encerrada ou renovada por outra requisição.
+	Rotate(ctx context.Context, id primitive.ObjectID, refreshHash, newHash, ip string, now, expiresAt time.Time) error
+	// Revoke encerra em at a sessão id, se ainda não foi encerrada, ou
+	// retorna ErrNotFound.
+	Revoke(ctx context.Context, id primitive.ObjectID, at time.Time) error
+	// RevokeReused encerra em at a sessão id cujo refresh token anterior é
+	// previousHash, se a troca ocorreu antes de rotatedBefore e a sessão
+	// ainda não foi encerrada, ou retorna ErrNotFound.
+	RevokeReused(ctx context.Context, id primitive.ObjectID, previousHash string, rotatedBefore, at time.Time) error
+	// RevokeByUser encerra em at as sessões ativas do usuário, exceto a
+	// sessão except, que pode ser zero.
+	RevokeByUser(ctx context.Context, companyID, userID, except primitive.ObjectID, at time.Time) error
+}
+
+type mongoSessionRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoSessionRepository cria um SessionRepository sobre a coleção
+// "sessions".
+func NewMongoSessionRepository(db *mongo.Database) SessionRepository {
+	return &mongoSessionRepository{collection: db.Collection("sessions")}
+}
+
+func (r *mongoSessionRepository) Create(ctx context.Context, session *models.Session) error {
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, session)
+	return err
+}
+
+func (r *mongoSessionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Session, error) {
+	var session models.Session
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&session)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *mongoSessionRepository) Rotate(ctx context.Context, id primitive.ObjectID, refreshHash, newHash, ip string, now, expiresAt time.Time) error {
+	filter := bson.M{
+		"_id":          id,
+		"refresh_hash": refreshHash,
+		"revoked_at":   nil,
+		"expires_at":   bson.M{"$gt": now},
+	}
+	update := bson.M{"$set": bson.M{
+		"previous_hash": refreshHash,
+		"refresh_hash":  newHash,
+		"rotated_at":    now,
+		"expires_at":    expiresAt,
+		"ip":            ip,
+	}}
+	return r.updateOne(ctx, filter, update)
+}
+
+func (r *mongoSessionRepository) Revoke(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	filter := bson.M{"_id": id, "revoked_at": nil}
+	return r.updateOne(ctx, filter, bson.M{"$set": bson.M{"revoked_at": at}})
+}
+
+func (r *mongoSessionRepository) RevokeReused(ctx context.Context, id primitive.ObjectID, previousHash string, rotatedBefore, at time.Time) error {
+	filter := bson.M{
+		"_id":           id,
+		"previous_hash": previousHash,
+		"rotated_at":    bson.M{"$lt": rotatedBefore},
+		"revoked_at":    nil,
+	}
+	return r.updateOne(ctx, filter, bson.M{"$set": bson.M{"revoked_at": at}})
+}
+
+// updateOne aplica update à sessão que atende a filter, ou retorna
+// ErrNotFound.
+func (r *mongoSessionRepository) updateOne(ctx context.Context, filter, update bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoSessionRepository) RevokeByUser(ctx context.Context, companyID, userID, except primitive.ObjectID, at time.Time) error {
+	filter := bson.M{
+		"company_id": tenantFilter(companyID),
+		"user_id":    userID,
+		"revoked_at": nil,
+	}
+	if !except.IsZero() {
+		filter["_id"] = bson.M{"$ne": except}
+	}
+	_, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"revoked_at": at}})
+	return err
+}
+
+type memorySessionRepository struct {
+	mu       sync.RWMutex
+	sessions map[primitive.ObjectID]models.Session
+}
+
+// NewMemorySessionRepository cria um SessionRepository mantido em memória.
+func NewMemorySessionRepository() SessionRepository {
+	return &memorySessionRepository{sessions: make(map[primitive.ObjectID]models.Session)}
+}
+
+func (r *memorySessionRepository) Create(ctx context.Context, session *models.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+func (r *memorySessionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &session, nil
+}
+
+func (r *memorySessionRepository) Rotate(ctx context.Context, id primitive.ObjectID, refreshHash, newHash, ip string, now, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok || session.RefreshHash != refreshHash || !session.Active(now) {
+		return ErrNotFound
+	}
+	session.PreviousHash = refreshHash
+	session.RefreshHash = newHash
+	session.RotatedAt = now
+	session.ExpiresAt = expiresAt
+	session.IP = ip
+	r.sessions[id] = session
+	return nil
+}
+
+func (r *memorySessionRepository) Revoke(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok || session.RevokedAt != nil {
+		return ErrNotFound
+	}
+	session.RevokedAt = &at
+	r.sessions[id] = session
+	return nil
+}
+
+func (r *memorySessionRepository) RevokeReused(ctx context.Context, id primitive.ObjectID, previousHash string, rotatedBefore, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok || session.PreviousHash != previousHash || !session.RotatedAt.Before(rotatedBefore) || session.RevokedAt != nil {
+		return ErrNotFound
+	}
+	session.RevokedAt = &at
+	r.sessions[id] = session
+	return nil
+}
+
+func (r *memorySessionRepository) RevokeByUser(ctx context.Context, companyID, userID, except primitive.ObjectID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, session := range r.sessions {
+		if session.CompanyID != companyID || session.UserID != userID || session.RevokedAt != nil || id == except {
+			continue
+		}
+		revokedAt := at
+		session.RevokedAt = &revokedAt
+		r.sessions[id] = session
+	}
+	return nil
+}
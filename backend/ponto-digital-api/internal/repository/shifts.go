@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"ponto-digital-api/internal/models"
+)
+
+// ShiftRepository persiste as escalas de revezamento das empresas. Todas as
+// consultas são restritas à empresa informada.
+type ShiftRepository interface {
+	// Create insere a escala e preenche shift.ID com o identificador gerado.
+	Create(ctx context.Context, shift *models.ShiftPattern) error
+	FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.ShiftPattern, error)
+	// ListByCompany retorna as escalas da empresa ordenadas por nome.
+	ListByCompany(ctx context.Context, companyID primitive.ObjectID) ([]models.ShiftPattern, error)
+	// Update substitui o documento da escala identificada por shift.ID
+	// dentro da empresa shift.CompanyID.
+	Update(ctx context.Context, shift *models.ShiftPattern) error
+}
+
+type mongoShiftRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoShiftRepository cria um ShiftRepository sobre a coleção
+// "shift_patterns".
+func NewMongoShiftRepository(db *mongo.Database) ShiftRepository {
+	return &mongoShiftRepository{collection: db.Collection("shift_patterns")}
+}
+
+func (r *mongoShiftRepository) Create(ctx context.Context, shift *models.ShiftPattern) error {
+	if shift.ID.IsZero() {
+		shift.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, shift)
+	return err
+}
+
+func (r *mongoShiftRepository) FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.ShiftPattern, error) {
+	return r.findOne(ctx, bson.M{"_id": id, "company_id": tenantFilter(companyID)})
+}
+
+func (r *mongoShiftRepository) findOne(ctx context.Context, filter bson.M) (*models.ShiftPattern, error) {
+	var shift models.ShiftPattern
+	err := r.collection.FindOne(ctx, filter).Decode(&shift)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &shift, nil
+}
+
+func (r *mongoShiftRepository) ListByCompany(ctx context.Context, companyID primitive.ObjectID) ([]models.ShiftPattern, error) {
+	opts := options.Find().SetSort(bson.M{"name": 1})
+	cursor, err := r.collection.Find(ctx, bson.M{"company_id": tenantFilter(companyID)}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	shiftes := []models.ShiftPattern{}
+	if err := cursor.All(ctx, &shiftes); err != nil {
+		return nil, err
+	}
+	return shiftes, nil
+}
+
+func (r *mongoShiftRepository) Update(ctx context.Context, shift *models.ShiftPattern) error {
+	filter := bson.M{"_id": shift.ID, "company_id": tenantFilter(shift.CompanyID)}
+	result, err := r.collection.ReplaceOne(ctx, filter, shift)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type memoryShiftRepository struct {
+	mu     sync.RWMutex
+	shifts map[primitive.ObjectID]models.ShiftPattern
+}
+
+// NewMemoryShiftRepository cria um ShiftRepository mantido em memória.
+func NewMemoryShiftRepository() ShiftRepository {
+	return &memoryShiftRepository{shifts: make(map[primitive.ObjectID]models.ShiftPattern)}
+}
+
+func (r *memoryShiftRepository) Create(ctx context.Context, shift *models.ShiftPattern) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if shift.ID.IsZero() {
+		shift.ID = primitive.NewObjectID()
+	}
+	r.shifts[shift.ID] = *shift
+	return nil
+}
+
+func (r *memoryShiftRepository) FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.ShiftPattern, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	shift, ok := r.shifts[id]
+	if !ok || shift.CompanyID != companyID {
+		return nil, ErrNotFound
+	}
+	return &shift, nil
+}
+
+func (r *memoryShiftRepository) ListByCompany(ctx context.Context, companyID primitive.ObjectID) ([]models.ShiftPattern, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	shiftes := []models.ShiftPattern{}
+	for _, shift := range r.shifts {
+		if shift.CompanyID == companyID {
+			shiftes = append(shiftes, shift)
+		}
+	}
+
+	sort.Slice(shiftes, func(i, j int) bool {
+		return shiftes[i].Name < shiftes[j].Name
+	})
+	return shiftes, nil
+}
+
+func (r *memoryShiftRepository) Update(ctx context.Context, shift *models.ShiftPattern) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.shifts[shift.ID]
+	if !ok || current.CompanyID != shift.CompanyID {
+		return ErrNotFound
+	}
+	r.shifts[shift.ID] = *shift
+	return nil
+}
@@ -0,0 +1,499 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/punch"
+)
+
+// TimeRecordRepository persiste as marcações de ponto. As consultas são
+// restritas à empresa informada.
+type TimeRecordRepository interface {
+	// Create insere um registro de tratamento (ajuste de ponto), preenchendo
+	// record.ID. Os ajustes não são marcações do REP: não recebem NSR nem
+	// hash e constam apenas no AEJ.
+	Create(ctx context.Context, record *models.TimeRecord) error
+	// CreatePunch numera a marcação original com o próximo NSR do
+	// estabelecimento, encadeia o seu hash ao do registro anterior e a
+	// insere, preenchendo record.ID, record.NSR e record.Hash. Na mesma
+	// transação confere o tipo solicitado contra a última marcação efetiva
+	// do usuário (punch.Resolve) e preenche record.Type, para que duas
+	// marcações simultâneas não possam ambas ocupar a mesma posição da
+	// sequência. Retorna os erros de punch.Resolve sem alteração.
+	CreatePunch(ctx context.Context, record *models.TimeRecord, requested string) error
+	// FindByUser retorna as marcações do usuário com timestamp em [start, end),
+	// ordenadas da mais antiga para a mais recente.
+	FindByUser(ctx context.Context, companyID, userID primitive.ObjectID, start, end time.Time) ([]models.TimeRecord, error)
+	FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.TimeRecord, error)
+	// FindByBranch retorna as marcações de todos os usuários do
+	// estabelecimento com timestamp em [start, end), ordenadas da mais antiga
+	// para a mais recente. O identificador nulo seleciona as marcações sem
+	// estabelecimento.
+	FindByBranch(ctx context.Context, companyID, branchID primitive.ObjectID, start, end time.Time) ([]models.TimeRecord, error)
+	// CreateEvent numera o evento de cadastro do REP com o próximo NSR do
+	// estabelecimento e o insere, preenchendo event.ID e event.NSR. Os
+	// eventos não entram no encadeamento dos hashes.
+	CreateEvent(ctx context.Context, event *models.RepEvent) error
+	// FindEvents retorna os eventos de cadastro do estabelecimento com
+	// created_at em [start, end), ordenados por NSR. O identificador nulo
+	// seleciona os eventos da sede.
+	FindEvents(ctx context.Context, companyID, branchID primitive.ObjectID, start, end time.Time) ([]models.RepEvent, error)
+	// FindChain retorna os registros numerados e os eventos de cadastro do
+	// estabelecimento, ordenados por NSR, e o último NSR emitido, para a
+	// verificação da sequência.
+	FindChain(ctx context.Context, companyID, branchID primitive.ObjectID) ([]models.TimeRecord, []models.RepEvent, ChainHead, error)
+}
+
+// ChainHead é o último NSR emitido em um estabelecimento e o hash do último
+// registro encadeado. Fica guardado fora dos registros para que a exclusão dos
+// últimos registros também seja detectada.
+type ChainHead struct {
+	NSR  int64  `bson:"nsr"`
+	Hash string `bson:"hash"`
+}
+
+// ErrSequenceConflict indica que o NSR não pôde ser reservado por concorrência
+// com outras marcações do mesmo estabelecimento.
+var ErrSequenceConflict = errors.New("conflito na numeração sequencial dos registros")
+
+// maxSequenceAttempts limita as tentativas de reservar o próximo NSR.
+const maxSequenceAttempts = 10
+
+type mongoTimeRecordRepository struct {
+	collection *mongo.Collection
+	events     *mongo.Collection
+	sequences  *mongo.Collection
+}
+
+// NewMongoTimeRecordRepository cria um TimeRecordRepository sobre a coleção
+// "time_records". Os eventos de cadastro ficam na coleção "rep_events" e o
+// último NSR de cada estabelecimento na coleção "record_sequences".
+func NewMongoTimeRecordRepository(db *mongo.Database) TimeRecordRepository {
+	return &mongoTimeRecordRepository{
+		collection: db.Collection("time_records"),
+		events:     db.Collection("rep_events"),
+		sequences:  db.Collection("record_sequences"),
+	}
+}
+
+// sequenceKey identifica a sequência de NSR de um estabelecimento.
+func sequenceKey(companyID, branchID primitive.ObjectID) string {
+	return companyID.Hex() + ":" + branchID.Hex()
+}
+
+// punchWindow retorna o intervalo das marcações que determinam o tipo de uma
+// marcação feita em at: as mais antigas que punch.MaxOpenDuration não alteram
+// a sequência.
+func punchWindow(at time.Time) (start, end time.Time) {
+	return at.Add(-punch.MaxOpenDuration), at.Add(time.Second)
+}
+
+func (r *mongoTimeRecordRepository) Create(ctx context.Context, record *models.TimeRecord) error {
+	if record.ID.IsZero() {
+		record.ID = primitive.NewObjectID()
+	}
+	record.NSR = 0
+	record.Hash = ""
+	_, err := r.collection.InsertOne(ctx, record)
+	return err
+}
+
+func (r *mongoTimeRecordRepository) CreatePunch(ctx context.Context, record *models.TimeRecord, requested string) error {
+	if record.ID.IsZero() {
+		record.ID = primitive.NewObjectID()
+	}
+
+	// A leitura é feita dentro da transação que avança a sequência do
+	// estabelecimento. Uma marcação concorrente do mesmo usuário altera o
+	// mesmo documento da sequência, e a transação que perde o conflito é
+	// refeita, já vendo a marcação gravada pela outra
+	return r.numbered(ctx, sequenceKey(record.CompanyID, record.BranchID), func(ctx mongo.SessionContext, head ChainHead) (ChainHead, error) {
+		start, end := punchWindow(record.Timestamp)
+		recent, err := r.FindByUser(ctx, record.CompanyID, record.UserID, start, end)
+		if err != nil {
+			return head, err
+		}
+		punchType, err := punch.Resolve(requested, punch.Last(recent), record.Timestamp)
+		if err != nil {
+			return head, err
+		}
+		record.Type = punchType
+
+		record.NSR = head.NSR + 1
+		record.Hash = punch.Hash(record, head.Hash)
+		if _, err := r.collection.InsertOne(ctx, record); err != nil {
+			return head, err
+		}
+		return ChainHead{NSR: record.NSR, Hash: record.Hash}, nil
+	})
+}
+
+func (r *mongoTimeRecordRepository) CreateEvent(ctx context.Context, event *models.RepEvent) error {
+	if event.ID.IsZero() {
+		event.ID = primitive.NewObjectID()
+	}
+
+	// O evento ocupa um NSR, mas o topo da sequência continua com o hash da
+	// última marcação, à qual a próxima será encadeada
+	return r.numbered(ctx, sequenceKey(event.CompanyID, event.BranchID), func(ctx mongo.SessionContext, head ChainHead) (ChainHead, error) {
+		event.NSR = head.NSR + 1
+		if _, err := r.events.InsertOne(ctx, event); err != nil {
+			return head, err
+		}
+		return ChainHead{NSR: event.NSR, Hash: head.Hash}, nil
+	})
+}
+
+// numbered executa insert em uma transação que avança a sequência key. insert
+// recebe o topo lido e retorna o novo topo, gravado somente se a sequência
+// ainda estiver no valor lido.
+func (r *mongoTimeRecordRepository) numbered(ctx context.Context, key string, insert func(ctx mongo.SessionContext, head ChainHead) (ChainHead, error)) error {
+	session, err := r.collection.Database().Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	// A sequência avança na mesma transação que insere o registro, para que
+	// uma falha na inserção não deixe lacuna. Em caso de concorrência a
+	// leitura é refeita
+	for attempt := 0; attempt < maxSequenceAttempts; attempt++ {
+		_, err := session.WithTransaction(ctx, func(ctx mongo.SessionContext) (interface{}, error) {
+			head, err := r.head(ctx, key)
+			if err != nil {
+				return nil, err
+			}
+			next, err := insert(ctx, head)
+			if err != nil {
+				return nil, err
+			}
+
+			result, err := r.sequences.UpdateOne(ctx,
+				bson.M{"_id": key, "nsr": head.NSR},
+				bson.M{"$set": bson.M{"nsr": next.NSR, "hash": next.Hash}},
+				options.Update().SetUpsert(true))
+			if err != nil {
+				return nil, err
+			}
+			if result.MatchedCount == 0 && result.UpsertedCount == 0 {
+				return nil, ErrSequenceConflict
+			}
+			return nil, nil
+		})
+		if errors.Is(err, ErrSequenceConflict) || mongo.IsDuplicateKeyError(err) {
+			continue
+		}
+		return err
+	}
+	return ErrSequenceConflict
+}
+
+func (r *mongoTimeRecordRepository) head(ctx context.Context, key string) (ChainHead, error) {
+	var head ChainHead
+	err := r.sequences.FindOne(ctx, bson.M{"_id": key}).Decode(&head)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ChainHead{}, nil
+	}
+	return head, err
+}
+
+func (r *mongoTimeRecordRepository) FindByUser(ctx context.Context, companyID, userID primitive.ObjectID, start, end time.Time) ([]models.TimeRecord, error) {
+	filter := bson.M{
+		"company_id": tenantFilter(companyID),
+		"user_id":    userID,
+		"timestamp": bson.M{
+			"$gte": start,
+			"$lt":  end,
+		},
+	}
+	opts := options.Find().SetSort(bson.M{"timestamp": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	records := []models.TimeRecord{}
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (r *mongoTimeRecordRepository) FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.TimeRecord, error) {
+	var record models.TimeRecord
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "company_id": tenantFilter(companyID)}).Decode(&record)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *mongoTimeRecordRepository) FindByBranch(ctx context.Context, companyID, branchID primitive.ObjectID, start, end time.Time) ([]models.TimeRecord, error) {
+	filter := branchFilter(companyID, branchID)
+	filter["timestamp"] = bson.M{
+		"$gte": start,
+		"$lt":  end,
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	records := []models.TimeRecord{}
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (r *mongoTimeRecordRepository) FindEvents(ctx context.Context, companyID, branchID primitive.ObjectID, start, end time.Time) ([]models.RepEvent, error) {
+	filter := branchFilter(companyID, branchID)
+	filter["created_at"] = bson.M{
+		"$gte": start,
+		"$lt":  end,
+	}
+	return r.findEvents(ctx, filter)
+}
+
+func (r *mongoTimeRecordRepository) FindChain(ctx context.Context, companyID, branchID primitive.ObjectID) ([]models.TimeRecord, []models.RepEvent, ChainHead, error) {
+	head, err := r.head(ctx, sequenceKey(companyID, branchID))
+	if err != nil {
+		return nil, nil, head, err
+	}
+
+	filter := branchFilter(companyID, branchID)
+	filter["nsr"] = bson.M{"$gt": 0}
+	opts := options.Find().SetSort(bson.D{{Key: "nsr", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, nil, head, err
+	}
+	defer cursor.Close(ctx)
+
+	records := []models.TimeRecord{}
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, nil, head, err
+	}
+
+	events, err := r.findEvents(ctx, branchFilter(companyID, branchID))
+	if err != nil {
+		return nil, nil, head, err
+	}
+	return records, events, head, nil
+}
+
+func (r *mongoTimeRecordRepository) findEvents(ctx context.Context, filter bson.M) ([]models.RepEvent, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "nsr", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.events.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	events := []models.RepEvent{}
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// branchFilter seleciona os documentos do estabelecimento, ou os sem
+// estabelecimento quando branchID é nulo.
+func branchFilter(companyID, branchID primitive.ObjectID) bson.M {
+	filter := bson.M{"company_id": tenantFilter(companyID)}
+	if branchID.IsZero() {
+		filter["branch_id"] = bson.M{"$exists": false}
+	} else {
+		filter["branch_id"] = branchID
+	}
+	return filter
+}
+
+type memoryTimeRecordRepository struct {
+	mu      sync.RWMutex
+	records []models.TimeRecord
+	events  []models.RepEvent
+	heads   map[string]ChainHead
+}
+
+// NewMemoryTimeRecordRepository cria um TimeRecordRepository mantido em memória.
+func NewMemoryTimeRecordRepository() TimeRecordRepository {
+	return &memoryTimeRecordRepository{heads: map[string]ChainHead{}}
+}
+
+func (r *memoryTimeRecordRepository) Create(ctx context.Context, record *models.TimeRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if record.ID.IsZero() {
+		record.ID = primitive.NewObjectID()
+	}
+	record.NSR = 0
+	record.Hash = ""
+	r.records = append(r.records, *record)
+	return nil
+}
+
+func (r *memoryTimeRecordRepository) CreatePunch(ctx context.Context, record *models.TimeRecord, requested string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	start, end := punchWindow(record.Timestamp)
+	recent := r.findByUser(record.CompanyID, record.UserID, start, end)
+	punchType, err := punch.Resolve(requested, punch.Last(recent), record.Timestamp)
+	if err != nil {
+		return err
+	}
+	record.Type = punchType
+
+	if record.ID.IsZero() {
+		record.ID = primitive.NewObjectID()
+	}
+	key := sequenceKey(record.CompanyID, record.BranchID)
+	head := r.heads[key]
+	record.NSR = head.NSR + 1
+	record.Hash = punch.Hash(record, head.Hash)
+	r.heads[key] = ChainHead{NSR: record.NSR, Hash: record.Hash}
+
+	r.records = append(r.records, *record)
+	return nil
+}
+
+func (r *memoryTimeRecordRepository) CreateEvent(ctx context.Context, event *models.RepEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if event.ID.IsZero() {
+		event.ID = primitive.NewObjectID()
+	}
+	key := sequenceKey(event.CompanyID, event.BranchID)
+	head := r.heads[key]
+	event.NSR = head.NSR + 1
+	r.heads[key] = ChainHead{NSR: event.NSR, Hash: head.Hash}
+
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *memoryTimeRecordRepository) FindByUser(ctx context.Context, companyID, userID primitive.ObjectID, start, end time.Time) ([]models.TimeRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.findByUser(companyID, userID, start, end), nil
+}
+
+// findByUser deve ser chamado com r.mu travado.
+func (r *memoryTimeRecordRepository) findByUser(companyID, userID primitive.ObjectID, start, end time.Time) []models.TimeRecord {
+	records := []models.TimeRecord{}
+	for _, record := range r.records {
+		if record.CompanyID != companyID || record.UserID != userID {
+			continue
+		}
+		if record.Timestamp.Before(start) || !record.Timestamp.Before(end) {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+	return records
+}
+
+func (r *memoryTimeRecordRepository) FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.TimeRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, record := range r.records {
+		if record.ID == id && record.CompanyID == companyID {
+			return &record, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryTimeRecordRepository) FindByBranch(ctx context.Context, companyID, branchID primitive.ObjectID, start, end time.Time) ([]models.TimeRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := []models.TimeRecord{}
+	for _, record := range r.records {
+		if record.CompanyID != companyID || record.BranchID != branchID {
+			continue
+		}
+		if record.Timestamp.Before(start) || !record.Timestamp.Before(end) {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+	return records, nil
+}
+
+func (r *memoryTimeRecordRepository) FindEvents(ctx context.Context, companyID, branchID primitive.ObjectID, start, end time.Time) ([]models.RepEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := []models.RepEvent{}
+	for _, event := range r.findEvents(companyID, branchID) {
+		if !event.CreatedAt.Before(start) && event.CreatedAt.Before(end) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (r *memoryTimeRecordRepository) FindChain(ctx context.Context, companyID, branchID primitive.ObjectID) ([]models.TimeRecord, []models.RepEvent, ChainHead, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := []models.TimeRecord{}
+	for _, record := range r.records {
+		if record.CompanyID == companyID && record.BranchID == branchID && record.NSR > 0 {
+			records = append(records, record)
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].NSR < records[j].NSR
+	})
+	return records, r.findEvents(companyID, branchID), r.heads[sequenceKey(companyID, branchID)], nil
+}
+
+// findEvents retorna os eventos do estabelecimento ordenados por NSR. Deve ser
+// chamado com r.mu travado.
+func (r *memoryTimeRecordRepository) findEvents(companyID, branchID primitive.ObjectID) []models.RepEvent {
+	events := []models.RepEvent{}
+	for _, event := range r.events {
+		if event.CompanyID == companyID && event.BranchID == branchID {
+			events = append(events, event)
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].NSR < events[j].NSR
+	})
+	return events
+}
@@ -0,0 +1,395 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"ponto-digital-api/internal/models"
+)
+
+// UserRepository persiste os usuários da API. Com exceção de FindByEmail,
+// usado no login, todas as consultas são restritas a uma empresa.
+type UserRepository interface {
+	// Create insere o usuário e preenche user.ID com o identificador gerado.
+	Create(ctx context.Context, user *models.User) error
+	FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	// As atualizações a seguir gravam apenas os campos indicados e a data de
+	// alteração do usuário identificado por user.ID dentro da empresa
+	// user.CompanyID, sem substituir alterações simultâneas nos demais campos,
+	// como as tentativas erradas de PIN contadas por RecordPinFailure.
+
+	// UpdateProfile grava o nome e a senha.
+	UpdateProfile(ctx context.Context, user *models.User) error
+	// UpdateRole grava o perfil e o gestor.
+	UpdateRole(ctx context.Context, user *models.User) error
+	// UpdateBranch grava o estabelecimento.
+	UpdateBranch(ctx context.Context, user *models.User) error
+	// UpdateCPF grava o CPF.
+	UpdateCPF(ctx context.Context, user *models.User) error
+	// UpdateSchedule grava a jornada, o grupo de jornada e a escala.
+	UpdateSchedule(ctx context.Context, user *models.User) error
+	// ChangePin grava o PIN e o histórico, mantendo o contador de tentativas
+	// erradas e o bloqueio.
+	ChangePin(ctx context.Context, user *models.User) error
+	// UpdatePin grava o PIN, o histórico, o contador de tentativas erradas e
+	// o bloqueio.
+	UpdatePin(ctx context.Context, user *models.User) error
+	// RecordPinFailure conta uma tentativa errada de PIN, recomeçando a
+	// contagem se o bloqueio anterior expirou em now, e bloqueia o PIN até
+	// lockedUntil ao atingir maxAttempts, em uma única operação atômica.
+	// Retorna o usuário atualizado. Com o PIN ainda bloqueado, nada muda.
+	RecordPinFailure(ctx context.Context, companyID, id primitive.ObjectID, maxAttempts int, now, lockedUntil time.Time) (*models.User, error)
+	// List retorna os usuários que atendem ao filtro, ordenados por nome.
+	List(ctx context.Context, filter UserFilter) ([]models.User, error)
+}
+
+// UserFilter restringe os usuários retornados por List. CompanyID é sempre
+// aplicado; os demais campos, quando vazios, não filtram.
+type UserFilter struct {
+	CompanyID primitive.ObjectID
+	ManagerID primitive.ObjectID
+	Role      models.Role
+}
+
+func (f UserFilter) matches(user *models.User) bool {
+	if user.CompanyID != f.CompanyID {
+		return false
+	}
+	if !f.ManagerID.IsZero() && user.ManagerID != f.ManagerID {
+		return false
+	}
+	if f.Role != "" && user.EffectiveRole() != f.Role {
+		return false
+	}
+	return true
+}
+
+type mongoUserRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoUserRepository cria um UserRepository sobre a coleção "users".
+func NewMongoUserRepository(db *mongo.Database) UserRepository {
+	return &mongoUserRepository{collection: db.Collection("users")}
+}
+
+func (r *mongoUserRepository) Create(ctx context.Context, user *models.User) error {
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, user)
+	return err
+}
+
+func (r *mongoUserRepository) FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.User, error) {
+	return r.findOne(ctx, bson.M{"_id": id, "company_id": tenantFilter(companyID)})
+}
+
+func (r *mongoUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.findOne(ctx, bson.M{"email": email})
+}
+
+func (r *mongoUserRepository) findOne(ctx context.Context, filter bson.M) (*models.User, error) {
+	var user models.User
+	err := r.collection.FindOne(ctx, filter).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *mongoUserRepository) UpdateProfile(ctx context.Context, user *models.User) error {
+	return r.updateFields(ctx, user, bson.M{"name": user.Name, "password": user.Password})
+}
+
+func (r *mongoUserRepository) UpdateRole(ctx context.Context, user *models.User) error {
+	return r.updateFields(ctx, user, bson.M{"role": user.Role, "manager_id": user.ManagerID})
+}
+
+func (r *mongoUserRepository) UpdateBranch(ctx context.Context, user *models.User) error {
+	return r.updateFields(ctx, user, bson.M{"branch_id": user.BranchID})
+}
+
+func (r *mongoUserRepository) UpdateCPF(ctx context.Context, user *models.User) error {
+	return r.updateFields(ctx, user, bson.M{"cpf": user.CPF})
+}
+
+func (r *mongoUserRepository) UpdateSchedule(ctx context.Context, user *models.User) error {
+	return r.updateFields(ctx, user, bson.M{
+		"schedule_id":  user.ScheduleID,
+		"group_id":     user.GroupID,
+		"shift_id":     user.ShiftID,
+		"shift_anchor": user.ShiftAnchor,
+	})
+}
+
+func (r *mongoUserRepository) ChangePin(ctx context.Context, user *models.User) error {
+	return r.updateFields(ctx, user, bson.M{"pin": user.Pin, "pin_history": user.PinHistory})
+}
+
+func (r *mongoUserRepository) UpdatePin(ctx context.Context, user *models.User) error {
+	return r.updateFields(ctx, user, bson.M{
+		"pin":                 user.Pin,
+		"pin_history":         user.PinHistory,
+		"pin_failed_attempts": user.PinFailedAttempts,
+		"pin_locked_until":    user.PinLockedUntil,
+	})
+}
+
+// updateFields grava os campos informados e a data de alteração do usuário.
+// Os valores vazios são removidos do documento, como o omitempty do modelo
+// faz na inserção, para que as consultas por campo ausente continuem valendo.
+func (r *mongoUserRepository) updateFields(ctx context.Context, user *models.User, fields bson.M) error {
+	set := bson.M{"updated_at": user.UpdatedAt}
+	unset := bson.M{}
+	for key, value := range fields {
+		if v := reflect.ValueOf(value); !v.IsValid() || v.IsZero() {
+			unset[key] = ""
+		} else {
+			set[key] = value
+		}
+	}
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	filter := bson.M{"_id": user.ID, "company_id": tenantFilter(user.CompanyID)}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoUserRepository) RecordPinFailure(ctx context.Context, companyID, id primitive.ObjectID, maxAttempts int, now, lockedUntil time.Time) (*models.User, error) {
+	filter := bson.M{"_id": id, "company_id": tenantFilter(companyID)}
+
+	// A atualização em pipeline lê o contador e o bloqueio do próprio
+	// documento, de modo que tentativas simultâneas não se perdem
+	lock := bson.M{"$ifNull": bson.A{"$pin_locked_until", time.Time{}}}
+	active := bson.M{"$gt": bson.A{lock, now}}
+	expired := bson.M{"$and": bson.A{
+		bson.M{"$gt": bson.A{lock, time.Time{}}},
+		bson.M{"$lte": bson.A{lock, now}},
+	}}
+	attempts := bson.M{"$cond": bson.A{expired, 0, bson.M{"$ifNull": bson.A{"$pin_failed_attempts", 0}}}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"pin_failed_attempts": bson.M{"$cond": bson.A{active, "$pin_failed_attempts", bson.M{"$add": bson.A{attempts, 1}}}},
+			"pin_locked_until":    bson.M{"$cond": bson.A{expired, "$$REMOVE", "$pin_locked_until"}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"pin_locked_until": bson.M{"$cond": bson.A{
+				bson.M{"$and": bson.A{
+					bson.M{"$gte": bson.A{"$pin_failed_attempts", maxAttempts}},
+					bson.M{"$eq": bson.A{bson.M{"$ifNull": bson.A{"$pin_locked_until", nil}}, nil}},
+				}},
+				lockedUntil,
+				"$pin_locked_until",
+			}},
+		}}},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var user models.User
+	err := r.collection.FindOneAndUpdate(ctx, filter, pipeline, opts).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *mongoUserRepository) List(ctx context.Context, filter UserFilter) ([]models.User, error) {
+	query := bson.M{"company_id": tenantFilter(filter.CompanyID)}
+	if !filter.ManagerID.IsZero() {
+		query["manager_id"] = filter.ManagerID
+	}
+	if filter.Role == models.RoleEmployee {
+		// Cadastros antigos não possuem perfil e são tratados como funcionários
+		query["role"] = bson.M{"$in": bson.A{models.RoleEmployee, "", nil}}
+	} else if filter.Role != "" {
+		query["role"] = filter.Role
+	}
+	opts := options.Find().SetSort(bson.M{"name": 1})
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	users := []models.User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+type memoryUserRepository struct {
+	mu    sync.RWMutex
+	users map[primitive.ObjectID]models.User
+}
+
+// NewMemoryUserRepository cria um UserRepository mantido em memória.
+func NewMemoryUserRepository() UserRepository {
+	return &memoryUserRepository{users: make(map[primitive.ObjectID]models.User)}
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *memoryUserRepository) FindByID(ctx context.Context, companyID, id primitive.ObjectID) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok || user.CompanyID != companyID {
+		return nil, ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r *memoryUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Email == email {
+			return &user, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryUserRepository) UpdateProfile(ctx context.Context, user *models.User) error {
+	return r.update(user, func(current *models.User) {
+		current.Name = user.Name
+		current.Password = user.Password
+	})
+}
+
+func (r *memoryUserRepository) UpdateRole(ctx context.Context, user *models.User) error {
+	return r.update(user, func(current *models.User) {
+		current.Role = user.Role
+		current.ManagerID = user.ManagerID
+	})
+}
+
+func (r *memoryUserRepository) UpdateBranch(ctx context.Context, user *models.User) error {
+	return r.update(user, func(current *models.User) {
+		current.BranchID = user.BranchID
+	})
+}
+
+func (r *memoryUserRepository) UpdateCPF(ctx context.Context, user *models.User) error {
+	return r.update(user, func(current *models.User) {
+		current.CPF = user.CPF
+	})
+}
+
+func (r *memoryUserRepository) UpdateSchedule(ctx context.Context, user *models.User) error {
+	return r.update(user, func(current *models.User) {
+		current.ScheduleID = user.ScheduleID
+		current.GroupID = user.GroupID
+		current.ShiftID = user.ShiftID
+		current.ShiftAnchor = user.ShiftAnchor
+	})
+}
+
+func (r *memoryUserRepository) ChangePin(ctx context.Context, user *models.User) error {
+	return r.update(user, func(current *models.User) {
+		current.Pin = user.Pin
+		current.PinHistory = user.PinHistory
+	})
+}
+
+func (r *memoryUserRepository) UpdatePin(ctx context.Context, user *models.User) error {
+	return r.update(user, func(current *models.User) {
+		current.Pin = user.Pin
+		current.PinHistory = user.PinHistory
+		current.PinFailedAttempts = user.PinFailedAttempts
+		current.PinLockedUntil = user.PinLockedUntil
+	})
+}
+
+// update aplica apply ao usuário guardado e grava a data de alteração.
+func (r *memoryUserRepository) update(user *models.User, apply func(current *models.User)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.users[user.ID]
+	if !ok || current.CompanyID != user.CompanyID {
+		return ErrNotFound
+	}
+	apply(&current)
+	current.UpdatedAt = user.UpdatedAt
+	r.users[user.ID] = current
+	return nil
+}
+
+func (r *memoryUserRepository) RecordPinFailure(ctx context.Context, companyID, id primitive.ObjectID, maxAttempts int, now, lockedUntil time.Time) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok || user.CompanyID != companyID {
+		return nil, ErrNotFound
+	}
+	if user.PinLockedUntil != nil && now.Before(*user.PinLockedUntil) {
+		return &user, nil
+	}
+	if user.PinLockedUntil != nil {
+		user.PinFailedAttempts = 0
+		user.PinLockedUntil = nil
+	}
+	user.PinFailedAttempts++
+	if user.PinFailedAttempts >= maxAttempts {
+		user.PinLockedUntil = &lockedUntil
+	}
+	r.users[id] = user
+	return &user, nil
+}
+
+func (r *memoryUserRepository) List(ctx context.Context, filter UserFilter) ([]models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := []models.User{}
+	for _, user := range r.users {
+		if filter.matches(&user) {
+			users = append(users, user)
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Name < users[j].Name
+	})
+	return users, nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"ponto-digital-api/internal/models"
+)
+
+// WebAuthnChallengeRepository guarda os desafios WebAuthn emitidos até que
+// sejam usados. Cada desafio é consumido uma única vez.
+type WebAuthnChallengeRepository interface {
+	// Create insere o desafio e remove os já expirados.
+	Create(ctx context.Context, challenge *models.WebAuthnChallenge) error
+	// Consume remove e retorna o desafio do usuário para a cerimônia kind,
+	// expirado ou não, ou retorna ErrNotFound.
+	Consume(ctx context.Context, userID primitive.ObjectID, kind models.WebAuthnChallengeKind, challenge []byte) (*models.WebAuthnChallenge, error)
+}
+
+type mongoWebAuthnChallengeRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoWebAuthnChallengeRepository cria um WebAuthnChallengeRepository
+// sobre a coleção "webauthn_challenges".
+func NewMongoWebAuthnChallengeRepository(db *mongo.Database) WebAuthnChallengeRepository {
+	return &mongoWebAuthnChallengeRepository{collection: db.Collection("webauthn_challenges")}
+}
+
+func (r *mongoWebAuthnChallengeRepository) Create(ctx context.Context, challenge *models.WebAuthnChallenge) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"expires_at": bson.M{"$lt": time.Now()}}); err != nil {
+		return err
+	}
+
+	if challenge.ID.IsZero() {
+		challenge.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, challenge)
+	return err
+}
+
+func (r *mongoWebAuthnChallengeRepository) Consume(ctx context.Context, userID primitive.ObjectID, kind models.WebAuthnChallengeKind, challenge []byte) (*models.WebAuthnChallenge, error) {
+	filter := bson.M{"user_id": userID, "kind": kind, "challenge": challenge}
+
+	var consumed models.WebAuthnChallenge
+	err := r.collection.FindOneAndDelete(ctx, filter).Decode(&consumed)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &consumed, nil
+}
+
+type memoryWebAuthnChallengeRepository struct {
+	mu         sync.Mutex
+	challenges map[primitive.ObjectID]models.WebAuthnChallenge
+}
+
+// NewMemoryWebAuthnChallengeRepository cria um WebAuthnChallengeRepository
+// mantido em memória.
+func NewMemoryWebAuthnChallengeRepository() WebAuthnChallengeRepository {
+	return &memoryWebAuthnChallengeRepository{challenges: make(map[primitive.ObjectID]models.WebAuthnChallenge)}
+}
+
+func (r *memoryWebAuthnChallengeRepository) Create(ctx context.Context, challenge *models.WebAuthnChallenge) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, existing := range r.challenges {
+		if existing.ExpiresAt.Before(now) {
+			delete(r.challenges, id)
+		}
+	}
+
+	if challenge.ID.IsZero() {
+		challenge.ID = primitive.NewObjectID()
+	}
+	r.challenges[challenge.ID] = *challenge
+	return nil
+}
+
+func (r *memoryWebAuthnChallengeRepository) Consume(ctx context.Context, userID primitive.ObjectID, kind models.WebAuthnChallengeKind, challenge []byte) (*models.WebAuthnChallenge, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, existing := range r.challenges {
+		if existing.UserID == userID && existing.Kind == kind && bytes.Equal(existing.Challenge, challenge) {
+			delete(r.challenges, id)
+			return &existing, nil
+		}
+	}
+	return nil, ErrNotFound
+}
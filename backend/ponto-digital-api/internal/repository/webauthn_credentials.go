@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"ponto-digital-api/internal/models"
+)
+
+// WebAuthnCredentialRepository persiste as credenciais WebAuthn dos usuários.
+// Todas as consultas são restritas à empresa informada.
+type WebAuthnCredentialRepository interface {
+	// Create insere a credencial e preenche credential.ID com o
+	// identificador gerado.
+	Create(ctx context.Context, credential *models.WebAuthnCredential) error
+	// FindByCredentialID retorna a credencial com o identificador atribuído
+	// pelo autenticador ou ErrNotFound.
+	FindByCredentialID(ctx context.Context, companyID primitive.ObjectID, credentialID []byte) (*models.WebAuthnCredential, error)
+	// ListByUser retorna as credenciais do usuário, das mais antigas para as
+	// mais recentes.
+	ListByUser(ctx context.Context, companyID, userID primitive.ObjectID) ([]models.WebAuthnCredential, error)
+	// Update substitui o documento da credencial identificada por
+	// credential.ID dentro da empresa credential.CompanyID.
+	Update(ctx context.Context, credential *models.WebAuthnCredential) error
+	// Delete remove a credencial id do usuário ou retorna ErrNotFound.
+	Delete(ctx context.Context, companyID, userID, id primitive.ObjectID) error
+}
+
+type mongoWebAuthnCredentialRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoWebAuthnCredentialRepository cria um WebAuthnCredentialRepository
+// sobre a coleção "webauthn_credentials".
+func NewMongoWebAuthnCredentialRepository(db *mongo.Database) WebAuthnCredentialRepository {
+	return &mongoWebAuthnCredentialRepository{collection: db.Collection("webauthn_credentials")}
+}
+
+func (r *mongoWebAuthnCredentialRepository) Create(ctx context.Context, credential *models.WebAuthnCredential) error {
+	if credential.ID.IsZero() {
+		credential.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, credential)
+	return err
+}
+
+func (r *mongoWebAuthnCredentialRepository) FindByCredentialID(ctx context.Context, companyID primitive.ObjectID, credentialID []byte) (*models.WebAuthnCredential, error) {
+	filter := bson.M{"company_id": tenantFilter(companyID), "credential_id": credentialID}
+
+	var credential models.WebAuthnCredential
+	err := r.collection.FindOne(ctx, filter).Decode(&credential)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+func (r *mongoWebAuthnCredentialRepository) ListByUser(ctx context.Context, companyID, userID primitive.ObjectID) ([]models.WebAuthnCredential, error) {
+	filter := bson.M{"company_id": tenantFilter(companyID), "user_id": userID}
+	opts := options.Find().SetSort(bson.M{"created_at": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	credentials := []models.WebAuthnCredential{}
+	if err := cursor.All(ctx, &credentials); err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+func (r *mongoWebAuthnCredentialRepository) Update(ctx context.Context, credential *models.WebAuthnCredential) error {
+	filter := bson.M{"_id": credential.ID, "company_id": tenantFilter(credential.CompanyID)}
+	result, err := r.collection.ReplaceOne(ctx, filter, credential)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoWebAuthnCredentialRepository) Delete(ctx context.Context, companyID, userID, id primitive.ObjectID) error {
+	filter := bson.M{"_id": id, "company_id": tenantFilter(companyID), "user_id": userID}
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type memoryWebAuthnCredentialRepository struct {
+	mu          sync.RWMutex
+	credentials map[primitive.ObjectID]models.WebAuthnCredential
+}
+
+// NewMemoryWebAuthnCredentialRepository cria um WebAuthnCredentialRepository
+// mantido em memória.
+func NewMemoryWebAuthnCredentialRepository() WebAuthnCredentialRepository {
+	return &memoryWebAuthnCredentialRepository{credentials: make(map[primitive.ObjectID]models.WebAuthnCredential)}
+}
+
+func (r *memoryWebAuthnCredentialRepository) Create(ctx context.Context, credential *models.WebAuthnCredential) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if credential.ID.IsZero() {
+		credential.ID = primitive.NewObjectID()
+	}
+	r.credentials[credential.ID] = *credential
+	return nil
+}
+
+func (r *memoryWebAuthnCredentialRepository) FindByCredentialID(ctx context.Context, companyID primitive.ObjectID, credentialID []byte) (*models.WebAuthnCredential, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, credential := range r.credentials {
+		if credential.CompanyID == companyID && bytes.Equal(credential.CredentialID, credentialID) {
+			return &credential, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryWebAuthnCredentialRepository) ListByUser(ctx context.Context, companyID, userID primitive.ObjectID) ([]models.WebAuthnCredential, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	credentials := []models.WebAuthnCredential{}
+	for _, credential := range r.credentials {
+		if credential.CompanyID == companyID && credential.UserID == userID {
+			credentials = append(credentials, credential)
+		}
+	}
+
+	sort.Slice(credentials, func(i, j int) bool {
+		return credentials[i].CreatedAt.Before(credentials[j].CreatedAt)
+	})
+	return credentials, nil
+}
+
+func (r *memoryWebAuthnCredentialRepository) Update(ctx context.Context, credential *models.WebAuthnCredential) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.credentials[credential.ID]
+	if !ok || existing.CompanyID != credential.CompanyID {
+		return ErrNotFound
+	}
+	r.credentials[credential.ID] = *credential
+	return nil
+}
+
+func (r *memoryWebAuthnCredentialRepository) Delete(ctx context.Context, companyID, userID, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	credential, ok := r.credentials[id]
+	if !ok || credential.CompanyID != companyID || credential.UserID != userID {
+		return ErrNotFound
+	}
+	delete(r.credentials, id)
+	return nil
+}
@@ -0,0 +1,208 @@
+// Package session controla as sessões de login: cada login abre uma sessão
+// com um refresh token, trocado a cada renovação, e os tokens de acesso de
+// curta duração valem apenas enquanto a sessão estiver ativa. Encerrar a
+// sessão (logout) ou todas as sessões do usuário invalida os tokens já
+// emitidos.
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+	"ponto-digital-api/internal/utils"
+)
+
+const (
+	// RefreshTTL é o tempo sem renovação após o qual a sessão expira.
+	RefreshTTL = 30 * 24 * time.Hour
+	// ReuseGrace é o intervalo após a troca em que a reapresentação do
+	// refresh token substituído é recusada sem encerrar a sessão, pois pode
+	// vir de renovações simultâneas do mesmo cliente. Depois dele, o reuso
+	// indica um token vazado e encerra a sessão.
+	ReuseGrace = 30 * time.Second
+)
+
+// ErrInvalid indica um refresh token ou uma sessão inválida, encerrada ou
+// expirada.
+var ErrInvalid = errors.New("Sessão inválida ou expirada")
+
+// Tokens são as credenciais entregues ao cliente no login e em cada
+// renovação.
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int // validade do token de acesso, em segundos
+}
+
+type Service struct {
+	store *repository.Store
+}
+
+func NewService(store *repository.Store) *Service {
+	return &Service{store: store}
+}
+
+// Start abre uma sessão para o usuário no aparelho identificado por ip e
+// userAgent.
+func (s *Service) Start(ctx context.Context, user *models.User, ip, userAgent string, now time.Time) (*Tokens, error) {
+	secret, hash, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	session := models.Session{
+		CompanyID:   user.CompanyID,
+		UserID:      user.ID,
+		RefreshHash: hash,
+		RotatedAt:   now,
+		IP:          ip,
+		UserAgent:   userAgent,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(RefreshTTL),
+	}
+	if err := s.store.Sessions.Create(ctx, &session); err != nil {
+		return nil, err
+	}
+	return issue(user, &session, secret)
+}
+
+// Refresh troca o refresh token por um novo e emite um token de acesso com
+// os dados atuais do usuário. O reuso de um refresh token já trocado, fora de
+// ReuseGrace, encerra a sessão.
+func (s *Service) Refresh(ctx context.Context, refreshToken, ip string, now time.Time) (*Tokens, error) {
+	id, presented, ok := parseRefreshToken(refreshToken)
+	if !ok {
+		return nil, ErrInvalid
+	}
+
+	session, err := s.store.Sessions.FindByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !session.Active(now) {
+		return nil, ErrInvalid
+	}
+
+	if !sameHash(presented, session.RefreshHash) {
+		if session.PreviousHash != "" && sameHash(presented, session.PreviousHash) {
+			err := s.store.Sessions.RevokeReused(ctx, id, presented, now.Add(-ReuseGrace), now)
+			if err != nil && !errors.Is(err, repository.ErrNotFound) {
+				return nil, err
+			}
+		}
+		return nil, ErrInvalid
+	}
+
+	user, err := s.store.Users.FindByID(ctx, session.CompanyID, session.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A troca só acontece se a sessão ainda estiver ativa com o token
+	// apresentado: um logout ou outra renovação concorrente a impedem
+	secret, hash, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+	err = s.store.Sessions.Rotate(ctx, id, presented, hash, ip, now, now.Add(RefreshTTL))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	return issue(user, session, secret)
+}
+
+// Check retorna ErrInvalid se a sessão de um token de acesso foi encerrada
+// ou expirou.
+func (s *Service) Check(ctx context.Context, id primitive.ObjectID, now time.Time) error {
+	session, err := s.store.Sessions.FindByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrInvalid
+	}
+	if err != nil {
+		return err
+	}
+	if !session.Active(now) {
+		return ErrInvalid
+	}
+	return nil
+}
+
+// Revoke encerra a sessão id. Encerrar uma sessão já encerrada não é erro.
+func (s *Service) Revoke(ctx context.Context, id primitive.ObjectID, now time.Time) error {
+	err := s.store.Sessions.Revoke(ctx, id, now)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// RevokeAll encerra as sessões do usuário em todos os aparelhos, exceto a
+// sessão except, que pode ser zero.
+func (s *Service) RevokeAll(ctx context.Context, user *models.User, except primitive.ObjectID, now time.Time) error {
+	return s.store.Sessions.RevokeByUser(ctx, user.CompanyID, user.ID, except, now)
+}
+
+func issue(user *models.User, session *models.Session, secret string) (*Tokens, error) {
+	accessToken, err := utils.GenerateToken(user, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: session.ID.Hex() + "." + secret,
+		ExpiresIn:    int(utils.AccessTokenTTL / time.Second),
+	}, nil
+}
+
+// newSecret gera a parte secreta de um refresh token e o hash guardado na
+// sessão.
+func newSecret() (secret, hash string, err error) {
+	value := make([]byte, 32)
+	if _, err := rand.Read(value); err != nil {
+		return "", "", err
+	}
+	secret = base64.RawURLEncoding.EncodeToString(value)
+	return secret, hashSecret(secret), nil
+}
+
+// parseRefreshToken separa o refresh token, no formato "<sessão>.<segredo>",
+// e retorna o ID da sessão e o hash do segredo.
+func parseRefreshToken(token string) (primitive.ObjectID, string, bool) {
+	sessionID, secret, found := strings.Cut(token, ".")
+	if !found || secret == "" {
+		return primitive.NilObjectID, "", false
+	}
+	id, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return primitive.NilObjectID, "", false
+	}
+	return id, hashSecret(secret), true
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func sameHash(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
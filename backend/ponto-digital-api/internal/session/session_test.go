@@ -0,0 +1,153 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/repository"
+)
+
+// newService cria um Service sobre um repositório em memória com um usuário
+// cadastrado.
+func newService(t *testing.T) (*Service, *models.User) {
+	t.Helper()
+	store := repository.NewMemoryStore()
+	user := models.User{Name: "Ana", Email: "ana@acme.com.br", CompanyID: primitive.NewObjectID(), Role: models.RoleEmployee}
+	if err := store.Users.Create(context.Background(), &user); err != nil {
+		t.Fatal(err)
+	}
+	return NewService(store), &user
+}
+
+// sessionID extrai o ID da sessão de um refresh token.
+func sessionID(t *testing.T, tokens *Tokens) primitive.ObjectID {
+	t.Helper()
+	id, _, ok := parseRefreshToken(tokens.RefreshToken)
+	if !ok {
+		t.Fatalf("refresh token %q is malformed", tokens.RefreshToken)
+	}
+	return id
+}
+
+func TestRefresh(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		reuseAfter  time.Duration // reapresentação do token trocado, após a troca
+		wantRevoked bool
+	}{
+		{name: "reuso dentro da tolerância mantém a sessão", reuseAfter: ReuseGrace / 2},
+		{name: "reuso após a tolerância encerra a sessão", reuseAfter: ReuseGrace + time.Second, wantRevoked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, user := newService(t)
+			first, err := service.Start(ctx, user, "10.0.0.1", "Firefox", start)
+			if err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			id := sessionID(t, first)
+
+			rotatedAt := start.Add(time.Hour)
+			second, err := service.Refresh(ctx, first.RefreshToken, "10.0.0.2", rotatedAt)
+			if err != nil {
+				t.Fatalf("Refresh() error = %v", err)
+			}
+			if second.RefreshToken == first.RefreshToken || sessionID(t, second) != id || second.AccessToken == "" {
+				t.Fatalf("Refresh() = %+v, want a new refresh token for the same session", second)
+			}
+
+			reusedAt := rotatedAt.Add(tt.reuseAfter)
+			if _, err := service.Refresh(ctx, first.RefreshToken, "10.0.0.3", reusedAt); !errors.Is(err, ErrInvalid) {
+				t.Fatalf("Refresh() with the replaced token error = %v, want %v", err, ErrInvalid)
+			}
+
+			err = service.Check(ctx, id, reusedAt)
+			if tt.wantRevoked != errors.Is(err, ErrInvalid) {
+				t.Errorf("Check() after reuse error = %v, want revoked %v", err, tt.wantRevoked)
+			}
+			_, err = service.Refresh(ctx, second.RefreshToken, "10.0.0.2", reusedAt)
+			if tt.wantRevoked != errors.Is(err, ErrInvalid) {
+				t.Errorf("Refresh() with the current token error = %v, want revoked %v", err, tt.wantRevoked)
+			}
+		})
+	}
+}
+
+func TestRefreshInvalid(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+	service, user := newService(t)
+
+	tokens, err := service.Start(ctx, user, "10.0.0.1", "Firefox", start)
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	id := sessionID(t, tokens)
+
+	tests := []struct {
+		name  string
+		token string
+		at    time.Time
+	}{
+		{name: "token sem segredo", token: id.Hex() + ".", at: start},
+		{name: "sessão inexistente", token: primitive.NewObjectID().Hex() + ".segredo", at: start},
+		{name: "segredo desconhecido", token: id.Hex() + ".segredo", at: start},
+		{name: "sessão expirada", token: tokens.RefreshToken, at: start.Add(RefreshTTL)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.Refresh(ctx, tt.token, "10.0.0.1", tt.at); !errors.Is(err, ErrInvalid) {
+				t.Errorf("Refresh() error = %v, want %v", err, ErrInvalid)
+			}
+		})
+	}
+
+	// Um segredo desconhecido não encerra a sessão
+	if err := service.Check(ctx, id, start); err != nil {
+		t.Errorf("Check() error = %v, want nil", err)
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+	service, user := newService(t)
+
+	start := func() *Tokens {
+		tokens, err := service.Start(ctx, user, "10.0.0.1", "Firefox", now)
+		if err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		return tokens
+	}
+	phone, laptop, tablet := start(), start(), start()
+
+	if err := service.Revoke(ctx, sessionID(t, phone), now); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if err := service.Revoke(ctx, sessionID(t, phone), now); err != nil {
+		t.Errorf("Revoke() of a revoked session error = %v, want nil", err)
+	}
+	if _, err := service.Refresh(ctx, phone.RefreshToken, "10.0.0.1", now); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Refresh() after Revoke() error = %v, want %v", err, ErrInvalid)
+	}
+
+	if err := service.RevokeAll(ctx, user, sessionID(t, laptop), now); err != nil {
+		t.Fatalf("RevokeAll() error = %v", err)
+	}
+	if err := service.Check(ctx, sessionID(t, laptop), now); err != nil {
+		t.Errorf("Check() of the kept session error = %v, want nil", err)
+	}
+	if err := service.Check(ctx, sessionID(t, tablet), now); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Check() of another session error = %v, want %v", err, ErrInvalid)
+	}
+}
@@ -0,0 +1,185 @@
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"sort"
+	"time"
+)
+
+var (
+	oidData                 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData           = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidContentType          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidSigningTime          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+	oidSigningCertificateV2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 47}
+	oidSHA256               = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA256WithRSA        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}
+	oidECDSAWithSHA256      = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+)
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	EncapContentInfo encapContentInfo
+	Certificates     asn1.RawValue
+	SignerInfos      []signerInfo `asn1:"set"`
+}
+
+// encapContentInfo omite o conteúdo: a assinatura é destacada do arquivo.
+type encapContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+}
+
+type signerInfo struct {
+	Version            int
+	SID                issuerAndSerial
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttributes   asn1.RawValue
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+}
+
+type issuerAndSerial struct {
+	Issuer asn1.RawValue
+	Serial *big.Int
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+// essCertIDv2 omite o algoritmo de hash, que por padrão é o SHA-256.
+type essCertIDv2 struct {
+	CertHash []byte
+}
+
+type signingCertificateV2 struct {
+	Certs []essCertIDv2
+}
+
+// SignDetached retorna a assinatura CMS destacada de data, em DER, com os
+// atributos assinados do CAdES-BES: tipo de conteúdo, resumo da mensagem,
+// horário da assinatura e certificado do signatário.
+func (s *Signer) SignDetached(data []byte, signingTime time.Time) ([]byte, error) {
+	digest := sha256.Sum256(data)
+	certHash := sha256.Sum256(s.Certificate.Raw)
+
+	attributes, err := signedAttributes(digest[:], certHash[:], signingTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// A assinatura cobre os atributos codificados como SET OF; na estrutura
+	// eles aparecem com a marca implícita [0]
+	attributesDigest := sha256.Sum256(attributes.FullBytes)
+	signature, err := s.key.Sign(rand.Reader, attributesDigest[:], crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	signatureAlgorithm, err := s.signatureAlgorithm()
+	if err != nil {
+		return nil, err
+	}
+
+	sha256Algorithm := pkix.AlgorithmIdentifier{Algorithm: oidSHA256}
+	content := signedData{
+		Version:          1,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{sha256Algorithm},
+		EncapContentInfo: encapContentInfo{ContentType: oidData},
+		Certificates: asn1.RawValue{
+			Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true,
+			Bytes: s.Certificate.Raw,
+		},
+		SignerInfos: []signerInfo{{
+			Version: 1,
+			SID: issuerAndSerial{
+				Issuer: asn1.RawValue{FullBytes: s.Certificate.RawIssuer},
+				Serial: s.Certificate.SerialNumber,
+			},
+			DigestAlgorithm: sha256Algorithm,
+			SignedAttributes: asn1.RawValue{
+				Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true,
+				Bytes: attributes.Bytes,
+			},
+			SignatureAlgorithm: signatureAlgorithm,
+			Signature:          signature,
+		}},
+	}
+
+	inner, err := asn1.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue
+	}{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: inner},
+	})
+}
+
+// signedAttributes codifica os atributos assinados como um SET OF em DER,
+// ordenado pela codificação de cada atributo.
+func signedAttributes(digest, certHash []byte, signingTime time.Time) (asn1.RawValue, error) {
+	values := []struct {
+		oid   asn1.ObjectIdentifier
+		value interface{}
+	}{
+		{oidContentType, oidData},
+		{oidSigningTime, signingTime.UTC()},
+		{oidMessageDigest, digest},
+		{oidSigningCertificateV2, signingCertificateV2{Certs: []essCertIDv2{{CertHash: certHash}}}},
+	}
+
+	var encoded [][]byte
+	for _, item := range values {
+		value, err := asn1.Marshal(item.value)
+		if err != nil {
+			return asn1.RawValue{}, err
+		}
+		der, err := asn1.Marshal(attribute{
+			Type:   item.oid,
+			Values: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: value},
+		})
+		if err != nil {
+			return asn1.RawValue{}, err
+		}
+		encoded = append(encoded, der)
+	}
+	sort.Slice(encoded, func(i, j int) bool {
+		return bytes.Compare(encoded[i], encoded[j]) < 0
+	})
+
+	set, err := asn1.Marshal(asn1.RawValue{
+		Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true,
+		Bytes: bytes.Join(encoded, nil),
+	})
+	if err != nil {
+		return asn1.RawValue{}, err
+	}
+	var raw asn1.RawValue
+	_, err = asn1.Unmarshal(set, &raw)
+	return raw, err
+}
+
+func (s *Signer) signatureAlgorithm() (pkix.AlgorithmIdentifier, error) {
+	switch s.key.Public().(type) {
+	case *rsa.PublicKey:
+		return pkix.AlgorithmIdentifier{Algorithm: oidSHA256WithRSA, Parameters: asn1.NullRawValue}, nil
+	case *ecdsa.PublicKey:
+		return pkix.AlgorithmIdentifier{Algorithm: oidECDSAWithSHA256}, nil
+	}
+	return pkix.AlgorithmIdentifier{}, errors.New("tipo de chave não suportado")
+}
@@ -0,0 +1,32 @@
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+)
+
+// Sign assina o SHA-256 de data com a chave do certificado. A assinatura é
+// PKCS #1 v1.5 nas chaves RSA e ASN.1 DER nas chaves ECDSA.
+func (s *Signer) Sign(data []byte) ([]byte, error) {
+	digest := sha256.Sum256(data)
+	return s.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+// Verify confere uma assinatura feita por Sign com a chave deste certificado.
+func (s *Signer) Verify(data, signature []byte) error {
+	var algorithm x509.SignatureAlgorithm
+	switch s.key.Public().(type) {
+	case *rsa.PublicKey:
+		algorithm = x509.SHA256WithRSA
+	case *ecdsa.PublicKey:
+		algorithm = x509.ECDSAWithSHA256
+	default:
+		return errors.New("tipo de chave não suportado")
+	}
+	return s.Certificate.CheckSignature(algorithm, data, signature)
+}
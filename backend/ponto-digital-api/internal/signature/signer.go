@@ -0,0 +1,189 @@
+// Package signature assina os arquivos fiscais do ponto com assinaturas
+// destacadas no formato CMS (PKCS #7), o formato dos arquivos .p7s exigidos
+// pela Portaria 671, e os comprovantes de registro de ponto com assinaturas
+// simples, conferidas pelo próprio servidor.
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Signer assina dados com a chave privada do certificado do desenvolvedor ou
+// do empregador.
+type Signer struct {
+	Certificate *x509.Certificate
+	key         crypto.Signer
+}
+
+// Load lê o certificado e a chave privada de arquivos PEM. A chave pode ser
+// RSA ou ECDSA, nos formatos PKCS #1, SEC 1 ou PKCS #8.
+func Load(certFile, keyFile string) (*Signer, error) {
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("certificado PEM não encontrado")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	block, _ = pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("chave privada PEM não encontrada")
+	}
+	key, err := parseKey(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signer{Certificate: cert, key: key}, nil
+}
+
+// Arquivos do certificado autoassinado guardado por Open.
+const (
+	SelfSignedCertFile = "assinatura.crt"
+	SelfSignedKeyFile  = "assinatura.key"
+)
+
+// Open carrega o certificado e a chave de certFile e keyFile. Sem arquivos
+// configurados, usa o certificado autoassinado em nome de commonName guardado
+// em dir, criado no primeiro uso, para que as assinaturas continuem sendo
+// conferidas depois de reiniciar; com dir vazio, o certificado é descartável.
+func Open(certFile, keyFile, dir, commonName string) (*Signer, error) {
+	if certFile != "" || keyFile != "" {
+		return Load(certFile, keyFile)
+	}
+	if dir == "" {
+		return NewSelfSigned(commonName)
+	}
+
+	certFile = filepath.Join(dir, SelfSignedCertFile)
+	keyFile = filepath.Join(dir, SelfSignedKeyFile)
+	if _, err := os.Stat(certFile); !errors.Is(err, fs.ErrNotExist) {
+		return Load(certFile, keyFile)
+	}
+
+	signer, err := NewSelfSigned(commonName)
+	if err != nil {
+		return nil, err
+	}
+	if err := signer.save(certFile, keyFile); err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
+
+// save grava o certificado e a chave em PEM. O certificado é gravado por
+// último, pois a sua presença indica o par completo.
+func (s *Signer) save(certFile, keyFile string) error {
+	key, err := x509.MarshalPKCS8PrivateKey(s.key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(certFile), 0o700); err != nil {
+		return err
+	}
+	if err := writeFile(keyFile, &pem.Block{Type: "PRIVATE KEY", Bytes: key}, 0o600); err != nil {
+		return err
+	}
+	return writeFile(certFile, &pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate.Raw}, 0o644)
+}
+
+// writeFile grava o bloco PEM em um arquivo temporário e o renomeia, para que
+// uma leitura simultânea não encontre o arquivo incompleto.
+func writeFile(name string, block *pem.Block, perm fs.FileMode) error {
+	temp, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if err := pem.Encode(temp, block); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Chmod(perm); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), name)
+}
+
+func parseKey(block *pem.Block) (crypto.Signer, error) {
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch key := key.(type) {
+		case *rsa.PrivateKey:
+			return key, nil
+		case *ecdsa.PrivateKey:
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("tipo de chave privada não suportado: %s", block.Type)
+}
+
+// NewSelfSigned cria uma chave ECDSA P-256 e um certificado autoassinado em
+// nome de commonName. Serve para desenvolvimento; em produção os arquivos
+// devem ser assinados com um certificado ICP-Brasil carregado por Load.
+func NewSelfSigned(commonName string) (*Signer, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 62))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.AddDate(10, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signer{Certificate: cert, key: key}, nil
+}
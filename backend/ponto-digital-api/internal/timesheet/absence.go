@@ -0,0 +1,65 @@
+package timesheet
+
+import (
+	"time"
+
+	"ponto-digital-api/internal/models"
+)
+
+// AbsencePlan é o Plan que marca em Base os dias das ausências aprovadas.
+// Absences devem estar com as datas à meia-noite no fuso da empresa.
+type AbsencePlan struct {
+	Base     Plan
+	Absences []models.Absence
+}
+
+func (p AbsencePlan) Expect(date time.Time) Expectation {
+	expectation := p.Base.Expect(date)
+	for i := range p.Absences {
+		absence := &p.Absences[i]
+		if absence.Status != models.AbsenceApproved || date.Before(absence.StartDate) || date.After(absence.EndDate) {
+			continue
+		}
+
+		expectation.Absence = absence.Kind
+		if absence.Partial() {
+			start, _ := ParseClock(absence.StartTime)
+			end, _ := ParseClock(absence.EndTime)
+			expectation.AbonoStart = addMinutes(date, start)
+			expectation.AbonoEnd = addMinutes(expectation.AbonoStart, span(start, end))
+		}
+		break
+	}
+	return expectation
+}
+
+// applyAbono completa as horas trabalhadas do dia com o tempo abonado: todo o
+// tempo previsto, nas ausências do dia inteiro, ou o trecho da jornada
+// prevista coberto pelo abono parcial. O abono nunca passa do tempo que falta
+// para a jornada prevista, nem gera horas extras. O atraso coberto pelo abono
+// deixa de contar.
+func applyAbono(day *Day) {
+	expected := day.Expected
+	if expected.Absence == "" || !expected.Working {
+		return
+	}
+
+	window := time.Duration(expected.Minutes) * time.Minute
+	covered := window
+	if !expected.AbonoStart.IsZero() {
+		window = overlap(expected.AbonoStart, expected.AbonoEnd, expected.Entry, expected.Exit) -
+			overlap(expected.AbonoStart, expected.AbonoEnd, expected.BreakStart, expected.BreakEnd)
+		covered = 0
+		if !expected.AbonoStart.After(expected.Entry) && expected.AbonoEnd.After(expected.Entry) {
+			covered = expected.AbonoEnd.Sub(expected.Entry)
+		}
+	}
+
+	day.LateMinutes = max(0, day.LateMinutes-int(covered/time.Minute))
+	day.AdjustedLateMinutes = max(0, day.AdjustedLateMinutes-int(covered/time.Minute))
+
+	limit := int(window / time.Minute)
+	day.AbonoMinutes = max(0, min(limit, day.ExpectedMinutes-day.AdjustedWorkedMinutes))
+	day.AdjustedWorkedMinutes += day.AbonoMinutes
+	day.WorkedMinutes += max(0, min(limit, day.ExpectedMinutes-day.WorkedMinutes))
+}
@@ -0,0 +1,132 @@
+package timesheet
+
+import (
+	"fmt"
+	"time"
+)
+
+// Limites legais de jornada e descanso verificados nos dias de jornada.
+const (
+	// Intervalo intrajornada mínimo para jornadas acima de 6 horas e entre
+	// 4 e 6 horas (CLT, art. 71, caput e §1º)
+	BreakLongDayMinutes  = 60
+	BreakShortDayMinutes = 15
+	// Descanso mínimo entre duas jornadas (CLT, art. 66)
+	RestBetweenDaysMinutes = 11 * 60
+	// Jornada máxima: 8 horas com até 2 horas extras (CLT, art. 59)
+	MaxWorkedMinutes = 10 * 60
+)
+
+// ViolationKind identifica a regra de jornada ou descanso descumprida.
+type ViolationKind string
+
+const (
+	ViolationMissingBreak ViolationKind = "sem_intervalo"
+	ViolationShortBreak   ViolationKind = "intervalo_curto"
+	ViolationShortRest    ViolationKind = "interjornada_curta"
+	ViolationLongDay      ViolationKind = "jornada_excessiva"
+)
+
+// Violation é o descumprimento de um limite legal em um dia de jornada.
+// Minutes é o tempo apurado (intervalo, descanso ou trabalho) e LimitMinutes
+// o mínimo ou o máximo exigido.
+type Violation struct {
+	Date         string        `json:"date"`
+	Kind         ViolationKind `json:"kind"`
+	Minutes      int           `json:"minutes"`
+	LimitMinutes int           `json:"limit_minutes"`
+	Message      string        `json:"message"`
+}
+
+// checkCompliance verifica, em cada dia, o intervalo intrajornada, a
+// jornada máxima e o descanso desde a última saída, que pode estar em um dia
+// anterior ao período: previousEnd, zero quando desconhecida. O tempo
+// considerado é o das marcações, sem tolerância nem abono.
+func checkCompliance(days []Day, previousEnd time.Time) {
+	for i := range days {
+		day := &days[i]
+		day.Violations = []Violation{}
+		if len(day.Intervals) == 0 {
+			continue
+		}
+
+		first := day.Intervals[0]
+		if !previousEnd.IsZero() {
+			rest := minutesBetween(previousEnd, first.Start)
+			if rest < RestBetweenDaysMinutes {
+				day.addViolation(ViolationShortRest, rest, RestBetweenDaysMinutes,
+					fmt.Sprintf("Descanso de %s entre jornadas, abaixo de 11h", formatMinutes(rest)))
+			}
+		}
+		previousEnd = day.Intervals[len(day.Intervals)-1].End
+
+		worked := 0
+		longestBreak := 0
+		for j, interval := range day.Intervals {
+			worked += minutesBetween(interval.Start, interval.End)
+			if j > 0 {
+				longestBreak = max(longestBreak, minutesBetween(day.Intervals[j-1].End, interval.Start))
+			}
+		}
+
+		if worked > MaxWorkedMinutes {
+			day.addViolation(ViolationLongDay, worked, MaxWorkedMinutes,
+				fmt.Sprintf("Jornada de %s, acima de 10h", formatMinutes(worked)))
+		}
+
+		required := 0
+		switch {
+		case worked > 6*60:
+			required = BreakLongDayMinutes
+		case worked > 4*60:
+			required = BreakShortDayMinutes
+		}
+		switch {
+		case required == 0:
+		case longestBreak == 0:
+			day.addViolation(ViolationMissingBreak, 0, required,
+				fmt.Sprintf("Jornada de %s sem intervalo; mínimo de %s", formatMinutes(worked), formatMinutes(required)))
+		case longestBreak < required:
+			day.addViolation(ViolationShortBreak, longestBreak, required,
+				fmt.Sprintf("Intervalo de %s; mínimo de %s", formatMinutes(longestBreak), formatMinutes(required)))
+		}
+	}
+}
+
+func (d *Day) addViolation(kind ViolationKind, minutes, limit int, message string) {
+	d.Violations = append(d.Violations, Violation{
+		Date:         d.Date,
+		Kind:         kind,
+		Minutes:      minutes,
+		LimitMinutes: limit,
+		Message:      message,
+	})
+}
+
+// Violations reúne os descumprimentos dos dias encerrados até until. O dia
+// corrente ainda pode receber marcações e não é considerado.
+func Violations(days []Day, until time.Time) []Violation {
+	violations := []Violation{}
+	for i := range days {
+		if days[i].date.AddDate(0, 0, 1).After(until) {
+			continue
+		}
+		violations = append(violations, days[i].Violations...)
+	}
+	return violations
+}
+
+func minutesBetween(start, end time.Time) int {
+	if !end.After(start) {
+		return 0
+	}
+	return int(end.Sub(start) / time.Minute)
+}
+
+// formatMinutes formata uma duração como "1h05" ou "45min".
+func formatMinutes(minutes int) string {
+	if minutes < 60 {
+		return fmt.Sprintf("%dmin", minutes)
+	}
+	return fmt.Sprintf("%dh%02d", minutes/60, minutes%60)
+}
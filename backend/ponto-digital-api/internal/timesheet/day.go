@@ -0,0 +1,235 @@
+package timesheet
+
+import (
+	"time"
+
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/punch"
+)
+
+// DateLayout é o formato das datas dos dias de jornada.
+const DateLayout = "2006-01-02"
+
+// Interval é um período trabalhado, entre uma entrada e a saída seguinte.
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Day consolida as marcações e a jornada prevista de um dia. Uma saída após a
+// meia-noite pertence ao dia da entrada correspondente.
+type Day struct {
+	Date            string              `json:"date"`
+	Records         []models.TimeRecord `json:"records"`
+	ExpectedEntry   string              `json:"expected_entry,omitempty"`
+	ExpectedExit    string              `json:"expected_exit,omitempty"`
+	ExpectedMinutes int                 `json:"expected_minutes"`
+	WorkedMinutes   int                 `json:"worked_minutes"`
+	LateMinutes     int                 `json:"late_minutes"`
+	BalanceMinutes  int                 `json:"balance_minutes"` // trabalhado menos previsto
+
+	// Valores com a tolerância de marcação aplicada
+	AdjustedWorkedMinutes  int `json:"adjusted_worked_minutes"`
+	AdjustedLateMinutes    int `json:"adjusted_late_minutes"`
+	AdjustedBalanceMinutes int `json:"adjusted_balance_minutes"`
+
+	OvertimeMinutes       int           `json:"overtime_minutes"`
+	Overtime              []RateMinutes `json:"overtime"`                // horas extras por adicional
+	OvertimeExcessMinutes int           `json:"overtime_excess_minutes"` // além do limite diário
+	RestDay               bool          `json:"rest_day"`                // domingo de folga ou feriado
+	Holiday               string        `json:"holiday,omitempty"`
+
+	NightMinutes     int `json:"night_minutes"`      // trabalho noturno na hora do relógio
+	NightPaidMinutes int `json:"night_paid_minutes"` // trabalho noturno com a hora reduzida
+
+	Absence      models.AbsenceKind `json:"absence,omitempty"`
+	AbonoMinutes int                `json:"abono_minutes"` // já incluídos nas horas trabalhadas
+
+	Violations []Violation `json:"violations"` // intervalos, descanso e jornada máxima
+
+	Expected  Expectation `json:"-"`
+	Intervals []Interval  `json:"-"`
+	date      time.Time
+}
+
+// Time retorna a data do dia à meia-noite no fuso da empresa.
+func (d *Day) Time() time.Time {
+	return d.date
+}
+
+// Build monta os dias de jornada de [from, to) a partir das marcações,
+// ordenadas por horário, da jornada prevista e das regras da empresa. from e
+// to devem estar à meia-noite no fuso loc. Todos os dias do período são
+// retornados, mesmo sem marcações.
+func Build(records []models.TimeRecord, plan Plan, policy models.LaborPolicy, loc *time.Location, from, to time.Time) []Day {
+	var days []Day
+	index := make(map[string]int)
+	for date := from; date.Before(to); date = date.AddDate(0, 0, 1) {
+		expected := plan.Expect(date)
+		day := Day{
+			Date:            date.Format(DateLayout),
+			Records:         []models.TimeRecord{},
+			Overtime:        []RateMinutes{},
+			ExpectedMinutes: expected.Minutes,
+			Holiday:         expected.Holiday,
+			Absence:         expected.Absence,
+			Expected:        expected,
+			date:            date,
+		}
+		if expected.Working {
+			day.ExpectedEntry = expected.Entry.Format("15:04")
+			day.ExpectedExit = expected.Exit.Format("15:04")
+		}
+		index[day.Date] = len(days)
+		days = append(days, day)
+	}
+
+	var open *models.TimeRecord
+	var openDay string
+	// Última saída anterior ao período, para conferir o descanso entre
+	// jornadas do primeiro dia
+	var previousEnd time.Time
+	firstDay := from.Format(DateLayout)
+	for i := range records {
+		record := records[i]
+		punchType, _ := models.ParsePunchType(string(record.Type))
+		dayKey := record.Timestamp.In(loc).Format(DateLayout)
+
+		if punchType == models.PunchSaida && open != nil && record.Timestamp.Sub(open.Timestamp) <= punch.MaxOpenDuration {
+			// A saída fecha o intervalo aberto e pertence ao dia da entrada
+			dayKey = openDay
+			if pos, ok := index[dayKey]; ok {
+				days[pos].Intervals = append(days[pos].Intervals, Interval{Start: open.Timestamp, End: record.Timestamp})
+			} else if dayKey < firstDay {
+				previousEnd = record.Timestamp
+			}
+			open = nil
+		} else if punchType == models.PunchEntrada {
+			open = &records[i]
+			openDay = dayKey
+		}
+
+		if pos, ok := index[dayKey]; ok {
+			days[pos].Records = append(days[pos].Records, record)
+		}
+	}
+
+	for i := range days {
+		computeDay(&days[i], policy)
+		computeNight(&days[i], policy.Night, loc)
+	}
+	checkCompliance(days, previousEnd)
+	return days
+}
+
+// computeDay calcula as horas trabalhadas e o atraso do dia, com e sem a
+// tolerância de marcação. O tempo abonado conta como trabalhado.
+func computeDay(day *Day, policy models.LaborPolicy) {
+	day.WorkedMinutes, day.LateMinutes = measure(day.Intervals, day.Expected)
+	adjusted := applyTolerance(day.Intervals, day.Expected, policy.Tolerance)
+	day.AdjustedWorkedMinutes, day.AdjustedLateMinutes = measure(adjusted, day.Expected)
+	applyAbono(day)
+
+	day.BalanceMinutes = day.WorkedMinutes - day.ExpectedMinutes
+	day.AdjustedBalanceMinutes = day.AdjustedWorkedMinutes - day.ExpectedMinutes
+
+	day.RestDay = day.Holiday != "" || (!day.Expected.Working && day.date.Weekday() == time.Sunday)
+	computeOvertime(day, policy.Overtime)
+}
+
+// measure soma os minutos trabalhados nos intervalos e o atraso da primeira
+// entrada em relação à prevista.
+func measure(intervals []Interval, expected Expectation) (worked, late int) {
+	var total time.Duration
+	for _, interval := range intervals {
+		if interval.End.After(interval.Start) {
+			total += interval.End.Sub(interval.Start)
+		}
+	}
+
+	if expected.Working && len(intervals) > 0 {
+		if delay := intervals[0].Start.Sub(expected.Entry); delay > 0 {
+			late = int(delay / time.Minute)
+		}
+	}
+	return int(total / time.Minute), late
+}
+
+// missed informa se o dia, encerrado até until, tinha jornada prevista e
+// ficou sem marcações nem abono: uma falta injustificada. O dia corrente ainda
+// pode receber marcações.
+func (d *Day) missed(until time.Time) bool {
+	ended := !d.date.AddDate(0, 0, 1).After(until)
+	return ended && d.Expected.Working && len(d.Records) == 0 && d.AbonoMinutes == 0
+}
+
+// Totals resume um conjunto de dias de jornada.
+type Totals struct {
+	DaysWorked      int `json:"days_worked"`
+	LateDays        int `json:"late_days"`
+	WorkedMinutes   int `json:"worked_minutes"`
+	ExpectedMinutes int `json:"expected_minutes"`
+	BalanceMinutes  int `json:"balance_minutes"` // trabalhado menos previsto
+
+	AdjustedLateDays       int `json:"adjusted_late_days"`
+	AdjustedWorkedMinutes  int `json:"adjusted_worked_minutes"`
+	AdjustedBalanceMinutes int `json:"adjusted_balance_minutes"`
+
+	OvertimeMinutes       int           `json:"overtime_minutes"`
+	Overtime              []RateMinutes `json:"overtime"`
+	OvertimeExcessMinutes int           `json:"overtime_excess_minutes"`
+	OvertimeExcessDays    int           `json:"overtime_excess_days"`
+
+	NightMinutes     int `json:"night_minutes"`
+	NightPaidMinutes int `json:"night_paid_minutes"`
+
+	AbonoMinutes int `json:"abono_minutes"`
+	AbonoDays    int `json:"abono_days"`
+	MissedDays   int `json:"missed_days"` // dias previstos sem marcações nem abono
+}
+
+// Summarize totaliza os dias até until, inclusive. Dias posteriores ainda não
+// aconteceram e não entram na jornada prevista.
+func Summarize(days []Day, until time.Time) Totals {
+	totals := Totals{Overtime: []RateMinutes{}}
+	for i := range days {
+		day := &days[i]
+		if day.date.After(until) {
+			break
+		}
+
+		if len(day.Records) > 0 {
+			totals.DaysWorked++
+		}
+		if day.LateMinutes > 0 {
+			totals.LateDays++
+		}
+		if day.AdjustedLateMinutes > 0 {
+			totals.AdjustedLateDays++
+		}
+		totals.WorkedMinutes += day.WorkedMinutes
+		totals.AdjustedWorkedMinutes += day.AdjustedWorkedMinutes
+		totals.ExpectedMinutes += day.ExpectedMinutes
+
+		totals.OvertimeMinutes += day.OvertimeMinutes
+		totals.Overtime = mergeRates(totals.Overtime, day.Overtime)
+		totals.OvertimeExcessMinutes += day.OvertimeExcessMinutes
+		if day.OvertimeExcessMinutes > 0 {
+			totals.OvertimeExcessDays++
+		}
+
+		totals.NightMinutes += day.NightMinutes
+		totals.NightPaidMinutes += day.NightPaidMinutes
+
+		totals.AbonoMinutes += day.AbonoMinutes
+		if day.AbonoMinutes > 0 {
+			totals.AbonoDays++
+		}
+		if day.missed(until) {
+			totals.MissedDays++
+		}
+	}
+	totals.BalanceMinutes = totals.WorkedMinutes - totals.ExpectedMinutes
+	totals.AdjustedBalanceMinutes = totals.AdjustedWorkedMinutes - totals.ExpectedMinutes
+	return totals
+}
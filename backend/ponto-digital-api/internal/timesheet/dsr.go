@@ -0,0 +1,104 @@
+package timesheet
+
+import (
+	"context"
+	"time"
+
+	"ponto-digital-api/internal/models"
+)
+
+// Week é a apuração do descanso semanal remunerado (DSR) de uma semana, de
+// segunda-feira a domingo. Uma falta injustificada na semana faz perder a
+// remuneração dos repousos dela (Lei 605/1949, art. 6º), e as horas extras
+// refletem no repouso na proporção dos repousos para os dias de jornada
+// (TST, Súmula 172).
+type Week struct {
+	Start       string `json:"start"`  // segunda-feira
+	End         string `json:"end"`    // domingo
+	Closed      bool   `json:"closed"` // semana encerrada; nas demais a apuração é parcial
+	WorkingDays int    `json:"working_days"`
+	RestDays    int    `json:"rest_days"`   // domingos de folga e feriados
+	MissedDays  int    `json:"missed_days"` // dias previstos sem marcações nem abono
+	Entitled    bool   `json:"entitled"`    // repousos remunerados, sem faltas na semana
+
+	LostRestDays int `json:"lost_rest_days"`
+
+	OvertimeMinutes    int           `json:"overtime_minutes"`
+	Overtime           []RateMinutes `json:"overtime"`
+	DSROvertimeMinutes int           `json:"dsr_overtime_minutes"`
+	DSROvertime        []RateMinutes `json:"dsr_overtime"` // reflexo das horas extras no repouso, por adicional
+}
+
+// Weeks apura o DSR de cada semana dos dias informados, que devem começar em
+// uma segunda-feira. Como em Summarize, apenas os dias até until entram na
+// apuração.
+func Weeks(days []Day, until time.Time) []Week {
+	weeks := []Week{}
+	for start := 0; start < len(days); start += 7 {
+		end := min(start+7, len(days))
+		weeks = append(weeks, summarizeWeek(days[start:end], until))
+	}
+	return weeks
+}
+
+func summarizeWeek(days []Day, until time.Time) Week {
+	week := Week{
+		Start:       days[0].Date,
+		End:         days[len(days)-1].Date,
+		Closed:      !days[len(days)-1].date.AddDate(0, 0, 1).After(until),
+		Overtime:    []RateMinutes{},
+		DSROvertime: []RateMinutes{},
+	}
+
+	for i := range days {
+		day := &days[i]
+		if day.date.After(until) {
+			break
+		}
+		if day.Expected.Working {
+			week.WorkingDays++
+		}
+		if day.RestDay {
+			week.RestDays++
+		}
+		if day.missed(until) {
+			week.MissedDays++
+		}
+		week.OvertimeMinutes += day.OvertimeMinutes
+		week.Overtime = mergeRates(week.Overtime, day.Overtime)
+	}
+
+	week.Entitled = week.MissedDays == 0
+	if !week.Entitled {
+		// Sem o repouso remunerado não há reflexo das horas extras
+		week.LostRestDays = week.RestDays
+		return week
+	}
+	if week.WorkingDays == 0 {
+		return week
+	}
+	for _, rate := range week.Overtime {
+		minutes := rate.Minutes * week.RestDays / week.WorkingDays
+		if minutes > 0 {
+			week.DSROvertime = append(week.DSROvertime, RateMinutes{Rate: rate.Rate, Minutes: minutes})
+			week.DSROvertimeMinutes += minutes
+		}
+	}
+	return week
+}
+
+// MonthWeeks apura o DSR das semanas do usuário cujo domingo cai no mês
+// informado, até until. Assim cada semana pertence a um único mês, mesmo
+// quando começa no anterior.
+func (s *Service) MonthWeeks(ctx context.Context, user *models.User, year, month int, until time.Time) ([]Week, error) {
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, s.Location)
+	firstSunday := start.AddDate(0, 0, (7-int(start.Weekday()))%7)
+	last := start.AddDate(0, 1, -1)
+	lastSunday := last.AddDate(0, 0, -int(last.Weekday()))
+
+	days, err := s.Period(ctx, user, firstSunday.AddDate(0, 0, -6), lastSunday)
+	if err != nil {
+		return nil, err
+	}
+	return Weeks(days, until), nil
+}
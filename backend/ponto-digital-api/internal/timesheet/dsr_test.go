@@ -0,0 +1,132 @@
+package timesheet
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"ponto-digital-api/internal/models"
+)
+
+// testWeek monta uma semana de segunda a domingo, a partir de 10/03/2025,
+// com jornada de segunda a sexta cumprida, sábado sem jornada e domingo de
+// folga.
+func testWeek() []Day {
+	monday := time.Date(2025, 3, 10, 0, 0, 0, 0, testLocation)
+	days := make([]Day, 7)
+	for i := range days {
+		date := monday.AddDate(0, 0, i)
+		days[i] = Day{Date: date.Format(DateLayout), date: date, Overtime: []RateMinutes{}}
+		if i < 5 {
+			days[i].Expected = Expectation{Working: true, Minutes: 480}
+			days[i].Records = []models.TimeRecord{{}}
+		}
+	}
+	days[6].RestDay = true
+	return days
+}
+
+func TestWeeks(t *testing.T) {
+	closed := time.Date(2025, 3, 17, 0, 0, 0, 0, testLocation)
+
+	tests := []struct {
+		name   string
+		change func(days []Day)
+		until  time.Time
+		want   Week
+	}{
+		{
+			name:  "semana sem faltas",
+			until: closed,
+			want:  Week{Closed: true, WorkingDays: 5, RestDays: 1, Entitled: true},
+		},
+		{
+			name: "reflexo das horas extras",
+			change: func(days []Day) {
+				days[0].OvertimeMinutes = 120
+				days[0].Overtime = []RateMinutes{{Rate: 50, Minutes: 120}}
+				days[1].OvertimeMinutes = 30
+				days[1].Overtime = []RateMinutes{{Rate: 50, Minutes: 30}}
+			},
+			until: closed,
+			want: Week{
+				Closed: true, WorkingDays: 5, RestDays: 1, Entitled: true,
+				OvertimeMinutes: 150, Overtime: []RateMinutes{{Rate: 50, Minutes: 150}},
+				DSROvertimeMinutes: 30, DSROvertime: []RateMinutes{{Rate: 50, Minutes: 30}},
+			},
+		},
+		{
+			name: "falta injustificada",
+			change: func(days []Day) {
+				days[2].Records = []models.TimeRecord{}
+				days[0].OvertimeMinutes = 120
+				days[0].Overtime = []RateMinutes{{Rate: 50, Minutes: 120}}
+			},
+			until: closed,
+			want: Week{
+				Closed: true, WorkingDays: 5, RestDays: 1, MissedDays: 1, LostRestDays: 1,
+				OvertimeMinutes: 120, Overtime: []RateMinutes{{Rate: 50, Minutes: 120}},
+			},
+		},
+		{
+			name: "falta abonada",
+			change: func(days []Day) {
+				days[2].Records = []models.TimeRecord{}
+				days[2].AbonoMinutes = 480
+			},
+			until: closed,
+			want:  Week{Closed: true, WorkingDays: 5, RestDays: 1, Entitled: true},
+		},
+		{
+			name: "feriado na semana",
+			change: func(days []Day) {
+				days[2].Expected = Expectation{Holiday: "Feriado"}
+				days[2].Records = []models.TimeRecord{}
+				days[2].RestDay = true
+				days[0].OvertimeMinutes = 120
+				days[0].Overtime = []RateMinutes{{Rate: 50, Minutes: 120}}
+			},
+			until: closed,
+			want: Week{
+				Closed: true, WorkingDays: 4, RestDays: 2, Entitled: true,
+				OvertimeMinutes: 120, Overtime: []RateMinutes{{Rate: 50, Minutes: 120}},
+				DSROvertimeMinutes: 60, DSROvertime: []RateMinutes{{Rate: 50, Minutes: 60}},
+			},
+		},
+		{
+			name: "semana em andamento",
+			change: func(days []Day) {
+				days[3].Records = []models.TimeRecord{}
+				days[4].Records = []models.TimeRecord{}
+			},
+			until: time.Date(2025, 3, 13, 12, 0, 0, 0, testLocation),
+			want:  Week{WorkingDays: 4, Entitled: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days := testWeek()
+			if tt.change != nil {
+				tt.change(days)
+			}
+
+			weeks := Weeks(days, tt.until)
+
+			if len(weeks) != 1 {
+				t.Fatalf("Weeks() returned %d weeks, want 1", len(weeks))
+			}
+			want := tt.want
+			want.Start, want.End = "2025-03-10", "2025-03-16"
+			if want.Overtime == nil {
+				want.Overtime = []RateMinutes{}
+			}
+			if want.DSROvertime == nil {
+				want.DSROvertime = []RateMinutes{}
+			}
+			if !reflect.DeepEqual(weeks[0], want) {
+				t.Errorf("Weeks() = %+v\nwant %+v", weeks[0], want)
+			}
+		})
+	}
+}
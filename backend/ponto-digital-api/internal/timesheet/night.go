@@ -0,0 +1,118 @@
+package timesheet
+
+import (
+	"errors"
+	"time"
+
+	"ponto-digital-api/internal/models"
+)
+
+// reducedNightHour é a duração da hora noturna reduzida (CLT, art. 73, §1º).
+const reducedNightHour = 52*time.Minute + 30*time.Second
+
+// computeNight separa as horas trabalhadas no período noturno. O tempo
+// noturno é somado na hora do relógio e, com a hora reduzida, convertido em
+// horas noturnas a pagar.
+func computeNight(day *Day, policy models.NightPolicy, loc *time.Location) {
+	start, errStart := ParseClock(policy.Start)
+	end, errEnd := ParseClock(policy.End)
+	if errStart != nil || errEnd != nil || len(day.Intervals) == 0 {
+		return
+	}
+
+	var night time.Duration
+	var extendFrom time.Time
+	for _, interval := range day.Intervals {
+		night += nightOverlap(interval, start, end, loc)
+
+		if !policy.ExtendAfterEnd {
+			continue
+		}
+		if extendFrom.IsZero() {
+			// A prorrogação só vale para quem cumpriu todo o período noturno
+			windowEnd := crossedNightEnd(interval, start, end, loc)
+			if !windowEnd.IsZero() && !day.Intervals[0].Start.After(addMinutes(windowEnd, -span(start, end))) {
+				extendFrom = windowEnd
+			}
+		}
+		if !extendFrom.IsZero() {
+			// Prorrogação: do fim do período até o próximo início, que não é
+			// alcançado por nightOverlap
+			until := addMinutes(localDate(extendFrom, loc), start)
+			night += overlap(interval.Start, interval.End, extendFrom, until)
+		}
+	}
+
+	day.NightMinutes = int(night / time.Minute)
+	day.NightPaidMinutes = day.NightMinutes
+	if policy.ReducedHour {
+		day.NightPaidMinutes = int(float64(night) / float64(reducedNightHour) * 60)
+	}
+}
+
+// nightOverlap retorna quanto do intervalo cai nos períodos noturnos das
+// datas que ele alcança.
+func nightOverlap(interval Interval, start, end int, loc *time.Location) time.Duration {
+	var total time.Duration
+	first := localDate(interval.Start, loc).AddDate(0, 0, -1)
+	for date := first; date.Before(interval.End); date = date.AddDate(0, 0, 1) {
+		windowStart := addMinutes(date, start)
+		windowEnd := addMinutes(windowStart, span(start, end))
+		total += overlap(interval.Start, interval.End, windowStart, windowEnd)
+	}
+	return total
+}
+
+// crossedNightEnd retorna o fim de período noturno atravessado pelo
+// intervalo, em que o trabalho noturno continua após o horário final, ou o
+// instante zero se não houver.
+func crossedNightEnd(interval Interval, start, end int, loc *time.Location) time.Time {
+	first := localDate(interval.Start, loc).AddDate(0, 0, -1)
+	for date := first; date.Before(interval.End); date = date.AddDate(0, 0, 1) {
+		windowEnd := addMinutes(addMinutes(date, start), span(start, end))
+		if interval.Start.Before(windowEnd) && interval.End.After(windowEnd) {
+			return windowEnd
+		}
+	}
+	return time.Time{}
+}
+
+// overlap retorna a duração da interseção entre [aStart, aEnd) e
+// [bStart, bEnd).
+func overlap(aStart, aEnd, bStart, bEnd time.Time) time.Duration {
+	if bStart.After(aStart) {
+		aStart = bStart
+	}
+	if bEnd.Before(aEnd) {
+		aEnd = bEnd
+	}
+	if !aEnd.After(aStart) {
+		return 0
+	}
+	return aEnd.Sub(aStart)
+}
+
+// localDate retorna a data de t à meia-noite no fuso loc.
+func localDate(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// ValidateNight verifica os horários e o adicional do trabalho noturno.
+func ValidateNight(policy models.NightPolicy) error {
+	start, err := ParseClock(policy.Start)
+	if err != nil {
+		return err
+	}
+	end, err := ParseClock(policy.End)
+	if err != nil {
+		return err
+	}
+	if start == end {
+		return errors.New("O período noturno deve ter início e fim diferentes")
+	}
+	if policy.Rate < 0 || policy.Rate > 300 {
+		return errors.New("Adicional noturno inválido")
+	}
+	return nil
+}
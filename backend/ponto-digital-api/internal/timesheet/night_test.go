@@ -0,0 +1,54 @@
+package timesheet
+
+import (
+	"testing"
+
+	"ponto-digital-api/internal/models"
+)
+
+func TestComputeNight(t *testing.T) {
+	clt := models.DefaultLaborPolicy().Night
+	withoutExtension := clt
+	withoutExtension.ExtendAfterEnd = false
+	clockHour := clt
+	clockHour.ReducedHour = false
+
+	tests := []struct {
+		name      string
+		policy    models.NightPolicy
+		intervals []Interval
+		wantClock int
+		wantPaid  int
+	}{
+		{name: "jornada diurna", policy: clt, intervals: []Interval{{at(8, 0), at(17, 0)}}},
+		{name: "período noturno completo", policy: clt, intervals: []Interval{{at(22, 0), at(29, 0)}}, wantClock: 420, wantPaid: 480},
+		{name: "uma hora noturna", policy: clt, intervals: []Interval{{at(18, 0), at(23, 0)}}, wantClock: 60, wantPaid: 68},
+		{name: "prorrogação após as 5h", policy: clt, intervals: []Interval{{at(22, 0), at(30, 0)}}, wantClock: 480, wantPaid: 548},
+		{name: "sem prorrogação configurada", policy: withoutExtension, intervals: []Interval{{at(22, 0), at(30, 0)}}, wantClock: 420, wantPaid: 480},
+		{name: "sem cumprir todo o período", policy: clt, intervals: []Interval{{at(23, 0), at(30, 0)}}, wantClock: 360, wantPaid: 411},
+		{
+			name:      "prorrogação após intervalo",
+			policy:    clt,
+			intervals: []Interval{{at(22, 0), at(26, 0)}, {at(27, 0), at(31, 0)}},
+			wantClock: 480,
+			wantPaid:  548,
+		},
+		{name: "hora do relógio", policy: clockHour, intervals: []Interval{{at(22, 0), at(29, 0)}}, wantClock: 420, wantPaid: 420},
+		{name: "madrugada", policy: clt, intervals: []Interval{{at(3, 0), at(9, 0)}}, wantClock: 120, wantPaid: 137},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			day := Day{Intervals: tt.intervals}
+
+			computeNight(&day, tt.policy, testLocation)
+
+			if day.NightMinutes != tt.wantClock {
+				t.Errorf("NightMinutes = %d, want %d", day.NightMinutes, tt.wantClock)
+			}
+			if day.NightPaidMinutes != tt.wantPaid {
+				t.Errorf("NightPaidMinutes = %d, want %d", day.NightPaidMinutes, tt.wantPaid)
+			}
+		})
+	}
+}
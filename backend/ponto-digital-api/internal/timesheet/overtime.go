@@ -0,0 +1,98 @@
+package timesheet
+
+import (
+	"errors"
+	"sort"
+
+	"ponto-digital-api/internal/models"
+)
+
+// RateMinutes são os minutos de horas extras pagos com um mesmo adicional.
+type RateMinutes struct {
+	Rate    int `json:"rate"` // adicional percentual
+	Minutes int `json:"minutes"`
+}
+
+// computeOvertime distribui as horas extras do dia, já com a tolerância de
+// marcação, entre as faixas da política. Em domingos de folga e feriados todo
+// o tempo trabalhado é extra com o adicional de descanso.
+func computeOvertime(day *Day, policy models.OvertimePolicy) {
+	extra := day.AdjustedWorkedMinutes - day.ExpectedMinutes
+	if extra <= 0 {
+		return
+	}
+	day.OvertimeMinutes = extra
+
+	if policy.DailyLimitMinutes > 0 && extra > policy.DailyLimitMinutes {
+		day.OvertimeExcessMinutes = extra - policy.DailyLimitMinutes
+	}
+
+	if day.RestDay {
+		day.Overtime = mergeRates(day.Overtime, []RateMinutes{{Rate: policy.RestDayRate, Minutes: extra}})
+		return
+	}
+
+	previous := 0
+	for i, band := range policy.Bands {
+		minutes := extra - previous
+		last := i == len(policy.Bands)-1
+		if !last && band.UpToMinutes-previous < minutes {
+			minutes = band.UpToMinutes - previous
+		}
+		if minutes > 0 {
+			day.Overtime = mergeRates(day.Overtime, []RateMinutes{{Rate: band.Rate, Minutes: minutes}})
+		}
+		if last || extra <= band.UpToMinutes {
+			return
+		}
+		previous = band.UpToMinutes
+	}
+}
+
+// mergeRates soma os minutos de add em total, agrupados por adicional e
+// ordenados do menor para o maior.
+func mergeRates(total, add []RateMinutes) []RateMinutes {
+	for _, item := range add {
+		found := false
+		for i := range total {
+			if total[i].Rate == item.Rate {
+				total[i].Minutes += item.Minutes
+				found = true
+				break
+			}
+		}
+		if !found {
+			total = append(total, item)
+		}
+	}
+
+	sort.Slice(total, func(i, j int) bool {
+		return total[i].Rate < total[j].Rate
+	})
+	return total
+}
+
+// ValidateOvertime verifica se as faixas de horas extras estão em ordem
+// crescente e com adicionais positivos.
+func ValidateOvertime(policy models.OvertimePolicy) error {
+	if len(policy.Bands) == 0 {
+		return errors.New("Informe ao menos uma faixa de horas extras")
+	}
+	previous := 0
+	for i, band := range policy.Bands {
+		if band.Rate <= 0 || band.Rate > 300 {
+			return errors.New("Adicional de horas extras inválido")
+		}
+		if i < len(policy.Bands)-1 && band.UpToMinutes <= previous {
+			return errors.New("As faixas de horas extras devem ter limites crescentes")
+		}
+		previous = band.UpToMinutes
+	}
+	if policy.RestDayRate <= 0 || policy.RestDayRate > 300 {
+		return errors.New("Adicional de horas extras inválido")
+	}
+	if policy.DailyLimitMinutes < 0 {
+		return errors.New("Limite diário de horas extras inválido")
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package timesheet
+
+import (
+	"reflect"
+	"testing"
+
+	"ponto-digital-api/internal/models"
+)
+
+func TestComputeOvertime(t *testing.T) {
+	clt := models.DefaultLaborPolicy().Overtime
+	threeBands := models.OvertimePolicy{
+		Bands:       []models.OvertimeBand{{UpToMinutes: 60, Rate: 50}, {UpToMinutes: 120, Rate: 70}, {Rate: 100}},
+		RestDayRate: 100,
+	}
+
+	tests := []struct {
+		name       string
+		policy     models.OvertimePolicy
+		worked     int
+		expected   int
+		restDay    bool
+		want       []RateMinutes
+		wantExcess int
+	}{
+		{name: "sem horas extras", policy: clt, worked: 480, expected: 480, want: []RateMinutes{}},
+		{name: "saldo negativo", policy: clt, worked: 420, expected: 480, want: []RateMinutes{}},
+		{name: "primeira faixa", policy: clt, worked: 540, expected: 480, want: []RateMinutes{{Rate: 50, Minutes: 60}}},
+		{name: "limite da primeira faixa", policy: clt, worked: 600, expected: 480, want: []RateMinutes{{Rate: 50, Minutes: 120}}},
+		{
+			name: "além do limite diário", policy: clt, worked: 660, expected: 480,
+			want:       []RateMinutes{{Rate: 50, Minutes: 120}, {Rate: 100, Minutes: 60}},
+			wantExcess: 60,
+		},
+		{
+			name: "domingo de folga", policy: clt, worked: 240, expected: 0, restDay: true,
+			want:       []RateMinutes{{Rate: 100, Minutes: 240}},
+			wantExcess: 120,
+		},
+		{
+			name: "três faixas", policy: threeBands, worked: 630, expected: 480,
+			want: []RateMinutes{{Rate: 50, Minutes: 60}, {Rate: 70, Minutes: 60}, {Rate: 100, Minutes: 30}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			day := Day{
+				AdjustedWorkedMinutes: tt.worked,
+				ExpectedMinutes:       tt.expected,
+				RestDay:               tt.restDay,
+				Overtime:              []RateMinutes{},
+			}
+
+			computeOvertime(&day, tt.policy)
+
+			if !reflect.DeepEqual(day.Overtime, tt.want) {
+				t.Errorf("Overtime = %v, want %v", day.Overtime, tt.want)
+			}
+			if wantTotal := max(tt.worked-tt.expected, 0); day.OvertimeMinutes != wantTotal {
+				t.Errorf("OvertimeMinutes = %d, want %d", day.OvertimeMinutes, wantTotal)
+			}
+			if day.OvertimeExcessMinutes != tt.wantExcess {
+				t.Errorf("OvertimeExcessMinutes = %d, want %d", day.OvertimeExcessMinutes, tt.wantExcess)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+// Package timesheet calcula a jornada prevista e as horas trabalhadas de cada
+// dia a partir das marcações de ponto.
+package timesheet
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ponto-digital-api/internal/holiday"
+	"ponto-digital-api/internal/models"
+)
+
+// Expectation é a jornada prevista para uma data. Em dias de folga Working é
+// falso e os demais campos ficam zerados.
+type Expectation struct {
+	Working    bool
+	Entry      time.Time
+	Exit       time.Time
+	BreakStart time.Time // zero quando não há intervalo previsto
+	BreakEnd   time.Time
+	Minutes    int    // minutos previstos, descontado o intervalo
+	Holiday    string // nome do feriado, quando a data é feriado
+
+	// Ausência justificada da data. AbonoStart e AbonoEnd delimitam o abono
+	// parcial e ficam zerados quando o dia inteiro é abonado.
+	Absence    models.AbsenceKind
+	AbonoStart time.Time
+	AbonoEnd   time.Time
+}
+
+// Plan fornece a jornada prevista para cada data.
+type Plan interface {
+	// Expect recebe a data à meia-noite no fuso da empresa.
+	Expect(date time.Time) Expectation
+}
+
+var commercialHours = models.WorkHours{
+	Entry:        "09:00",
+	Exit:         "18:00",
+	BreakStart:   "12:00",
+	BreakEnd:     "13:00",
+	DailyMinutes: 480,
+}
+
+// DefaultSchedule é usada para funcionários sem jornada atribuída: segunda a
+// sexta, das 9h às 18h, com uma hora de intervalo.
+var DefaultSchedule = models.WorkSchedule{
+	Name: "Padrão",
+	Days: []models.ScheduleDay{
+		{Weekday: time.Monday, WorkHours: commercialHours},
+		{Weekday: time.Tuesday, WorkHours: commercialHours},
+		{Weekday: time.Wednesday, WorkHours: commercialHours},
+		{Weekday: time.Thursday, WorkHours: commercialHours},
+		{Weekday: time.Friday, WorkHours: commercialHours},
+	},
+}
+
+// WeeklyPlan é o Plan de uma jornada semanal.
+type WeeklyPlan struct {
+	Schedule *models.WorkSchedule
+}
+
+func (p WeeklyPlan) Expect(date time.Time) Expectation {
+	day, ok := p.Schedule.Day(date.Weekday())
+	if !ok {
+		return Expectation{}
+	}
+	return expectHours(date, day.WorkHours)
+}
+
+// HolidayPlan é o Plan que suspende a jornada de Base nos feriados do
+// calendário.
+type HolidayPlan struct {
+	Base     Plan
+	Calendar holiday.Calendar
+}
+
+func (p HolidayPlan) Expect(date time.Time) Expectation {
+	if found, ok := p.Calendar.Lookup(date); ok {
+		return Expectation{Holiday: found.Name}
+	}
+	return p.Base.Expect(date)
+}
+
+// CyclePlan é o Plan de uma escala de revezamento. Anchor é a data, à
+// meia-noite, em que começa o primeiro dia do ciclo.
+type CyclePlan struct {
+	Pattern *models.ShiftPattern
+	Anchor  time.Time
+}
+
+func (p CyclePlan) Expect(date time.Time) Expectation {
+	cycle := p.Pattern.Cycle
+	if len(cycle) == 0 {
+		return Expectation{}
+	}
+
+	// Posição da data no ciclo, também para datas anteriores à âncora
+	position := daysBetween(p.Anchor, date) % len(cycle)
+	if position < 0 {
+		position += len(cycle)
+	}
+
+	day := cycle[position]
+	if !day.Working {
+		return Expectation{}
+	}
+	return expectHours(date, day.WorkHours)
+}
+
+// daysBetween conta os dias de calendário entre duas datas.
+func daysBetween(from, to time.Time) int {
+	a := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+// expectHours posiciona os horários previstos na data informada.
+func expectHours(date time.Time, hours models.WorkHours) Expectation {
+	entry, _ := ParseClock(hours.Entry)
+	exit, _ := ParseClock(hours.Exit)
+	expectation := Expectation{
+		Working: true,
+		Entry:   addMinutes(date, entry),
+		Minutes: hours.DailyMinutes,
+	}
+	expectation.Exit = addMinutes(expectation.Entry, span(entry, exit))
+
+	if hours.BreakStart != "" && hours.BreakEnd != "" {
+		breakStart, _ := ParseClock(hours.BreakStart)
+		breakEnd, _ := ParseClock(hours.BreakEnd)
+		expectation.BreakStart = addMinutes(expectation.Entry, span(entry, breakStart))
+		expectation.BreakEnd = addMinutes(expectation.BreakStart, span(breakStart, breakEnd))
+	}
+	return expectation
+}
+
+func addMinutes(t time.Time, minutes int) time.Time {
+	return t.Add(time.Duration(minutes) * time.Minute)
+}
+
+// ParseClock converte um horário "HH:MM" em minutos desde a meia-noite.
+func ParseClock(value string) (int, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 || len(parts[0]) != 2 || len(parts[1]) != 2 {
+		return 0, fmt.Errorf("Horário inválido: %q", value)
+	}
+
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil || hours < 0 || hours > 23 {
+		return 0, fmt.Errorf("Horário inválido: %q", value)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil || minutes < 0 || minutes > 59 {
+		return 0, fmt.Errorf("Horário inválido: %q", value)
+	}
+	return hours*60 + minutes, nil
+}
+
+// NormalizeSchedule valida os horários da jornada e calcula o total de
+// minutos previstos de cada dia.
+func NormalizeSchedule(schedule *models.WorkSchedule) error {
+	seen := make(map[time.Weekday]bool)
+	for i := range schedule.Days {
+		day := &schedule.Days[i]
+		if day.Weekday < time.Sunday || day.Weekday > time.Saturday {
+			return errors.New("Dia da semana inválido")
+		}
+		if seen[day.Weekday] {
+			return errors.New("Dia da semana repetido na jornada")
+		}
+		seen[day.Weekday] = true
+
+		if err := normalizeHours(&day.WorkHours); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NormalizeShift valida a escala e monta o ciclo. Para as escalas 12x36, 6x1
+// e 5x2 o ciclo é gerado a partir de hours, os horários de cada dia de
+// trabalho; na escala personalizada o ciclo informado é validado.
+func NormalizeShift(pattern *models.ShiftPattern, hours models.WorkHours) error {
+	switch pattern.Kind {
+	case models.Shift12x36:
+		pattern.Cycle = buildCycle(hours, 1, 1)
+	case models.Shift6x1:
+		pattern.Cycle = buildCycle(hours, 6, 1)
+	case models.Shift5x2:
+		pattern.Cycle = buildCycle(hours, 5, 2)
+	case models.ShiftCustom:
+		if len(pattern.Cycle) == 0 {
+			return errors.New("Informe os dias do ciclo da escala")
+		}
+	default:
+		return errors.New("Tipo de escala inválido")
+	}
+
+	if pattern.AnchorDate.IsZero() {
+		return errors.New("Informe a data de início da escala")
+	}
+
+	working := false
+	for i := range pattern.Cycle {
+		day := &pattern.Cycle[i]
+		if !day.Working {
+			day.WorkHours = models.WorkHours{}
+			continue
+		}
+		working = true
+		if err := normalizeHours(&day.WorkHours); err != nil {
+			return err
+		}
+	}
+	if !working {
+		return errors.New("A escala precisa ter ao menos um dia de trabalho")
+	}
+	return nil
+}
+
+// buildCycle monta um ciclo com workDays dias de trabalho seguidos de offDays
+// dias de folga.
+func buildCycle(hours models.WorkHours, workDays, offDays int) []models.ShiftDay {
+	cycle := make([]models.ShiftDay, 0, workDays+offDays)
+	for i := 0; i < workDays; i++ {
+		cycle = append(cycle, models.ShiftDay{Working: true, WorkHours: hours})
+	}
+	for i := 0; i < offDays; i++ {
+		cycle = append(cycle, models.ShiftDay{})
+	}
+	return cycle
+}
+
+// normalizeHours valida os horários e calcula os minutos previstos do dia.
+func normalizeHours(hours *models.WorkHours) error {
+	minutes, err := dayMinutes(*hours)
+	if err != nil {
+		return err
+	}
+	hours.DailyMinutes = minutes
+	return nil
+}
+
+// dayMinutes calcula os minutos previstos do dia, descontado o intervalo.
+func dayMinutes(day models.WorkHours) (int, error) {
+	entry, err := ParseClock(day.Entry)
+	if err != nil {
+		return 0, err
+	}
+	exit, err := ParseClock(day.Exit)
+	if err != nil {
+		return 0, err
+	}
+	if exit == entry {
+		return 0, errors.New("Entrada e saída não podem ter o mesmo horário")
+	}
+	total := span(entry, exit)
+
+	if day.BreakStart == "" && day.BreakEnd == "" {
+		return total, nil
+	}
+	if day.BreakStart == "" || day.BreakEnd == "" {
+		return 0, errors.New("Informe o início e o fim do intervalo")
+	}
+
+	breakStart, err := ParseClock(day.BreakStart)
+	if err != nil {
+		return 0, err
+	}
+	breakEnd, err := ParseClock(day.BreakEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	// O intervalo precisa estar contido na jornada
+	startOffset := span(entry, breakStart)
+	breakMinutes := span(breakStart, breakEnd)
+	if breakMinutes == 0 || startOffset == 0 || startOffset+breakMinutes >= total {
+		return 0, errors.New("Intervalo fora do horário da jornada")
+	}
+	return total - breakMinutes, nil
+}
+
+// span retorna os minutos entre dois horários, considerando a virada do dia
+// quando to é anterior a from.
+func span(from, to int) int {
+	if to < from {
+		to += 24 * 60
+	}
+	return to - from
+}
@@ -0,0 +1,226 @@
+package timesheet
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/holiday"
+	"ponto-digital-api/internal/models"
+	"ponto-digital-api/internal/punch"
+	"ponto-digital-api/internal/repository"
+)
+
+// Service monta os dias de jornada dos funcionários a partir dos dados
+// persistidos.
+type Service struct {
+	store    *repository.Store
+	holidays *holiday.Service
+	Location *time.Location
+}
+
+// NewService cria um Service que usa o fuso de São Paulo para separar os dias.
+func NewService(store *repository.Store) *Service {
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		loc = time.FixedZone("BRT", -3*60*60)
+	}
+	return &Service{store: store, holidays: holiday.NewService(store, loc), Location: loc}
+}
+
+// PlanFor retorna a jornada prevista do usuário: a escala de revezamento
+// atribuída a ele, a jornada semanal atribuída a ele, a do seu grupo ou, na
+// falta de todas, DefaultSchedule.
+func (s *Service) PlanFor(ctx context.Context, user *models.User) (Plan, error) {
+	if !user.ShiftID.IsZero() {
+		shift, err := s.store.Shifts.FindByID(ctx, user.CompanyID, user.ShiftID)
+		if err == nil {
+			// A data de início própria do usuário posiciona-o no ciclo
+			anchor := shift.AnchorDate
+			if !user.ShiftAnchor.IsZero() {
+				anchor = user.ShiftAnchor
+			}
+			return CyclePlan{Pattern: shift, Anchor: s.Date(anchor)}, nil
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	schedule, err := s.ScheduleFor(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return WeeklyPlan{Schedule: schedule}, nil
+}
+
+// PlanBetween retorna a jornada prevista do usuário com os feriados entre
+// from e to, inclusive, como dias sem expediente, e as ausências aprovadas.
+func (s *Service) PlanBetween(ctx context.Context, user *models.User, from, to time.Time) (Plan, error) {
+	policy, err := s.PolicyFor(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return s.planBetween(ctx, user, policy, from, to)
+}
+
+func (s *Service) planBetween(ctx context.Context, user *models.User, policy models.LaborPolicy, from, to time.Time) (Plan, error) {
+	plan, err := s.PlanFor(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	calendar, err := s.Holidays(ctx, user, policy, from, to)
+	if err != nil {
+		return nil, err
+	}
+	absences, err := s.Absences(ctx, user, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return AbsencePlan{Base: HolidayPlan{Base: plan, Calendar: calendar}, Absences: absences}, nil
+}
+
+// Absences retorna as ausências aprovadas do usuário com algum dia entre from
+// e to, inclusive.
+func (s *Service) Absences(ctx context.Context, user *models.User, from, to time.Time) ([]models.Absence, error) {
+	absences, err := s.store.Absences.List(ctx, repository.AbsenceFilter{
+		CompanyID: user.CompanyID,
+		UserIDs:   []primitive.ObjectID{user.ID},
+		Statuses:  []models.AbsenceStatus{models.AbsenceApproved},
+		From:      s.Date(from),
+		To:        s.Date(to),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// As datas são comparadas à meia-noite no fuso do serviço
+	for i := range absences {
+		absences[i].StartDate = s.Date(absences[i].StartDate)
+		absences[i].EndDate = s.Date(absences[i].EndDate)
+	}
+	return absences, nil
+}
+
+// Holidays retorna os feriados do usuário entre from e to, inclusive.
+func (s *Service) Holidays(ctx context.Context, user *models.User, policy models.LaborPolicy, from, to time.Time) (holiday.Calendar, error) {
+	return s.holidays.For(ctx, user, policy.Holidays, from, to)
+}
+
+// ScheduleFor retorna a jornada semanal efetiva do usuário.
+func (s *Service) ScheduleFor(ctx context.Context, user *models.User) (*models.WorkSchedule, error) {
+	scheduleID := user.ScheduleID
+	if scheduleID.IsZero() && !user.GroupID.IsZero() {
+		group, err := s.store.Groups.FindByID(ctx, user.CompanyID, user.GroupID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		if group != nil {
+			scheduleID = group.ScheduleID
+		}
+	}
+
+	if !scheduleID.IsZero() {
+		schedule, err := s.store.Schedules.FindByID(ctx, user.CompanyID, scheduleID)
+		if err == nil {
+			return schedule, nil
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	schedule := DefaultSchedule
+	return &schedule, nil
+}
+
+// PolicyFor retorna as regras trabalhistas do usuário: as da convenção
+// coletiva do seu estabelecimento ou, na falta dela, as da empresa. Usuários
+// sem empresa seguem DefaultLaborPolicy.
+func (s *Service) PolicyFor(ctx context.Context, user *models.User) (models.LaborPolicy, error) {
+	if user.CompanyID.IsZero() {
+		return models.DefaultLaborPolicy(), nil
+	}
+
+	if !user.BranchID.IsZero() {
+		agreement, err := s.agreementFor(ctx, user)
+		if err != nil {
+			return models.LaborPolicy{}, err
+		}
+		if agreement != nil {
+			return agreement.EffectivePolicy(), nil
+		}
+	}
+
+	company, err := s.store.Companies.FindByID(ctx, user.CompanyID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.DefaultLaborPolicy(), nil
+	}
+	if err != nil {
+		return models.LaborPolicy{}, err
+	}
+	return company.EffectivePolicy(), nil
+}
+
+// agreementFor retorna a convenção coletiva do estabelecimento do usuário ou
+// nil se não houver.
+func (s *Service) agreementFor(ctx context.Context, user *models.User) (*models.CollectiveAgreement, error) {
+	branch, err := s.store.Branches.FindByID(ctx, user.CompanyID, user.BranchID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if branch.AgreementID.IsZero() {
+		return nil, nil
+	}
+
+	agreement, err := s.store.Agreements.FindByID(ctx, user.CompanyID, branch.AgreementID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	return agreement, err
+}
+
+// Period monta os dias de jornada do usuário entre as datas from e to,
+// inclusive.
+func (s *Service) Period(ctx context.Context, user *models.User, from, to time.Time) ([]Day, error) {
+	policy, err := s.PolicyFor(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.planBetween(ctx, user, policy, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	start := s.Date(from)
+	end := s.Date(to).AddDate(0, 0, 1)
+
+	// Marcações próximas aos limites podem completar intervalos do período
+	records, err := s.store.TimeRecords.FindByUser(ctx, user.CompanyID, user.ID,
+		start.Add(-punch.MaxOpenDuration), end.Add(punch.MaxOpenDuration))
+	if err != nil {
+		return nil, err
+	}
+
+	// Ajustes aprovados valem no cálculo; as marcações originais permanecem
+	// gravadas
+	records = punch.Effective(records)
+
+	return Build(records, plan, policy, s.Location, start, end), nil
+}
+
+// Month monta todos os dias de jornada do mês informado.
+func (s *Service) Month(ctx context.Context, user *models.User, year, month int) ([]Day, error) {
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, s.Location)
+	return s.Period(ctx, user, start, start.AddDate(0, 1, -1))
+}
+
+// Date retorna a data de t à meia-noite no fuso do serviço.
+func (s *Service) Date(t time.Time) time.Time {
+	t = t.In(s.Location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.Location)
+}
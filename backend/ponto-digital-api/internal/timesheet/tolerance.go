@@ -0,0 +1,58 @@
+package timesheet
+
+import (
+	"time"
+
+	"ponto-digital-api/internal/models"
+)
+
+// applyTolerance retorna uma cópia dos intervalos em que as marcações próximas
+// dos horários previstos são movidas para esses horários (CLT, art. 58, §1º).
+//
+// Cada marcação com variação de até PerPunchMinutes é tolerada. Se a soma das
+// variações toleradas passar de PerDayMinutes, nenhuma é desconsiderada e o
+// dia é calculado pelas marcações reais (TST, Súmula 366).
+func applyTolerance(intervals []Interval, expected Expectation, tolerance models.TolerancePolicy) []Interval {
+	adjusted := append([]Interval(nil), intervals...)
+	if !expected.Working || len(adjusted) == 0 {
+		return adjusted
+	}
+
+	// Marcações comparáveis com a jornada prevista
+	type mark struct {
+		punch    *time.Time
+		expected time.Time
+	}
+	marks := []mark{
+		{&adjusted[0].Start, expected.Entry},
+		{&adjusted[len(adjusted)-1].End, expected.Exit},
+	}
+	if !expected.BreakStart.IsZero() && len(adjusted) > 1 {
+		marks = append(marks,
+			mark{&adjusted[0].End, expected.BreakStart},
+			mark{&adjusted[1].Start, expected.BreakEnd},
+		)
+	}
+
+	perPunch := time.Duration(tolerance.PerPunchMinutes) * time.Minute
+	var tolerated []mark
+	var total time.Duration
+	for _, m := range marks {
+		deviation := m.punch.Sub(m.expected)
+		if deviation < 0 {
+			deviation = -deviation
+		}
+		if deviation <= perPunch {
+			tolerated = append(tolerated, m)
+			total += deviation
+		}
+	}
+
+	if total > time.Duration(tolerance.PerDayMinutes)*time.Minute {
+		return adjusted
+	}
+	for _, m := range tolerated {
+		*m.punch = m.expected
+	}
+	return adjusted
+}
@@ -0,0 +1,90 @@
+package timesheet
+
+import (
+	"testing"
+	"time"
+
+	"ponto-digital-api/internal/models"
+)
+
+var testLocation = time.FixedZone("BRT", -3*60*60)
+
+// at retorna o horário hh:mm de 10/03/2025, uma segunda-feira, no fuso de
+// teste; horas a partir de 24 caem no dia seguinte.
+func at(hour, minute int) time.Time {
+	return time.Date(2025, 3, 10, hour, minute, 0, 0, testLocation)
+}
+
+func TestApplyTolerance(t *testing.T) {
+	expected := Expectation{
+		Working:    true,
+		Entry:      at(8, 0),
+		BreakStart: at(12, 0),
+		BreakEnd:   at(13, 0),
+		Exit:       at(17, 0),
+		Minutes:    480,
+	}
+	tolerance := models.TolerancePolicy{PerPunchMinutes: 5, PerDayMinutes: 10}
+
+	tests := []struct {
+		name      string
+		expected  Expectation
+		intervals []Interval
+		want      []Interval
+	}{
+		{
+			name:      "variações dentro da tolerância",
+			expected:  expected,
+			intervals: []Interval{{at(8, 4), at(12, 0)}, {at(13, 0), at(17, 3)}},
+			want:      []Interval{{at(8, 0), at(12, 0)}, {at(13, 0), at(17, 0)}},
+		},
+		{
+			name:      "marcação além da tolerância por marcação",
+			expected:  expected,
+			intervals: []Interval{{at(8, 6), at(12, 2)}, {at(13, 0), at(17, 0)}},
+			want:      []Interval{{at(8, 6), at(12, 0)}, {at(13, 0), at(17, 0)}},
+		},
+		{
+			name:      "soma das variações além do limite diário",
+			expected:  expected,
+			intervals: []Interval{{at(8, 5), at(12, 5)}, {at(13, 5), at(17, 0)}},
+			want:      []Interval{{at(8, 5), at(12, 5)}, {at(13, 5), at(17, 0)}},
+		},
+		{
+			name:      "jornada sem intervalo previsto",
+			expected:  Expectation{Working: true, Entry: at(8, 0), Exit: at(14, 0), Minutes: 360},
+			intervals: []Interval{{at(7, 57), at(14, 4)}},
+			want:      []Interval{{at(8, 0), at(14, 0)}},
+		},
+		{
+			name:      "dia sem jornada prevista",
+			expected:  Expectation{},
+			intervals: []Interval{{at(8, 4), at(12, 0)}},
+			want:      []Interval{{at(8, 4), at(12, 0)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := append([]Interval(nil), tt.intervals...)
+
+			got := applyTolerance(tt.intervals, tt.expected, tolerance)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("applyTolerance() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Start.Equal(tt.want[i].Start) || !got[i].End.Equal(tt.want[i].End) {
+					t.Errorf("interval %d = %v-%v, want %v-%v", i,
+						got[i].Start.Format("15:04"), got[i].End.Format("15:04"),
+						tt.want[i].Start.Format("15:04"), tt.want[i].End.Format("15:04"))
+				}
+			}
+			for i := range original {
+				if original[i] != tt.intervals[i] {
+					t.Errorf("applyTolerance() changed the original intervals")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package utils
+
+import "strings"
+
+// NormalizeCNPJ remove pontuação do CNPJ, mantendo apenas os dígitos
+func NormalizeCNPJ(cnpj string) string {
+	var b strings.Builder
+	for _, r := range cnpj {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// ValidCNPJ verifica o tamanho e os dígitos verificadores do CNPJ
+func ValidCNPJ(cnpj string) bool {
+	digits := NormalizeCNPJ(cnpj)
+	if len(digits) != 14 {
+		return false
+	}
+
+	// CNPJs com todos os dígitos iguais passam no cálculo, mas são inválidos
+	if strings.Count(digits, digits[:1]) == 14 {
+		return false
+	}
+
+	weights := []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
+	for _, n := range []int{12, 13} {
+		sum := 0
+		for i := 0; i < n; i++ {
+			sum += int(digits[i]-'0') * weights[len(weights)-n+i]
+		}
+		check := sum % 11
+		if check < 2 {
+			check = 0
+		} else {
+			check = 11 - check
+		}
+		if int(digits[n]-'0') != check {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatCNPJ pontua um CNPJ de 14 dígitos (00.000.000/0000-00); outros
+// valores são retornados sem alteração
+func FormatCNPJ(cnpj string) string {
+	if len(cnpj) != 14 {
+		return cnpj
+	}
+	return cnpj[:2] + "." + cnpj[2:5] + "." + cnpj[5:8] + "/" + cnpj[8:12] + "-" + cnpj[12:]
+}
@@ -0,0 +1,45 @@
+package utils
+
+import "strings"
+
+// NormalizeCPF remove pontuação do CPF, mantendo apenas os dígitos
+func NormalizeCPF(cpf string) string {
+	return NormalizeCNPJ(cpf)
+}
+
+// ValidCPF verifica o tamanho e os dígitos verificadores do CPF
+func ValidCPF(cpf string) bool {
+	digits := NormalizeCPF(cpf)
+	if len(digits) != 11 {
+		return false
+	}
+
+	// CPFs com todos os dígitos iguais passam no cálculo, mas são inválidos
+	if strings.Count(digits, digits[:1]) == 11 {
+		return false
+	}
+
+	for _, n := range []int{9, 10} {
+		sum := 0
+		for i := 0; i < n; i++ {
+			sum += int(digits[i]-'0') * (n + 1 - i)
+		}
+		check := sum * 10 % 11
+		if check == 10 {
+			check = 0
+		}
+		if int(digits[n]-'0') != check {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatCPF pontua um CPF de 11 dígitos (000.000.000-00); outros valores são
+// retornados sem alteração
+func FormatCPF(cpf string) string {
+	if len(cpf) != 11 {
+		return cpf
+	}
+	return cpf[:3] + "." + cpf[3:6] + "." + cpf[6:9] + "-" + cpf[9:]
+}
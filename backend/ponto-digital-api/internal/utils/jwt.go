@@ -4,6 +4,7 @@ import (
 	"time"
 	"github.com/golang-jwt/jwt/v5"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"ponto-digital-api/internal/models"
 )
 
 var jwtSecret = []byte("2732f6b343f9904cb6ca54211d7234ad8a59905206a970d67d946d38ef0197854b18962b232e331b112a3de367539d6f74356ffbed653e6ec1bf80ffedec902c94f2df4382cd8df6fe12b0023296ce38ad884afee1a6bcdc895456bf05471817457253bb39433334658bde294cb236ed8ae935a4d3679c08b5e280211d923ea2") // Nova chave
@@ -11,15 +12,26 @@ var jwtSecret = []byte("2732f6b343f9904cb6ca54211d7234ad8a59905206a970d67d946d38
 type Claims struct {
 	UserID primitive.ObjectID `json:"user_id"`
 	Email  string            `json:"email"`
+	Role      models.Role        `json:"role,omitempty"`
+	CompanyID primitive.ObjectID `json:"company_id,omitempty"`
+	SessionID primitive.ObjectID `json:"sid"` // sessão de login; o token deixa de valer quando ela é encerrada
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID primitive.ObjectID, email string) (string, error) {
+// AccessTokenTTL é a validade dos tokens de acesso; depois dela o cliente
+// obtém um novo token com o refresh token da sessão
+const AccessTokenTTL = 15 * time.Minute
+
+// GenerateToken emite o token de acesso do usuário na sessão sessionID
+func GenerateToken(user *models.User, sessionID primitive.ObjectID) (string, error) {
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      user.EffectiveRole(),
+		CompanyID: user.CompanyID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
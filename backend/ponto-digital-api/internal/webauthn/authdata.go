@@ -0,0 +1,64 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Bits do campo flags dos dados do autenticador.
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttested     = 0x40
+)
+
+// authenticatorData são os dados assinados pelo autenticador (WebAuthn,
+// §6.1): o hash do RP ID, as flags, o contador de assinaturas e, no
+// cadastro, a credencial gerada.
+type authenticatorData struct {
+	RPIDHash  []byte
+	Flags     byte
+	SignCount uint32
+
+	// Presentes apenas quando a flag AT está ligada
+	AAGUID       []byte
+	CredentialID []byte
+	PublicKey    []byte // chave COSE, codificada em CBOR
+}
+
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, errors.New("Dados do autenticador incompletos")
+	}
+
+	parsed := &authenticatorData{
+		RPIDHash:  data[:32],
+		Flags:     data[32],
+		SignCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	if parsed.Flags&flagAttested == 0 {
+		return parsed, nil
+	}
+
+	rest := data[37:]
+	if len(rest) < 18 {
+		return nil, errors.New("Credencial incompleta nos dados do autenticador")
+	}
+	parsed.AAGUID = rest[:16]
+	size := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if len(rest) < size {
+		return nil, errors.New("Credencial incompleta nos dados do autenticador")
+	}
+	parsed.CredentialID = rest[:size]
+	rest = rest[size:]
+
+	// A chave pública é o primeiro item CBOR após o identificador; o que
+	// sobra são extensões, que não são usadas
+	_, remaining, err := decodeCBOR(rest)
+	if err != nil {
+		return nil, errors.New("Chave pública da credencial inválida")
+	}
+	parsed.PublicKey = rest[:len(rest)-len(remaining)]
+	return parsed, nil
+}
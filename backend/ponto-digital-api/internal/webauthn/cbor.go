@@ -0,0 +1,147 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// maxCBORDepth limita o aninhamento de estruturas aceito pelo decodificador.
+const maxCBORDepth = 16
+
+var errCBOR = errors.New("CBOR inválido")
+
+// decodeCBOR decodifica o primeiro item CBOR (RFC 8949) de data e retorna o
+// restante dos bytes. Cobre o subconjunto usado pelo WebAuthn: inteiros,
+// cadeias de bytes e de texto, listas, mapas, booleanos e nulo. Inteiros são
+// retornados como int64, mapas como map[interface{}]interface{} e etiquetas
+// (tags) são ignoradas.
+func decodeCBOR(data []byte) (interface{}, []byte, error) {
+	return decodeItem(data, 0)
+}
+
+func decodeItem(data []byte, depth int) (interface{}, []byte, error) {
+	if depth > maxCBORDepth || len(data) == 0 {
+		return nil, nil, errCBOR
+	}
+
+	major := data[0] >> 5
+	info := data[0] & 0x1f
+	data = data[1:]
+
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, data, nil
+		case 21:
+			return true, data, nil
+		case 22, 23:
+			return nil, data, nil
+		case 25, 26, 27:
+			// Números de ponto flutuante não aparecem nas estruturas do
+			// WebAuthn; são pulados
+			size := 1 << (info - 24)
+			if len(data) < size {
+				return nil, nil, errCBOR
+			}
+			return nil, data[size:], nil
+		}
+		return nil, nil, errCBOR
+	}
+
+	argument, data, err := decodeArgument(info, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch major {
+	case 0:
+		if argument > math.MaxInt64 {
+			return nil, nil, errCBOR
+		}
+		return int64(argument), data, nil
+
+	case 1:
+		if argument > math.MaxInt64 {
+			return nil, nil, errCBOR
+		}
+		return -1 - int64(argument), data, nil
+
+	case 2, 3:
+		if argument > uint64(len(data)) {
+			return nil, nil, errCBOR
+		}
+		value := data[:argument]
+		if major == 3 {
+			return string(value), data[argument:], nil
+		}
+		return append([]byte(nil), value...), data[argument:], nil
+
+	case 4:
+		if argument > uint64(len(data)) {
+			return nil, nil, errCBOR
+		}
+		items := make([]interface{}, 0, argument)
+		for i := uint64(0); i < argument; i++ {
+			var item interface{}
+			if item, data, err = decodeItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+			items = append(items, item)
+		}
+		return items, data, nil
+
+	case 5:
+		if argument > uint64(len(data)) {
+			return nil, nil, errCBOR
+		}
+		entries := make(map[interface{}]interface{}, argument)
+		for i := uint64(0); i < argument; i++ {
+			var key, value interface{}
+			if key, data, err = decodeItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, nil, errCBOR
+			}
+			if value, data, err = decodeItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+			entries[key] = value
+		}
+		return entries, data, nil
+
+	case 6:
+		return decodeItem(data, depth+1)
+	}
+	return nil, nil, errCBOR
+}
+
+// decodeArgument lê o argumento do cabeçalho de um item. Itens de tamanho
+// indefinido não são aceitos.
+func decodeArgument(info byte, data []byte) (uint64, []byte, error) {
+	switch {
+	case info < 24:
+		return uint64(info), data, nil
+	case info <= 27:
+		size := 1 << (info - 24)
+		if len(data) < size {
+			return 0, nil, errCBOR
+		}
+		var value uint64
+		switch size {
+		case 1:
+			value = uint64(data[0])
+		case 2:
+			value = uint64(binary.BigEndian.Uint16(data))
+		case 4:
+			value = uint64(binary.BigEndian.Uint32(data))
+		case 8:
+			value = binary.BigEndian.Uint64(data)
+		}
+		return value, data[size:], nil
+	}
+	return 0, nil, errCBOR
+}
@@ -0,0 +1,84 @@
+package webauthn
+
+import (
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeCBOR(t *testing.T) {
+	// Exemplos do apêndice A da RFC 8949
+	tests := []struct {
+		name     string
+		data     string
+		want     interface{}
+		wantRest string
+	}{
+		{name: "zero", data: "00", want: int64(0)},
+		{name: "inteiro curto", data: "17", want: int64(23)},
+		{name: "inteiro de 1 byte", data: "1818", want: int64(24)},
+		{name: "inteiro de 2 bytes", data: "1903e8", want: int64(1000)},
+		{name: "inteiro de 8 bytes", data: "1b000000e8d4a51000", want: int64(1000000000000)},
+		{name: "negativo", data: "20", want: int64(-1)},
+		{name: "negativo de 2 bytes", data: "3903e7", want: int64(-1000)},
+		{name: "bytes", data: "4401020304", want: []byte{1, 2, 3, 4}},
+		{name: "texto", data: "62c3bc", want: "ü"},
+		{name: "lista", data: "8301820203820405", want: []interface{}{int64(1), []interface{}{int64(2), int64(3)}, []interface{}{int64(4), int64(5)}}},
+		{name: "mapa com chaves inteiras", data: "a201020304", want: map[interface{}]interface{}{int64(1): int64(2), int64(3): int64(4)}},
+		{name: "mapa com chaves de texto", data: "a26161016162820203", want: map[interface{}]interface{}{"a": int64(1), "b": []interface{}{int64(2), int64(3)}}},
+		{name: "falso", data: "f4", want: false},
+		{name: "verdadeiro", data: "f5", want: true},
+		{name: "nulo", data: "f6", want: nil},
+		{name: "etiqueta ignorada", data: "c11a514b67b0", want: int64(1363896240)},
+		{name: "ponto flutuante pulado", data: "f93c00", want: nil},
+		{name: "bytes restantes", data: "0a0102", want: int64(10), wantRest: "0102"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.data)
+
+			got, rest, err := decodeCBOR(data)
+
+			if err != nil {
+				t.Fatalf("decodeCBOR(%s) error = %v", tt.data, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeCBOR(%s) = %#v, want %#v", tt.data, got, tt.want)
+			}
+			if hex.EncodeToString(rest) != tt.wantRest {
+				t.Errorf("rest = %x, want %s", rest, tt.wantRest)
+			}
+		})
+	}
+}
+
+func TestDecodeCBORInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "vazio", data: ""},
+		{name: "argumento incompleto", data: "19"},
+		{name: "bytes incompletos", data: "45010203"},
+		{name: "lista incompleta", data: "830102"},
+		{name: "tamanho indefinido", data: "5f"},
+		{name: "chave de mapa booleana", data: "a1f401"},
+		{name: "inteiro fora do int64", data: "1bffffffffffffffff"},
+		{name: "negativo fora do int64", data: "3bffffffffffffffff"},
+		{name: "break isolado", data: "ff"},
+		{name: "aninhamento excessivo", data: strings.Repeat("81", maxCBORDepth+2) + "00"},
+		{name: "lista com tamanho maior que os dados", data: "9affffffff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.data)
+			if _, _, err := decodeCBOR(data); !errors.Is(err, errCBOR) {
+				t.Errorf("decodeCBOR(%s) error = %v, want %v", tt.data, err, errCBOR)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// Algoritmos COSE (RFC 9053) aceitos para as credenciais.
+const (
+	AlgES256 = -7
+	AlgEdDSA = -8
+	AlgRS256 = -257
+)
+
+// SupportedAlgorithms são os algoritmos oferecidos ao navegador no cadastro,
+// em ordem de preferência.
+var SupportedAlgorithms = []int{AlgES256, AlgEdDSA, AlgRS256}
+
+// Parâmetros das chaves COSE.
+const (
+	coseKeyType   = 1
+	coseAlgorithm = 3
+	coseCurve     = -1 // EC2 e OKP
+	coseX         = -2 // EC2 e OKP
+	coseY         = -3 // EC2
+	coseModulus   = -1 // RSA
+	coseExponent  = -2 // RSA
+
+	coseKeyTypeOKP = 1
+	coseKeyTypeEC2 = 2
+	coseKeyTypeRSA = 3
+
+	coseCurveP256    = 1
+	coseCurveEd25519 = 6
+)
+
+var errPublicKey = errors.New("Chave pública da credencial inválida")
+
+// parsePublicKey decodifica uma chave pública COSE nos formatos aceitos.
+func parsePublicKey(data []byte) (algorithm int64, key crypto.PublicKey, err error) {
+	decoded, _, err := decodeCBOR(data)
+	if err != nil {
+		return 0, nil, errPublicKey
+	}
+	params, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return 0, nil, errPublicKey
+	}
+
+	keyType, _ := params[int64(coseKeyType)].(int64)
+	algorithm, _ = params[int64(coseAlgorithm)].(int64)
+
+	switch {
+	case keyType == coseKeyTypeEC2 && algorithm == AlgES256:
+		curve, _ := params[int64(coseCurve)].(int64)
+		x, _ := params[int64(coseX)].([]byte)
+		y, _ := params[int64(coseY)].([]byte)
+		if curve != coseCurveP256 || len(x) != 32 || len(y) != 32 {
+			return 0, nil, errPublicKey
+		}
+		public := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if !public.Curve.IsOnCurve(public.X, public.Y) {
+			return 0, nil, errPublicKey
+		}
+		return algorithm, public, nil
+
+	case keyType == coseKeyTypeOKP && algorithm == AlgEdDSA:
+		curve, _ := params[int64(coseCurve)].(int64)
+		x, _ := params[int64(coseX)].([]byte)
+		if curve != coseCurveEd25519 || len(x) != ed25519.PublicKeySize {
+			return 0, nil, errPublicKey
+		}
+		return algorithm, ed25519.PublicKey(x), nil
+
+	case keyType == coseKeyTypeRSA && algorithm == AlgRS256:
+		n, _ := params[int64(coseModulus)].([]byte)
+		e, _ := params[int64(coseExponent)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return 0, nil, errPublicKey
+		}
+		exponent := int(new(big.Int).SetBytes(e).Int64())
+		return algorithm, &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exponent}, nil
+	}
+	return 0, nil, errors.New("Algoritmo da credencial não suportado")
+}
+
+// verifySignature confere a assinatura de message com a chave pública COSE.
+func verifySignature(publicKey, message, signature []byte) error {
+	algorithm, key, err := parsePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	valid := false
+	switch algorithm {
+	case AlgES256:
+		digest := sha256.Sum256(message)
+		valid = ecdsa.VerifyASN1(key.(*ecdsa.PublicKey), digest[:], signature)
+	case AlgEdDSA:
+		valid = ed25519.Verify(key.(ed25519.PublicKey), message, signature)
+	case AlgRS256:
+		digest := sha256.Sum256(message)
+		valid = rsa.VerifyPKCS1v15(key.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+	}
+	if !valid {
+		return errors.New("Assinatura do autenticador inválida")
+	}
+	return nil
+}
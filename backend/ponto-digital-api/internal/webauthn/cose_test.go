@@ -0,0 +1,226 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// cborHead codifica o cabeçalho de um item CBOR com o argumento n.
+func cborHead(major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n < 1<<8:
+		return []byte{major<<5 | 24, byte(n)}
+	default:
+		return []byte{major<<5 | 25, byte(n >> 8), byte(n)}
+	}
+}
+
+func cborInt(v int64) []byte {
+	if v < 0 {
+		return cborHead(1, uint64(-1-v))
+	}
+	return cborHead(0, uint64(v))
+}
+
+// coseKey codifica um mapa CBOR com chaves inteiras e valores inteiros ou
+// byte strings, na ordem em que os pares são informados.
+func coseKey(pairs ...interface{}) []byte {
+	out := cborHead(5, uint64(len(pairs)/2))
+	for i := 0; i < len(pairs); i += 2 {
+		out = append(out, cborInt(int64(pairs[i].(int)))...)
+		switch v := pairs[i+1].(type) {
+		case int:
+			out = append(out, cborInt(int64(v))...)
+		case []byte:
+			out = append(out, cborHead(2, uint64(len(v)))...)
+			out = append(out, v...)
+		}
+	}
+	return out
+}
+
+func ec2Key(t *testing.T) (*ecdsa.PrivateKey, []byte) {
+	t.Helper()
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := private.X.FillBytes(make([]byte, 32))
+	y := private.Y.FillBytes(make([]byte, 32))
+	return private, coseKey(coseKeyType, coseKeyTypeEC2, coseAlgorithm, AlgES256, coseCurve, coseCurveP256, coseX, x, coseY, y)
+}
+
+func TestParsePublicKey(t *testing.T) {
+	message := []byte("dados do autenticador e hash do client data")
+	digest := sha256.Sum256(message)
+
+	ecPrivate, ecKey := ec2Key(t)
+	ecSignature, err := ecdsa.SignASN1(rand.Reader, ecPrivate, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edKey := coseKey(coseKeyType, coseKeyTypeOKP, coseAlgorithm, AlgEdDSA, coseCurve, coseCurveEd25519, coseX, []byte(edPublic))
+	edSignature := ed25519.Sign(edPrivate, message)
+
+	rsaPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exponent := big.NewInt(int64(rsaPrivate.E)).Bytes()
+	rsaKey := coseKey(coseKeyType, coseKeyTypeRSA, coseAlgorithm, AlgRS256, coseModulus, rsaPrivate.N.Bytes(), coseExponent, exponent)
+	rsaSignature, err := rsa.SignPKCS1v15(rand.Reader, rsaPrivate, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		key       []byte
+		signature []byte
+		algorithm int64
+	}{
+		{name: "ES256", key: ecKey, signature: ecSignature, algorithm: AlgES256},
+		{name: "EdDSA", key: edKey, signature: edSignature, algorithm: AlgEdDSA},
+		{name: "RS256", key: rsaKey, signature: rsaSignature, algorithm: AlgRS256},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			algorithm, _, err := parsePublicKey(tt.key)
+			if err != nil {
+				t.Fatalf("parsePublicKey() error = %v", err)
+			}
+			if algorithm != tt.algorithm {
+				t.Errorf("algorithm = %d, want %d", algorithm, tt.algorithm)
+			}
+			if err := verifySignature(tt.key, message, tt.signature); err != nil {
+				t.Errorf("verifySignature() error = %v", err)
+			}
+			if err := verifySignature(tt.key, []byte("mensagem alterada"), tt.signature); err == nil {
+				t.Errorf("verifySignature() accepted a signature over another message")
+			}
+		})
+	}
+}
+
+func TestParsePublicKeyInvalid(t *testing.T) {
+	_, valid := ec2Key(t)
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	y[31] = 1
+	edPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shortRSA, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		key  []byte
+		want error
+	}{
+		{name: "CBOR inválido", key: valid[:len(valid)-1], want: errPublicKey},
+		{name: "não é um mapa", key: []byte{0x80}, want: errPublicKey},
+		{name: "ponto fora da curva", key: coseKey(coseKeyType, coseKeyTypeEC2, coseAlgorithm, AlgES256, coseCurve, coseCurveP256, coseX, x, coseY, y), want: errPublicKey},
+		{name: "curva diferente da P-256", key: coseKey(coseKeyType, coseKeyTypeEC2, coseAlgorithm, AlgES256, coseCurve, 2, coseX, x, coseY, y), want: errPublicKey},
+		{name: "coordenada curta", key: coseKey(coseKeyType, coseKeyTypeEC2, coseAlgorithm, AlgES256, coseCurve, coseCurveP256, coseX, x[:31], coseY, y), want: errPublicKey},
+		{name: "curva OKP diferente da Ed25519", key: coseKey(coseKeyType, coseKeyTypeOKP, coseAlgorithm, AlgEdDSA, coseCurve, 4, coseX, []byte(edPublic)), want: errPublicKey},
+		{name: "módulo RSA menor que 2048 bits", key: coseKey(coseKeyType, coseKeyTypeRSA, coseAlgorithm, AlgRS256, coseModulus, shortRSA.N.Bytes(), coseExponent, []byte{1, 0, 1}), want: errPublicKey},
+		{name: "algoritmo não suportado", key: coseKey(coseKeyType, coseKeyTypeEC2, coseAlgorithm, -35, coseCurve, 2, coseX, x, coseY, y)},
+		{name: "algoritmo incompatível com o tipo", key: coseKey(coseKeyType, coseKeyTypeOKP, coseAlgorithm, AlgES256, coseCurve, coseCurveEd25519, coseX, []byte(edPublic))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := parsePublicKey(tt.key)
+			if err == nil {
+				t.Fatalf("parsePublicKey() accepted an invalid key")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("parsePublicKey() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAuthenticatorData(t *testing.T) {
+	_, key := ec2Key(t)
+	rpIDHash := sha256.Sum256([]byte("ponto.example.com"))
+	credentialID := []byte("credencial-1")
+
+	header := func(flags byte, signCount uint32) []byte {
+		data := append([]byte{}, rpIDHash[:]...)
+		data = append(data, flags)
+		return binary.BigEndian.AppendUint32(data, signCount)
+	}
+	attested := func(extensions []byte) []byte {
+		data := header(flagUserPresent|flagUserVerified|flagAttested, 0)
+		data = append(data, make([]byte, 16)...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(credentialID)))
+		data = append(data, credentialID...)
+		data = append(data, key...)
+		return append(data, extensions...)
+	}
+
+	t.Run("asserção sem credencial", func(t *testing.T) {
+		parsed, err := parseAuthenticatorData(header(flagUserPresent, 42))
+		if err != nil {
+			t.Fatalf("parseAuthenticatorData() error = %v", err)
+		}
+		if parsed.SignCount != 42 || parsed.Flags != flagUserPresent || parsed.CredentialID != nil {
+			t.Errorf("parsed = %+v", parsed)
+		}
+	})
+
+	for name, extensions := range map[string][]byte{
+		"cadastro":               nil,
+		"cadastro com extensões": {0xa1, 0x61, 0x61, 0xf5},
+	} {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := parseAuthenticatorData(attested(extensions))
+			if err != nil {
+				t.Fatalf("parseAuthenticatorData() error = %v", err)
+			}
+			if !bytes.Equal(parsed.CredentialID, credentialID) {
+				t.Errorf("CredentialID = %q, want %q", parsed.CredentialID, credentialID)
+			}
+			if !bytes.Equal(parsed.PublicKey, key) {
+				t.Errorf("PublicKey = %x, want %x", parsed.PublicKey, key)
+			}
+		})
+	}
+
+	invalid := map[string][]byte{
+		"curto":                    header(flagUserPresent, 0)[:36],
+		"credencial sem AAGUID":    header(flagAttested, 0),
+		"identificador incompleto": attested(nil)[:37+18+4],
+		"chave pública incompleta": attested(nil)[:len(attested(nil))-1],
+	}
+	for name, data := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseAuthenticatorData(data); err == nil {
+				t.Errorf("parseAuthenticatorData() accepted %x", data)
+			}
+		})
+	}
+}
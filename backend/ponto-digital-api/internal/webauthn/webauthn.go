@@ -0,0 +1,330 @@
+// Package webauthn implementa a parte do servidor (relying party) do
+// WebAuthn Level 2 usada na marcação biométrica: as opções entregues ao
+// navegador, a verificação do cadastro de credenciais e a verificação das
+// asserções assinadas pelo autenticador. A atestação do autenticador não é
+// exigida; a credencial é vinculada ao usuário já autenticado na API.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ChallengeTimeout é a validade de um desafio emitido ao navegador.
+const ChallengeTimeout = 5 * time.Minute
+
+// Tipos do clientDataJSON de cada cerimônia.
+const (
+	ceremonyCreate = "webauthn.create"
+	ceremonyGet    = "webauthn.get"
+)
+
+// ErrCounter indica que o contador de assinaturas não avançou, sinal de que
+// a credencial pode ter sido clonada.
+var ErrCounter = errors.New("Contador do autenticador inválido: a credencial pode ter sido clonada")
+
+// RelyingParty identifica a aplicação perante os autenticadores. ID é o
+// domínio (RP ID) ao qual as credenciais ficam vinculadas e Origins as
+// origens de onde as cerimônias podem partir, como "https://ponto.exemplo.com".
+type RelyingParty struct {
+	ID      string
+	Name    string
+	Origins []string
+}
+
+// URLEncodedBytes são bytes representados em JSON como base64url sem
+// preenchimento, a codificação usada pelo WebAuthn.
+type URLEncodedBytes []byte
+
+func (b URLEncodedBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+func (b *URLEncodedBytes) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	// Aceita também o base64url com preenchimento
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return errors.New("Valor base64url inválido")
+	}
+	*b = decoded
+	return nil
+}
+
+// NewChallenge gera um desafio aleatório de 32 bytes.
+func NewChallenge() ([]byte, error) {
+	challenge := make([]byte, 32)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// CredentialDescriptor identifica uma credencial nas opções.
+type CredentialDescriptor struct {
+	Type string          `json:"type"`
+	ID   URLEncodedBytes `json:"id"`
+}
+
+// CredentialParameter é um algoritmo aceito para a credencial.
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+// CreationOptions são as opções de navigator.credentials.create().
+type CreationOptions struct {
+	Challenge URLEncodedBytes `json:"challenge"`
+	RP        struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"rp"`
+	User struct {
+		ID          URLEncodedBytes `json:"id"`
+		Name        string          `json:"name"`
+		DisplayName string          `json:"displayName"`
+	} `json:"user"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int                    `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection struct {
+		AuthenticatorAttachment string `json:"authenticatorAttachment"`
+		ResidentKey             string `json:"residentKey"`
+		UserVerification        string `json:"userVerification"`
+	} `json:"authenticatorSelection"`
+	Attestation string `json:"attestation"`
+}
+
+// RequestOptions são as opções de navigator.credentials.get().
+type RequestOptions struct {
+	Challenge        URLEncodedBytes        `json:"challenge"`
+	Timeout          int                    `json:"timeout"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// CreationOptions monta as opções de cadastro de uma credencial de
+// plataforma (leitor biométrico do próprio aparelho) com verificação do
+// usuário. exclude lista as credenciais já cadastradas do usuário, que o
+// autenticador não deve duplicar.
+func (rp *RelyingParty) CreationOptions(challenge, userID []byte, name, displayName string, exclude [][]byte) CreationOptions {
+	var options CreationOptions
+	options.Challenge = challenge
+	options.RP.ID = rp.ID
+	options.RP.Name = rp.Name
+	options.User.ID = userID
+	options.User.Name = name
+	options.User.DisplayName = displayName
+	for _, algorithm := range SupportedAlgorithms {
+		options.PubKeyCredParams = append(options.PubKeyCredParams, CredentialParameter{Type: "public-key", Alg: algorithm})
+	}
+	options.Timeout = int(ChallengeTimeout / time.Millisecond)
+	options.ExcludeCredentials = descriptors(exclude)
+	options.AuthenticatorSelection.AuthenticatorAttachment = "platform"
+	options.AuthenticatorSelection.ResidentKey = "discouraged"
+	options.AuthenticatorSelection.UserVerification = "required"
+	options.Attestation = "none"
+	return options
+}
+
+// RequestOptions monta as opções de uma asserção com uma das credenciais
+// allow, exigindo a verificação do usuário.
+func (rp *RelyingParty) RequestOptions(challenge []byte, allow [][]byte) RequestOptions {
+	return RequestOptions{
+		Challenge:        challenge,
+		Timeout:          int(ChallengeTimeout / time.Millisecond),
+		RPID:             rp.ID,
+		AllowCredentials: descriptors(allow),
+		UserVerification: "required",
+	}
+}
+
+func descriptors(ids [][]byte) []CredentialDescriptor {
+	list := []CredentialDescriptor{}
+	for _, id := range ids {
+		list = append(list, CredentialDescriptor{Type: "public-key", ID: id})
+	}
+	return list
+}
+
+// RegistrationResponse é o PublicKeyCredential devolvido por
+// navigator.credentials.create(), com os campos binários em base64url.
+type RegistrationResponse struct {
+	ID       string          `json:"id"`
+	RawID    URLEncodedBytes `json:"rawId"`
+	Type     string          `json:"type"`
+	Response struct {
+		ClientDataJSON    URLEncodedBytes `json:"clientDataJSON"`
+		AttestationObject URLEncodedBytes `json:"attestationObject"`
+	} `json:"response"`
+}
+
+// AssertionResponse é o PublicKeyCredential devolvido por
+// navigator.credentials.get(), com os campos binários em base64url.
+type AssertionResponse struct {
+	ID       string          `json:"id"`
+	RawID    URLEncodedBytes `json:"rawId"`
+	Type     string          `json:"type"`
+	Response struct {
+		ClientDataJSON    URLEncodedBytes `json:"clientDataJSON"`
+		AuthenticatorData URLEncodedBytes `json:"authenticatorData"`
+		Signature         URLEncodedBytes `json:"signature"`
+		UserHandle        URLEncodedBytes `json:"userHandle"`
+	} `json:"response"`
+}
+
+// Credential é a credencial validada no cadastro. PublicKey é a chave
+// pública no formato COSE, guardada para verificar as asserções.
+type Credential struct {
+	ID        []byte
+	PublicKey []byte
+	SignCount uint32
+	AAGUID    []byte
+}
+
+type clientData struct {
+	Type      string          `json:"type"`
+	Challenge URLEncodedBytes `json:"challenge"`
+	Origin    string          `json:"origin"`
+}
+
+// Challenge extrai o desafio do clientDataJSON, para localizar o desafio
+// emitido antes da verificação completa.
+func Challenge(clientDataJSON []byte) ([]byte, error) {
+	var data clientData
+	if err := json.Unmarshal(clientDataJSON, &data); err != nil || len(data.Challenge) == 0 {
+		return nil, errors.New("Dados do cliente inválidos")
+	}
+	return data.Challenge, nil
+}
+
+// VerifyRegistration valida a resposta de cadastro contra o desafio emitido
+// e retorna a credencial criada.
+func (rp *RelyingParty) VerifyRegistration(response *RegistrationResponse, challenge []byte) (*Credential, error) {
+	if response.Type != "public-key" {
+		return nil, errors.New("Tipo de credencial inválido")
+	}
+	if err := rp.verifyClientData(response.Response.ClientDataJSON, ceremonyCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	decoded, _, err := decodeCBOR(response.Response.AttestationObject)
+	if err != nil {
+		return nil, errors.New("Objeto de atestação inválido")
+	}
+	attestation, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("Objeto de atestação inválido")
+	}
+	rawAuthData, ok := attestation["authData"].([]byte)
+	if !ok {
+		return nil, errors.New("Objeto de atestação sem dados do autenticador")
+	}
+
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(authData); err != nil {
+		return nil, err
+	}
+	if authData.Flags&flagAttested == 0 {
+		return nil, errors.New("Resposta de cadastro sem credencial")
+	}
+	if len(authData.CredentialID) == 0 || len(authData.CredentialID) > 1023 {
+		return nil, errors.New("Identificador da credencial inválido")
+	}
+	if !bytes.Equal(authData.CredentialID, response.RawID) {
+		return nil, errors.New("Identificador da credencial não confere")
+	}
+	if _, _, err := parsePublicKey(authData.PublicKey); err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:        append([]byte(nil), authData.CredentialID...),
+		PublicKey: append([]byte(nil), authData.PublicKey...),
+		SignCount: authData.SignCount,
+		AAGUID:    append([]byte(nil), authData.AAGUID...),
+	}, nil
+}
+
+// VerifyAssertion valida a asserção contra o desafio emitido e a chave
+// pública e o contador guardados da credencial, e retorna o novo contador.
+// Autenticadores sem contador informam sempre zero e são aceitos; nos demais
+// o contador precisa avançar, ou ErrCounter é retornado.
+func (rp *RelyingParty) VerifyAssertion(response *AssertionResponse, challenge, publicKey []byte, signCount uint32) (uint32, error) {
+	if response.Type != "public-key" {
+		return 0, errors.New("Tipo de credencial inválido")
+	}
+	if err := rp.verifyClientData(response.Response.ClientDataJSON, ceremonyGet, challenge); err != nil {
+		return 0, err
+	}
+
+	authData, err := parseAuthenticatorData(response.Response.AuthenticatorData)
+	if err != nil {
+		return 0, err
+	}
+	if err := rp.verifyAuthenticatorData(authData); err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(response.Response.ClientDataJSON)
+	message := append(append([]byte(nil), response.Response.AuthenticatorData...), clientDataHash[:]...)
+	if err := verifySignature(publicKey, message, response.Response.Signature); err != nil {
+		return 0, err
+	}
+
+	if (authData.SignCount != 0 || signCount != 0) && authData.SignCount <= signCount {
+		return 0, ErrCounter
+	}
+	return authData.SignCount, nil
+}
+
+// verifyClientData confere o tipo da cerimônia, o desafio e a origem do
+// clientDataJSON.
+func (rp *RelyingParty) verifyClientData(raw []byte, ceremony string, challenge []byte) error {
+	var data clientData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return errors.New("Dados do cliente inválidos")
+	}
+	if data.Type != ceremony {
+		return errors.New("Tipo de cerimônia WebAuthn inválido")
+	}
+	if len(challenge) == 0 || subtle.ConstantTimeCompare(data.Challenge, challenge) != 1 {
+		return errors.New("Desafio WebAuthn inválido")
+	}
+	for _, origin := range rp.Origins {
+		if data.Origin == origin {
+			return nil
+		}
+	}
+	return errors.New("Origem WebAuthn não autorizada")
+}
+
+// verifyAuthenticatorData confere se os dados do autenticador são do RP ID
+// da aplicação e se o usuário esteve presente e foi verificado.
+func (rp *RelyingParty) verifyAuthenticatorData(authData *authenticatorData) error {
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if subtle.ConstantTimeCompare(authData.RPIDHash, rpIDHash[:]) != 1 {
+		return errors.New("RP ID do autenticador não confere")
+	}
+	if authData.Flags&flagUserPresent == 0 {
+		return errors.New("Presença do usuário não confirmada pelo autenticador")
+	}
+	if authData.Flags&flagUserVerified == 0 {
+		return errors.New("Usuário não verificado pelo autenticador")
+	}
+	return nil
+}
@@ -79,10 +79,7 @@ const PointRegister = ({ onRegister = () => {} }) => {
       
       console.log("Tipo determinado para registro:", type);
       
-      // Solicitar verificação biométrica
-      await pointService.authenticateBiometric();
-      
-      // Registrar ponto
+      // Registrar ponto; a verificação biométrica é feita pelo serviço
       const response = await pointService.registerPoint({
         type,
         device: navigator.userAgent,
@@ -25,9 +25,9 @@ export const useAuth = () => {
     }
   };
 
-  const register = async (name, email, password) => {
+  const register = async (name, email, password, company) => {
     try {
-      const data = await authService.register(name, email, password);
+      const data = await authService.register(name, email, password, company);
       setUser(data);
       navigate('/dashboard');
       return true;
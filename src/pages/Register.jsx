@@ -12,7 +12,9 @@ const Register = () => {
     name: '',
     email: '',
     password: '',
-    confirmPassword: ''
+    confirmPassword: '',
+    companyName: '',
+    cnpj: ''
   });
   const [error, setError] = useState('');
   const [loading, setLoading] = useState(false);
@@ -37,7 +39,10 @@ const Register = () => {
         throw new Error('As senhas não coincidem');
       }
 
-      await authService.register(formData.name, formData.email, formData.password);
+      await authService.register(formData.name, formData.email, formData.password, {
+        name: formData.companyName,
+        cnpj: formData.cnpj
+      });
       navigate('/dashboard');
     } catch (err) {
       setError(err.response?.data?.error || err.message || 'Erro ao registrar usuário');
@@ -101,6 +106,36 @@ const Register = () => {
                 />
               </div>
 
+              <div className="space-y-2">
+                <label htmlFor="companyName" className="text-sm font-medium">
+                  Razão Social da Empresa
+                </label>
+                <Input
+                  id="companyName"
+                  name="companyName"
+                  type="text"
+                  placeholder="Digite a razão social"
+                  value={formData.companyName}
+                  onChange={handleInputChange}
+                  required
+                />
+              </div>
+
+              <div className="space-y-2">
+                <label htmlFor="cnpj" className="text-sm font-medium">
+                  CNPJ
+                </label>
+                <Input
+                  id="cnpj"
+                  name="cnpj"
+                  type="text"
+                  placeholder="00.000.000/0000-00"
+                  value={formData.cnpj}
+                  onChange={handleInputChange}
+                  required
+                />
+              </div>
+
               <div className="space-y-2">
                 <label htmlFor="password" className="text-sm font-medium">
                   Senha
@@ -10,8 +10,13 @@ const axiosInstance = axios.create({
 });
 
 const TOKEN_KEY = 'auth_token';
+const REFRESH_TOKEN_KEY = 'refresh_token';
 const USER_KEY = 'user_data';
 
+// Renovação em andamento, compartilhada pelas requisições que recebem 401 ao
+// mesmo tempo: cada refresh token vale uma única vez
+let refreshing = null;
+
 export const authService = {
   async login(email, password) {
     try {
@@ -22,6 +27,7 @@ export const authService = {
 
       if (response.data.token) {
         localStorage.setItem(TOKEN_KEY, response.data.token);
+        localStorage.setItem(REFRESH_TOKEN_KEY, response.data.refreshToken);
         localStorage.setItem(USER_KEY, JSON.stringify(response.data.user));
       }
       return response.data;
@@ -31,16 +37,18 @@ export const authService = {
     }
   },
 
-  async register(name, email, password) {
+  async register(name, email, password, company) {
     try {
       const response = await axiosInstance.post('/register', {
         name,
         email,
-        password
+        password,
+        company
       });
 
       if (response.data.token) {
         localStorage.setItem(TOKEN_KEY, response.data.token);
+        localStorage.setItem(REFRESH_TOKEN_KEY, response.data.refreshToken);
         localStorage.setItem(USER_KEY, JSON.stringify(response.data.user));
       }
       return response.data;
@@ -50,11 +58,75 @@ export const authService = {
     }
   },
 
+  // Encerra a sessão no servidor e remove os tokens locais
   logout() {
+    const token = this.getToken();
+    if (token) {
+      axiosInstance
+        .post('/logout', null, { headers: { Authorization: `Bearer ${token}` } })
+        .catch(error => console.error('Erro ao encerrar sessão:', error));
+    }
+    this.clearSession();
+  },
+
+  // Encerra as sessões do usuário em todos os aparelhos
+  async logoutAll() {
+    try {
+      await axiosInstance.post('/logout-all', null, {
+        headers: { Authorization: `Bearer ${this.getToken()}` }
+      });
+    } finally {
+      this.clearSession();
+    }
+  },
+
+  clearSession() {
     localStorage.removeItem(TOKEN_KEY);
+    localStorage.removeItem(REFRESH_TOKEN_KEY);
     localStorage.removeItem(USER_KEY);
   },
 
+  // Troca o refresh token por um novo token de acesso; retorna o novo token
+  // ou lança erro quando a sessão foi encerrada
+  refreshToken() {
+    if (!refreshing) {
+      const refreshToken = localStorage.getItem(REFRESH_TOKEN_KEY);
+      refreshing = (refreshToken
+        ? axios.post(`${API_URL}/refresh`, { refreshToken })
+        : Promise.reject(new Error('Sessão expirada'))
+      )
+        .then(response => {
+          localStorage.setItem(TOKEN_KEY, response.data.token);
+          localStorage.setItem(REFRESH_TOKEN_KEY, response.data.refreshToken);
+          return response.data.token;
+        })
+        .finally(() => {
+          refreshing = null;
+        });
+    }
+    return refreshing;
+  },
+
+  // Repete uma vez a requisição que recebeu 401 com um token renovado; se a
+  // sessão não puder ser renovada, volta para o login
+  async retryWithRefresh(instance, error) {
+    const request = error.config;
+    if (error.response?.status !== 401 || !request || request._retried || !this.getToken()) {
+      return Promise.reject(error);
+    }
+
+    try {
+      const token = await this.refreshToken();
+      request._retried = true;
+      request.headers.Authorization = `Bearer ${token}`;
+      return instance(request);
+    } catch {
+      this.clearSession();
+      window.location.href = '/login';
+      return Promise.reject(error);
+    }
+  },
+
   getCurrentUser() {
     const userStr = localStorage.getItem(USER_KEY);
     return userStr ? JSON.parse(userStr) : null;
@@ -85,14 +157,15 @@ export const authService = {
   },
 };
 
-// Interceptor para tratar erros de autenticação
+// Interceptor para tratar erros de autenticação: tokens de acesso expiram em
+// poucos minutos e são renovados com o refresh token
 axiosInstance.interceptors.response.use(
   response => response,
   error => {
-    if (error.response?.status === 401) {
-      authService.logout();
-      window.location.href = '/login';
+    const path = error.config?.url;
+    if (['/login', '/register', '/logout', '/logout-all'].includes(path)) {
+      return Promise.reject(error);
     }
-    return Promise.reject(error);
+    return authService.retryWithRefresh(axiosInstance, error);
   }
 );
\ No newline at end of file
@@ -23,6 +23,24 @@ axiosInstance.interceptors.request.use(
   }
 );
 
+// Renova o token de acesso expirado e repete a requisição
+axiosInstance.interceptors.response.use(
+  response => response,
+  error => authService.retryWithRefresh(axiosInstance, error)
+);
+
+// O WebAuthn trabalha com ArrayBuffers; a API os representa em base64url
+const toBase64Url = (buffer) =>
+  btoa(String.fromCharCode(...new Uint8Array(buffer)))
+    .replace(/\+/g, '-')
+    .replace(/\//g, '_')
+    .replace(/=+$/, '');
+
+const fromBase64Url = (value) => {
+  const base64 = value.replace(/-/g, '+').replace(/_/g, '/');
+  return Uint8Array.from(atob(base64.padEnd(Math.ceil(base64.length / 4) * 4, '=')), c => c.charCodeAt(0));
+};
+
 export const pointService = {
   async checkBiometrySupport() {
     try {
@@ -44,39 +62,84 @@ export const pointService = {
     }
   },
 
-  async authenticateBiometric() {
+  // Cadastra o leitor biométrico deste aparelho como credencial WebAuthn do
+  // usuário, com o desafio emitido pelo servidor
+  async registerBiometric(name = 'Biometria') {
     try {
-      const challenge = new Uint8Array(32);
-      window.crypto.getRandomValues(challenge);
-
-      const publicKeyCredentialCreationOptions = {
-        challenge,
-        rp: {
-          name: "Sistema de Ponto Digital",
-          id: window.location.hostname,
-        },
-        user: {
-          id: Uint8Array.from("UZSL85T9AFC", c => c.charCodeAt(0)),
-          name: "webauthn@example.com",
-          displayName: "webauthn",
-        },
-        pubKeyCredParams: [{alg: -7, type: "public-key"}],
-        authenticatorSelection: {
-          authenticatorAttachment: "platform",
-          userVerification: "preferred",
-        },
-        timeout: 60000,
-      };
+      const { data: options } = await axiosInstance.post('/webauthn/register/options');
 
       const credential = await navigator.credentials.create({
-        publicKey: publicKeyCredentialCreationOptions
+        publicKey: {
+          ...options,
+          challenge: fromBase64Url(options.challenge),
+          user: { ...options.user, id: fromBase64Url(options.user.id) },
+          excludeCredentials: options.excludeCredentials.map(item => ({
+            ...item,
+            id: fromBase64Url(item.id)
+          }))
+        }
+      });
+
+      const response = await axiosInstance.post('/webauthn/register', {
+        name,
+        credential: {
+          id: credential.id,
+          rawId: toBase64Url(credential.rawId),
+          type: credential.type,
+          response: {
+            clientDataJSON: toBase64Url(credential.response.clientDataJSON),
+            attestationObject: toBase64Url(credential.response.attestationObject)
+          }
+        }
+      });
+      return response.data;
+    } catch (error) {
+      console.error('Erro no cadastro da biometria:', error);
+      throw new Error(error.response?.data?.error || 'Falha no cadastro da biometria');
+    }
+  },
+
+  // Assina o desafio emitido pelo servidor com a credencial biométrica do
+  // usuário, cadastrando-a antes se ainda não houver nenhuma, e retorna a
+  // asserção enviada no registro do ponto
+  async authenticateBiometric() {
+    try {
+      let options;
+      try {
+        ({ data: options } = await axiosInstance.post('/webauthn/authenticate/options'));
+      } catch (error) {
+        if (error.response?.status !== 409) {
+          throw error;
+        }
+        await this.registerBiometric();
+        ({ data: options } = await axiosInstance.post('/webauthn/authenticate/options'));
+      }
+
+      const credential = await navigator.credentials.get({
+        publicKey: {
+          ...options,
+          challenge: fromBase64Url(options.challenge),
+          allowCredentials: options.allowCredentials.map(item => ({
+            ...item,
+            id: fromBase64Url(item.id)
+          }))
+        }
       });
 
-      const biometricToken = btoa(String.fromCharCode(...new Uint8Array(credential.rawId)));
-      return biometricToken;
+      return {
+        id: credential.id,
+        rawId: toBase64Url(credential.rawId),
+        type: credential.type,
+        response: {
+          clientDataJSON: toBase64Url(credential.response.clientDataJSON),
+          authenticatorData: toBase64Url(credential.response.authenticatorData),
+          signature: toBase64Url(credential.response.signature),
+          userHandle: credential.response.userHandle ? toBase64Url(credential.response.userHandle) : null
+        }
+      };
     } catch (error) {
       console.error('Erro na autenticação:', error);
-      throw new Error('Falha na autenticação biométrica');
+      throw new Error(error.response?.data?.error || 'Falha na autenticação biométrica');
     }
   },
 
@@ -98,11 +161,11 @@ export const pointService = {
           payloadJSON: JSON.stringify(payload)
         });
       } else if (authMethod === 'biometric') {
-        const biometricToken = await this.authenticateBiometric();
-        payload = { ...payload, biometricToken };
+        const assertion = await this.authenticateBiometric();
+        payload = { ...payload, assertion };
         console.log('Registrando ponto com Biometria:', {
           ...payload,
-          biometricToken: '****',
+          assertion: '****',
           payloadJSON: JSON.stringify(payload)
         });
       }